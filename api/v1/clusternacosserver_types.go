@@ -0,0 +1,58 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterNacosServerSpec defines a nacos server which can be referenced from DynamicConfigurations in allowed namespaces
+type ClusterNacosServerSpec struct {
+	NacosServerSpec `json:",inline"`
+	// AllowedNamespaces restricts which namespaces can reference this server, all namespaces are allowed if empty
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
+}
+
+// AllowedNamespaces allows a namespace if it is listed in Names or matched by Selector
+type AllowedNamespaces struct {
+	Names    []string              `json:"names,omitempty"`
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName=cncs
+
+// ClusterNacosServer is the Schema for the clusternacosservers API
+type ClusterNacosServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterNacosServerSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterNacosServerList contains a list of ClusterNacosServer
+type ClusterNacosServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNacosServer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterNacosServer{}, &ClusterNacosServerList{})
+}
@@ -29,11 +29,38 @@ type DynamicConfigurationSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	DataIds        []string                 `json:"dataIds,omitempty"`
+	DataIds []string `json:"dataIds,omitempty"`
+	// Configs are dataIds with group, key and sync strategy overrides, used together with DataIds.
+	// A dataId can appear only once in DataIds and Configs, even in different groups, since status is kept by dataId.
+	Configs []DataIdConfig `json:"configs,omitempty"`
+	// DataIdSelector selects dataIds in namespace and group of nacos server, in addition to DataIds.
+	// Only used by server2cluster sync direction.
+	DataIdSelector *DataIdSelector          `json:"dataIdSelector,omitempty"`
 	AdditionalConf *AdditionalConfiguration `json:"additionalConf,omitempty"`
 	Strategy       SyncStrategy             `json:"strategy,omitempty"`
 	NacosServer    NacosServerConfiguration `json:"nacosServer,omitempty"`
 	ObjectRef      *v1.ObjectReference      `json:"objectRef,omitempty"`
+	// NacosServerRef refers to a NacosServer or ClusterNacosServer which provides connection and auth of nacos server.
+	// When it is set, only group and namespace of spec.nacosServer are used.
+	NacosServerRef *NacosServerReference `json:"nacosServerRef,omitempty"`
+	// Template renders content before it is published to nacos server in cluster2server,
+	// or before it is stored to objectRef in server2cluster.
+	Template *ContentTemplate `json:"template,omitempty"`
+	// RolloutTargets are workloads in the same namespace which are restarted when content of objectRef is changed
+	// by server2cluster or bidirectional sync, by patching config hash annotation of pod template
+	RolloutTargets []RolloutTarget `json:"rolloutTargets,omitempty"`
+	// RevisionHistoryLimit is the number of DynamicConfigurationRevisions kept for each dataId, default 10.
+	// Zero disables revision history.
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	// Rollback restores a revision of dataId to the destination side, both sides for bidirectional.
+	// The dataId is not synced until rollback is removed.
+	Rollback *RollbackSpec `json:"rollback,omitempty"`
+	// Suspend pauses syncing without deleting the DynamicConfiguration, changes from nacos server are ignored.
+	// All dataIds are synced again after it is resumed.
+	Suspend bool `json:"suspend,omitempty"`
+	// Targets are additional nacos servers or namespaces which dataIds are published to, besides spec.nacosServer.
+	// Only used by cluster2server sync direction. Failure of a target doesn't block syncing of the others.
+	Targets []SyncTarget `json:"targets,omitempty"`
 }
 
 // DynamicConfigurationStatus defines the observed state of DynamicConfiguration
@@ -45,12 +72,34 @@ type DynamicConfigurationStatus struct {
 	ObservedGeneration int64               `json:"observedGeneration,omitempty"`
 	SyncStatuses       []SyncStatus        `json:"syncStatuses,omitempty"`
 	ObjectRef          *v1.ObjectReference `json:"objectRef,omitempty"`
+	// NacosNamespace is the namespace of nacos server at last sync, resolved from spec.nacosServer or spec.nacosServerRef
+	NacosNamespace string `json:"nacosNamespace,omitempty"`
+	// SelectedDataIds are dataIds selected by spec.dataIdSelector at last sync
+	SelectedDataIds []string `json:"selectedDataIds,omitempty"`
+	// LastResyncTime is the time of last periodic resync
+	LastResyncTime *metav1.Time `json:"lastResyncTime,omitempty"`
+	// LastRollback is the result of spec.rollback
+	LastRollback *RollbackStatus `json:"lastRollback,omitempty"`
+	// Plan is the changes computed by last dry run, only set if spec.strategy.dryRun is true
+	Plan *SyncPlan `json:"plan,omitempty"`
+	// GrayStatuses are states of beta publishing of dataIds
+	GrayStatuses []GrayStatus `json:"grayStatuses,omitempty"`
+	// TargetStatuses are sync states of spec.targets
+	TargetStatuses []TargetStatus `json:"targetStatuses,omitempty"`
+	// Rollouts are workloads triggered to roll out at last content change
+	Rollouts []RolloutStatus `json:"rollouts,omitempty"`
+	// Conditions of DynamicConfiguration, types are Ready, ServerReachable, Authenticated, Listening and Synced
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:shortName=dc
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // DynamicConfiguration is the Schema for the dynamicconfigurations API
@@ -62,6 +111,169 @@ type DynamicConfiguration struct {
 	Status DynamicConfigurationStatus `json:"status,omitempty"`
 }
 
+// DataIdConfig is a dataId with overrides of spec.nacosServer.group and spec.strategy
+type DataIdConfig struct {
+	DataId string `json:"dataId"`
+	// Group overrides spec.nacosServer.group. The same dataId in another group can't be synced by the same
+	// DynamicConfiguration, use another DynamicConfiguration instead.
+	Group string `json:"group,omitempty"`
+	// Key is the key in objectRef which stores content of dataId, default dataId
+	Key string `json:"key,omitempty"`
+	// SyncPolicy overrides spec.strategy.syncPolicy
+	SyncPolicy DynamicConfigurationSyncPolicy `json:"syncPolicy,omitempty"`
+	// SyncDeletion overrides spec.strategy.syncDeletion
+	SyncDeletion *bool `json:"syncDeletion,omitempty"`
+	// Type is the content format of dataId, which is validated before sync.
+	// Detected by extension of dataId if it is empty, e.g. .yaml, .json, .properties and .xml
+	Type ConfigFormat `json:"type,omitempty"`
+}
+
+// ConfigFormat is the content format of a dataId, same as config type of nacos server
+type ConfigFormat string
+
+const (
+	ConfigFormatText       ConfigFormat = "text"
+	ConfigFormatYaml       ConfigFormat = "yaml"
+	ConfigFormatJson       ConfigFormat = "json"
+	ConfigFormatProperties ConfigFormat = "properties"
+	ConfigFormatXml        ConfigFormat = "xml"
+	ConfigFormatHtml       ConfigFormat = "html"
+)
+
+// RollbackSpec refers to a DynamicConfigurationRevision of dataId
+type RollbackSpec struct {
+	DataId   string `json:"dataId"`
+	Revision int64  `json:"revision"`
+}
+
+// RollbackStatus is the result of rolling back dataId to revision
+type RollbackStatus struct {
+	DataId       string      `json:"dataId"`
+	Revision     int64       `json:"revision"`
+	Md5          string      `json:"md5,omitempty"`
+	RollbackTime metav1.Time `json:"rollbackTime,omitempty"`
+	Ready        bool        `json:"ready,omitempty"`
+	Message      string      `json:"message,omitempty"`
+}
+
+// RolloutTarget is a workload or workloads selected by labels, only one of Name and Selector should be set
+type RolloutTarget struct {
+	// Kind is Deployment, StatefulSet or DaemonSet
+	Kind string `json:"kind"`
+	// Name of the workload
+	Name string `json:"name,omitempty"`
+	// Selector selects workloads of Kind by labels
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+const (
+	DeploymentKind  = "Deployment"
+	StatefulSetKind = "StatefulSet"
+	DaemonSetKind   = "DaemonSet"
+)
+
+// RolloutStatus is the result of triggering rollout of a workload
+type RolloutStatus struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// ConfigHash is the hash of content patched to pod template
+	ConfigHash string `json:"configHash,omitempty"`
+	// LastRolloutTime is the time rollout was triggered, or the time the current failure happened first
+	LastRolloutTime metav1.Time `json:"lastRolloutTime,omitempty"`
+	Ready           bool        `json:"ready,omitempty"`
+	Message         string      `json:"message,omitempty"`
+	// Failures is the number of consecutive failures, failed rollout is retried with exponential backoff
+	Failures int32 `json:"failures,omitempty"`
+}
+
+// SyncPlan is the changes which would be made by syncing
+type SyncPlan struct {
+	PlanTime metav1.Time `json:"planTime,omitempty"`
+	// Summary counts changes by action
+	Summary string          `json:"summary,omitempty"`
+	Changes []PlannedChange `json:"changes,omitempty"`
+}
+
+// PlannedChange is the action which would be taken on a dataId
+type PlannedChange struct {
+	DataId string `json:"dataId"`
+	// Target is the name of spec.targets which the change is planned for, empty for spec.nacosServer
+	Target string     `json:"target,omitempty"`
+	Action PlanAction `json:"action"`
+	// Diff summarizes lines added and removed in the destination side
+	Diff    string `json:"diff,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type PlanAction string
+
+const (
+	PlanActionCreate PlanAction = "Create"
+	PlanActionUpdate PlanAction = "Update"
+	PlanActionDelete PlanAction = "Delete"
+	PlanActionSkip   PlanAction = "Skip"
+)
+
+// GrayStatus is the state of beta publishing of a dataId
+type GrayStatus struct {
+	DataId string    `json:"dataId"`
+	Phase  GrayPhase `json:"phase"`
+	// Md5 of the beta content
+	Md5         string       `json:"md5,omitempty"`
+	BetaIps     []string     `json:"betaIps,omitempty"`
+	BetaTime    metav1.Time  `json:"betaTime,omitempty"`
+	PromoteTime *metav1.Time `json:"promoteTime,omitempty"`
+	Message     string       `json:"message,omitempty"`
+}
+
+type GrayPhase string
+
+const (
+	// GrayPhaseBeta means content is published to beta IPs only
+	GrayPhaseBeta GrayPhase = "Beta"
+	// GrayPhasePromoted means content is promoted to all clients
+	GrayPhasePromoted GrayPhase = "Promoted"
+)
+
+// ContentTemplate renders content of dataIds with variables
+type ContentTemplate struct {
+	// Type of template, placeholder renders ${var}, and $${var} is rendered as ${var}. goTemplate renders Go template.
+	// Default placeholder.
+	Type ContentTemplateType `json:"type,omitempty"`
+	// DataIds to be rendered, all dataIds are rendered if it is empty
+	DataIds []string `json:"dataIds,omitempty"`
+	// ValuesFrom are ConfigMaps or Secrets in the same namespace, whose data are variables.
+	// Later source overrides earlier one.
+	ValuesFrom []TemplateValuesSource `json:"valuesFrom,omitempty"`
+	// Variables override variables from ValuesFrom
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+type ContentTemplateType string
+
+const (
+	PlaceholderTemplate ContentTemplateType = "placeholder"
+	GoTemplate          ContentTemplateType = "goTemplate"
+)
+
+// TemplateValuesSource refers to a ConfigMap or a Secret, only one of them should be set
+type TemplateValuesSource struct {
+	ConfigMapRef *v1.LocalObjectReference `json:"configMapRef,omitempty"`
+	SecretRef    *v1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// DataIdSelector selects dataIds by searching nacos server, all conditions must be matched
+type DataIdSelector struct {
+	// Pattern is a glob pattern of dataId, e.g. app-*.yaml. All dataIds are selected if it is empty
+	Pattern string `json:"pattern,omitempty"`
+	// Regex is a regular expression which dataId must match
+	Regex string `json:"regex,omitempty"`
+	// Tags of config in nacos server, config having any of the tags is selected
+	Tags []string `json:"tags,omitempty"`
+	// RefreshInterval is the interval to search nacos server for new dataIds, default 1m
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
 type AdditionalConfiguration struct {
 	Labels     map[string]string `json:"labels,omitempty"`
 	Properties map[string]string `json:"properties,omitempty"`
@@ -72,6 +284,32 @@ type SyncStrategy struct {
 	SyncPolicy    DynamicConfigurationSyncPolicy    `json:"syncPolicy,omitempty"`
 	SyncDeletion  bool                              `json:"syncDeletion,omitempty"`
 	SyncDirection DynamicConfigurationSyncDirection `json:"syncDirection,omitempty"`
+	// ConflictPolicy decides which side wins when both server and cluster changed since last sync.
+	// Only used by bidirectional sync direction.
+	ConflictPolicy DynamicConfigurationConflictPolicy `json:"conflictPolicy,omitempty"`
+	// ResyncInterval is the interval to sync all dataIds again, even if nothing changed in cluster.
+	// Controller default is used if it is empty, and resync is disabled if both are zero.
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+	// DriftPolicy decides what to do when content in nacos server differs from cluster on resync, default correct.
+	// Only used by cluster2server sync direction.
+	DriftPolicy DynamicConfigurationDriftPolicy `json:"driftPolicy,omitempty"`
+	// Gray publishes changed content as beta to selected nacos clients first, and promotes it to all clients later.
+	// Only used by cluster2server sync direction.
+	Gray *GrayStrategy `json:"gray,omitempty"`
+	// DryRun computes changes of dataIds and reports them in status.plan and events, without writing either side.
+	// Only used by cluster2server and server2cluster sync direction.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// GrayStrategy selects nacos clients receiving beta configs, and decides when beta configs are promoted
+type GrayStrategy struct {
+	// BetaIps are IPs of nacos clients receiving beta configs
+	BetaIps []string `json:"betaIps,omitempty"`
+	// PodSelector selects pods in the namespace of DynamicConfiguration, whose IPs receive beta configs
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// PromoteAfter promotes beta configs to all clients after the duration since published.
+	// If it is empty, beta configs are promoted only by annotation nacos.io/gray-promote=true.
+	PromoteAfter *metav1.Duration `json:"promoteAfter,omitempty"`
 }
 
 type DynamicConfigurationSyncPolicy string
@@ -86,23 +324,146 @@ type DynamicConfigurationSyncDirection string
 const (
 	Cluster2Server DynamicConfigurationSyncDirection = "cluster2server"
 	Server2Cluster DynamicConfigurationSyncDirection = "server2cluster"
+	Bidirectional  DynamicConfigurationSyncDirection = "bidirectional"
+)
+
+type DynamicConfigurationDriftPolicy string
+
+const (
+	// DriftCorrect publishes content in cluster to nacos server again
+	DriftCorrect DynamicConfigurationDriftPolicy = "correct"
+	// DriftReport only reports drift in status and events
+	DriftReport DynamicConfigurationDriftPolicy = "report"
+)
+
+type DynamicConfigurationConflictPolicy string
+
+const (
+	ServerWins  DynamicConfigurationConflictPolicy = "serverWins"
+	ClusterWins DynamicConfigurationConflictPolicy = "clusterWins"
+	// Manual stops syncing the conflicted dataId until both sides have the same content,
+	// or the conflict policy is changed to serverWins or clusterWins
+	Manual DynamicConfigurationConflictPolicy = "manual"
 )
 
 type NacosServerConfiguration struct {
-	Endpoint   *string             `json:"endpoint,omitempty"`
+	Endpoint *string `json:"endpoint,omitempty"`
+	// ServerAddr is a comma separated list of nacos server addresses, each is [scheme://]host[:port][/contextPath],
+	// e.g. 10.0.0.1:8848,10.0.0.2:8848. IPv6 should be in brackets, e.g. [fd00::1]:8848
 	ServerAddr *string             `json:"serverAddr,omitempty"`
 	Namespace  string              `json:"namespace,omitempty"`
 	Group      string              `json:"group,omitempty"`
 	AuthRef    *v1.ObjectReference `json:"authRef,omitempty"`
+	// AuthKeys specifies which keys of AuthRef hold the credentials, default keys are used if empty
+	AuthKeys *NacosAuthKeys `json:"authKeys,omitempty"`
+	// ClientOptions overrides client options of the referenced NacosServer and the flags of controller
+	ClientOptions *NacosClientOptions `json:"clientOptions,omitempty"`
+	// Scheme is the scheme of serverAddr, https is used by default if tls is set
+	// +kubebuilder:validation:Enum=http;https
+	Scheme string `json:"scheme,omitempty"`
+	// TLS configures certificates used to connect nacos server by https and grpc
+	TLS *NacosServerTLS `json:"tls,omitempty"`
 }
 
+// SyncTarget is an additional nacos server or namespace which dataIds are published to
+type SyncTarget struct {
+	// Name identifies the target in status.targetStatuses
+	Name string `json:"name"`
+	// NacosServer provides connection, namespace, group and auth of the target,
+	// group of spec.nacosServer is used if group is empty
+	NacosServer NacosServerConfiguration `json:"nacosServer,omitempty"`
+	// NacosServerRef refers to a NacosServer or ClusterNacosServer which provides connection and auth of the target.
+	// When it is set, only group and namespace of nacosServer are used.
+	NacosServerRef *NacosServerReference `json:"nacosServerRef,omitempty"`
+}
+
+// TargetStatus is the sync state of a target of spec.targets
+type TargetStatus struct {
+	Name string `json:"name"`
+	// NacosNamespace is the namespace of nacos server of the target at last sync
+	NacosNamespace string `json:"nacosNamespace,omitempty"`
+	// Ready is true when all dataIds are synced to the target
+	Ready   bool   `json:"ready,omitempty"`
+	Message string `json:"message,omitempty"`
+	// SyncStatuses are sync states of dataIds in the target
+	SyncStatuses []SyncStatus `json:"syncStatuses,omitempty"`
+	// GrayStatuses are states of beta publishing of dataIds in the target
+	GrayStatuses []GrayStatus `json:"grayStatuses,omitempty"`
+}
+
+// NacosAuthKeys maps credentials of nacos server to keys in the auth Secret
+type NacosAuthKeys struct {
+	// AccessKey is the key of access key, default is ak
+	AccessKey string `json:"accessKey,omitempty"`
+	// SecretKey is the key of secret key, default is sk
+	SecretKey string `json:"secretKey,omitempty"`
+	// Username is the key of username, default is username
+	Username string `json:"username,omitempty"`
+	// Password is the key of password, default is password
+	Password string `json:"password,omitempty"`
+}
+
+// NacosServerReference refers to a NacosServer in the same namespace, or a ClusterNacosServer
+type NacosServerReference struct {
+	// Kind is NacosServer or ClusterNacosServer, default is NacosServer
+	Kind string `json:"kind,omitempty"`
+	Name string `json:"name"`
+}
+
+const (
+	NacosServerKind        = "NacosServer"
+	ClusterNacosServerKind = "ClusterNacosServer"
+)
+
+// Condition types of DynamicConfiguration
+const (
+	// ConditionReady is true when all other conditions are true
+	ConditionReady = "Ready"
+	// ConditionServerReachable is true when nacos server responded at last sync
+	ConditionServerReachable = "ServerReachable"
+	// ConditionAuthenticated is true when nacos server accepted the credentials at last sync
+	ConditionAuthenticated = "Authenticated"
+	// ConditionListening is true when all dataIds are listened, only for server2cluster and bidirectional
+	ConditionListening = "Listening"
+	// ConditionSynced is true when all dataIds are synced
+	ConditionSynced = "Synced"
+	// ConditionSuspended is true when spec.suspend is true, it is removed after resumed
+	ConditionSuspended = "Suspended"
+)
+
+// Reasons of SyncStatus
+const (
+	SyncReasonSynced            = "Synced"
+	SyncReasonSkipped           = "Skipped"
+	SyncReasonDeleted           = "Deleted"
+	SyncReasonConflict          = "Conflict"
+	SyncReasonDrifted           = "Drifted"
+	SyncReasonRenderFailed      = "RenderFailed"
+	SyncReasonInvalidContent    = "InvalidContent"
+	SyncReasonRolledBack        = "RolledBack"
+	SyncReasonRollbackFailed    = "RollbackFailed"
+	SyncReasonReadFailed        = "ReadFailed"
+	SyncReasonPublishFailed     = "PublishFailed"
+	SyncReasonStoreFailed       = "StoreFailed"
+	SyncReasonDeleteFailed      = "DeleteFailed"
+	SyncReasonListenFailed      = "ListenFailed"
+	SyncReasonAuthFailed        = "AuthFailed"
+	SyncReasonServerUnreachable = "ServerUnreachable"
+)
+
 type SyncStatus struct {
 	DataId       string      `json:"dataId,omitempty"`
 	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
 	LastSyncFrom string      `json:"lastSyncFrom,omitempty"`
 	Md5          string      `json:"md5,omitempty"`
 	Ready        bool        `json:"ready,omitempty"`
-	Message      string      `json:"message,omitempty"`
+	// Reason is a brief CamelCase code of the result of last sync, e.g. Synced, PublishFailed, AuthFailed
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// ServerMd5 and ClusterMd5 are md5 of each side at last sync, only used by bidirectional sync direction
+	ServerMd5  string `json:"serverMd5,omitempty"`
+	ClusterMd5 string `json:"clusterMd5,omitempty"`
+	Conflict   bool   `json:"conflict,omitempty"`
 }
 
 //+kubebuilder:object:root=true
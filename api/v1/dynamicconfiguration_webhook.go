@@ -18,13 +18,20 @@ package v1
 
 import (
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"net"
+	"path"
+	"regexp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"strings"
+	"time"
 )
 
 // log is for logging in this package.
@@ -56,6 +63,12 @@ func (r *DynamicConfiguration) Default() {
 	if r.Spec.Strategy.SyncDirection == "" {
 		r.Spec.Strategy.SyncDirection = Cluster2Server
 	}
+	if r.Spec.Strategy.SyncDirection == Bidirectional && r.Spec.Strategy.ConflictPolicy == "" {
+		r.Spec.Strategy.ConflictPolicy = Manual
+	}
+	if r.Spec.Strategy.SyncDirection == Cluster2Server && r.Spec.Strategy.DriftPolicy == "" {
+		r.Spec.Strategy.DriftPolicy = DriftCorrect
+	}
 }
 
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
@@ -92,12 +105,27 @@ func (r *DynamicConfiguration) validateDC() error {
 	if err := r.validateNacosServerConfiguration(); err != nil {
 		allErrs = append(allErrs, err)
 	}
+	if err := r.validateDataIds(); err != nil {
+		allErrs = append(allErrs, err)
+	}
 	if err := r.validateObjectRef(); err != nil {
 		allErrs = append(allErrs, err)
 	}
 	if err := r.validateSyncStrategy(); err != nil {
 		allErrs = append(allErrs, err)
 	}
+	if err := r.validateTemplate(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := r.validateRolloutTargets(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := r.validateRevisionHistory(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := r.validateTargets(); err != nil {
+		allErrs = append(allErrs, err)
+	}
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -108,13 +136,19 @@ func (r *DynamicConfiguration) validateDC() error {
 }
 
 func (r *DynamicConfiguration) validateNacosServerConfiguration() *field.Error {
+	if len(r.Spec.NacosServer.Group) == 0 {
+		return field.Required(field.NewPath("spec").Child("group"), "nacos group should be set")
+	}
+	if r.Spec.NacosServerRef != nil {
+		return r.validateNacosServerRef()
+	}
 	serverAddrEmpty := r.Spec.NacosServer.ServerAddr == nil || len(*r.Spec.NacosServer.ServerAddr) == 0
 	endpoint := r.Spec.NacosServer.Endpoint == nil || len(*r.Spec.NacosServer.Endpoint) == 0
 	if serverAddrEmpty && endpoint {
 		return field.Required(field.NewPath("spec").Child("nacosServer"), "either ServerAddr or Endpoint should be set")
 	}
-	if len(r.Spec.NacosServer.Group) == 0 {
-		return field.Required(field.NewPath("spec").Child("group"), "nacos group should be set")
+	if err := validateServerAddr(field.NewPath("spec").Child("nacosServer").Child("serverAddr"), r.Spec.NacosServer.ServerAddr); err != nil {
+		return err
 	}
 	if r.Spec.NacosServer.AuthRef == nil {
 		return field.Required(field.NewPath("spec").Child("group"), "nacos auth reference should be set")
@@ -128,20 +162,259 @@ func (r *DynamicConfiguration) validateNacosServerConfiguration() *field.Error {
 				supportGVKs)
 		}
 	}
-	if r.Spec.DataIds == nil || len(r.Spec.DataIds) == 0 {
-		return field.Required(field.NewPath("spec").Child("dataIds"), "at least one dataId should be set")
+	return validateNacosServerTLS(field.NewPath("spec").Child("nacosServer").Child("tls"), r.Spec.NacosServer.TLS)
+}
+
+// validateServerAddr checks every address of serverAddr, the error tells which address is invalid
+func validateServerAddr(p *field.Path, serverAddr *string) *field.Error {
+	if serverAddr == nil || len(*serverAddr) == 0 {
+		return nil
+	}
+	if _, err := ParseServerAddrs(*serverAddr); err != nil {
+		return field.Invalid(p, *serverAddr, err.Error())
 	}
 	return nil
 }
 
-func (r *DynamicConfiguration) validateObjectRef() *field.Error {
-	if r.Spec.Strategy.SyncDirection != Cluster2Server {
+// validateNacosServerTLS checks tls of nacos server, the CA bundle of secret is required unless insecureSkipVerify is set
+func validateNacosServerTLS(p *field.Path, tls *NacosServerTLS) *field.Error {
+	if tls == nil {
+		return nil
+	}
+	if tls.SecretRef == nil {
+		if !tls.InsecureSkipVerify {
+			return field.Required(p.Child("secretRef"), "secret of CA bundle should be set unless insecureSkipVerify is set")
+		}
+		return nil
+	}
+	if len(tls.SecretRef.Name) == 0 {
+		return field.Required(p.Child("secretRef").Child("name"), "name of tls secret should be set")
+	}
+	return nil
+}
+
+func (r *DynamicConfiguration) validateDataIds() *field.Error {
+	if err := r.validateConfigs(); err != nil {
+		return err
+	}
+	selector := r.Spec.DataIdSelector
+	if selector == nil {
+		if len(r.Spec.DataIds) == 0 && len(r.Spec.Configs) == 0 {
+			return field.Required(field.NewPath("spec").Child("dataIds"), "at least one dataId, config or dataIdSelector should be set")
+		}
+		return nil
+	}
+	selectorPath := field.NewPath("spec").Child("dataIdSelector")
+	if r.Spec.Strategy.SyncDirection != Server2Cluster {
+		return field.Forbidden(selectorPath, "dataIdSelector is only supported by server2cluster sync direction")
+	}
+	if _, err := path.Match(selector.Pattern, ""); err != nil {
+		return field.Invalid(selectorPath.Child("pattern"), selector.Pattern, err.Error())
+	}
+	if _, err := regexp.Compile(selector.Regex); err != nil {
+		return field.Invalid(selectorPath.Child("regex"), selector.Regex, err.Error())
+	}
+	if selector.RefreshInterval != nil && selector.RefreshInterval.Duration < time.Second {
+		return field.Invalid(selectorPath.Child("refreshInterval"), selector.RefreshInterval.Duration.String(), "refreshInterval must not be less than 1s")
+	}
+	return nil
+}
+
+func (r *DynamicConfiguration) validateTemplate() *field.Error {
+	tpl := r.Spec.Template
+	if tpl == nil {
+		return nil
+	}
+	p := field.NewPath("spec").Child("template")
+	if r.Spec.Strategy.SyncDirection == Bidirectional {
+		return field.Forbidden(p, "template is not supported by bidirectional sync direction")
+	}
+	typeSupportList := []string{string(PlaceholderTemplate), string(GoTemplate)}
+	if len(tpl.Type) > 0 && !stringsContains(typeSupportList, string(tpl.Type)) {
+		return field.NotSupported(p.Child("type"), tpl.Type, typeSupportList)
+	}
+	for i, source := range tpl.ValuesFrom {
+		sourcePath := p.Child("valuesFrom").Index(i)
+		if (source.ConfigMapRef == nil) == (source.SecretRef == nil) {
+			return field.Invalid(sourcePath, source, "only one of configMapRef and secretRef should be set")
+		}
+		if source.ConfigMapRef != nil && len(source.ConfigMapRef.Name) == 0 {
+			return field.Required(sourcePath.Child("configMapRef").Child("name"), "name of ConfigMap should be set")
+		}
+		if source.SecretRef != nil && len(source.SecretRef.Name) == 0 {
+			return field.Required(sourcePath.Child("secretRef").Child("name"), "name of Secret should be set")
+		}
+	}
+	return nil
+}
+
+func (r *DynamicConfiguration) validateRolloutTargets() *field.Error {
+	if len(r.Spec.RolloutTargets) == 0 {
+		return nil
+	}
+	p := field.NewPath("spec").Child("rolloutTargets")
+	if r.Spec.Strategy.SyncDirection == Cluster2Server {
+		return field.Forbidden(p, "rolloutTargets is not supported by cluster2server sync direction")
+	}
+	kindSupportList := []string{DeploymentKind, StatefulSetKind, DaemonSetKind}
+	for i, target := range r.Spec.RolloutTargets {
+		targetPath := p.Index(i)
+		if !stringsContains(kindSupportList, target.Kind) {
+			return field.NotSupported(targetPath.Child("kind"), target.Kind, kindSupportList)
+		}
+		if (len(target.Name) == 0) == (target.Selector == nil) {
+			return field.Invalid(targetPath, target, "only one of name and selector should be set")
+		}
+		if target.Selector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(target.Selector); err != nil {
+				return field.Invalid(targetPath.Child("selector"), target.Selector, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (r *DynamicConfiguration) validateRevisionHistory() *field.Error {
+	if r.Spec.RevisionHistoryLimit != nil && *r.Spec.RevisionHistoryLimit < 0 {
+		return field.Invalid(field.NewPath("spec").Child("revisionHistoryLimit"), *r.Spec.RevisionHistoryLimit, "revisionHistoryLimit must not be negative")
+	}
+	rollback := r.Spec.Rollback
+	if rollback == nil {
 		return nil
 	}
+	p := field.NewPath("spec").Child("rollback")
+	if len(rollback.DataId) == 0 {
+		return field.Required(p.Child("dataId"), "dataId should be set")
+	}
+	if rollback.Revision <= 0 {
+		return field.Invalid(p.Child("revision"), rollback.Revision, "revision must be positive")
+	}
+	// dataIds selected by dataIdSelector are unknown until syncing
+	if r.Spec.DataIdSelector != nil {
+		return nil
+	}
+	if stringsContains(r.Spec.DataIds, rollback.DataId) {
+		return nil
+	}
+	for _, c := range r.Spec.Configs {
+		if c.DataId == rollback.DataId {
+			return nil
+		}
+	}
+	return field.Invalid(p.Child("dataId"), rollback.DataId, "dataId should be one of dataIds or configs")
+}
+
+func (r *DynamicConfiguration) validateTargets() *field.Error {
+	if len(r.Spec.Targets) == 0 {
+		return nil
+	}
+	p := field.NewPath("spec").Child("targets")
+	if r.Spec.Strategy.SyncDirection != Cluster2Server {
+		return field.Forbidden(p, "targets is only supported by cluster2server sync direction")
+	}
+	names := map[string]bool{}
+	for i, target := range r.Spec.Targets {
+		targetPath := p.Index(i)
+		if len(target.Name) == 0 {
+			return field.Required(targetPath.Child("name"), "name of target should be set")
+		}
+		if names[target.Name] {
+			return field.Duplicate(targetPath.Child("name"), target.Name)
+		}
+		names[target.Name] = true
+		if ref := target.NacosServerRef; ref != nil {
+			if len(ref.Name) == 0 {
+				return field.Required(targetPath.Child("nacosServerRef").Child("name"), "name of nacos server should be set")
+			}
+			supportKinds := []string{NacosServerKind, ClusterNacosServerKind}
+			if len(ref.Kind) > 0 && !stringsContains(supportKinds, ref.Kind) {
+				return field.NotSupported(targetPath.Child("nacosServerRef").Child("kind"), ref.Kind, supportKinds)
+			}
+			continue
+		}
+		server := target.NacosServer
+		serverAddrEmpty := server.ServerAddr == nil || len(*server.ServerAddr) == 0
+		endpointEmpty := server.Endpoint == nil || len(*server.Endpoint) == 0
+		if serverAddrEmpty && endpointEmpty {
+			return field.Required(targetPath.Child("nacosServer"), "either ServerAddr or Endpoint should be set")
+		}
+		if err := validateServerAddr(targetPath.Child("nacosServer").Child("serverAddr"), server.ServerAddr); err != nil {
+			return err
+		}
+		if server.AuthRef == nil {
+			return field.Required(targetPath.Child("nacosServer").Child("authRef"), "nacos auth reference should be set")
+		}
+		supportGVKs := []string{SecretGVK.String()}
+		if gvk := server.AuthRef.GroupVersionKind().String(); !stringsContains(supportGVKs, gvk) {
+			return field.NotSupported(targetPath.Child("nacosServer").Child("authRef"), server.AuthRef, supportGVKs)
+		}
+		if err := validateNacosServerTLS(targetPath.Child("nacosServer").Child("tls"), server.TLS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateConfigs checks spec.configs, dataIds and keys should be unique in spec.configs and spec.dataIds
+func (r *DynamicConfiguration) validateConfigs() *field.Error {
+	dataIds := map[string]bool{}
+	keys := map[string]bool{}
+	for _, dataId := range r.Spec.DataIds {
+		dataIds[dataId] = true
+		keys[dataId] = true
+	}
+	syncPolicySupportList := []string{string(Always), string(IfAbsent)}
+	typeSupportList := []string{string(ConfigFormatText), string(ConfigFormatYaml), string(ConfigFormatJson),
+		string(ConfigFormatProperties), string(ConfigFormatXml), string(ConfigFormatHtml)}
+	for i, c := range r.Spec.Configs {
+		p := field.NewPath("spec").Child("configs").Index(i)
+		if len(c.DataId) == 0 {
+			return field.Required(p.Child("dataId"), "dataId should be set")
+		}
+		if dataIds[c.DataId] {
+			return field.Duplicate(p.Child("dataId"), c.DataId)
+		}
+		dataIds[c.DataId] = true
+		key := c.Key
+		if len(key) == 0 {
+			key = c.DataId
+		} else if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return field.Invalid(p.Child("key"), key, strings.Join(errs, ","))
+		}
+		if keys[key] {
+			return field.Duplicate(p.Child("key"), key)
+		}
+		keys[key] = true
+		if len(c.SyncPolicy) > 0 && !stringsContains(syncPolicySupportList, string(c.SyncPolicy)) {
+			return field.NotSupported(p.Child("syncPolicy"), c.SyncPolicy, syncPolicySupportList)
+		}
+		if len(c.Type) > 0 && !stringsContains(typeSupportList, string(c.Type)) {
+			return field.NotSupported(p.Child("type"), c.Type, typeSupportList)
+		}
+	}
+	return nil
+}
+
+func (r *DynamicConfiguration) validateNacosServerRef() *field.Error {
+	ref := r.Spec.NacosServerRef
+	if len(ref.Name) == 0 {
+		return field.Required(field.NewPath("spec").Child("nacosServerRef").Child("name"), "name of nacos server should be set")
+	}
+	supportKinds := []string{NacosServerKind, ClusterNacosServerKind}
+	if len(ref.Kind) > 0 && !stringsContains(supportKinds, ref.Kind) {
+		return field.NotSupported(field.NewPath("spec").Child("nacosServerRef").Child("kind"), ref.Kind, supportKinds)
+	}
+	return nil
+}
+
+func (r *DynamicConfiguration) validateObjectRef() *field.Error {
 	if r.Spec.ObjectRef == nil {
+		if r.Spec.Strategy.SyncDirection != Cluster2Server {
+			return nil
+		}
 		return field.Required(field.NewPath("spec").Child("objectRef"), "ObjectRef should be set when SyncDirection is cluster2server")
 	} else {
-		supportGVKs := []string{ConfigMapGVK.String()}
+		supportGVKs := []string{ConfigMapGVK.String(), SecretGVK.String()}
 		gvk := r.Spec.ObjectRef.GroupVersionKind().String()
 		if !stringsContains(supportGVKs, gvk) {
 			return field.NotSupported(
@@ -154,7 +427,7 @@ func (r *DynamicConfiguration) validateObjectRef() *field.Error {
 }
 
 func (r *DynamicConfiguration) validateSyncStrategy() *field.Error {
-	syncDirectionSupportList := []string{string(Cluster2Server), string(Server2Cluster)}
+	syncDirectionSupportList := []string{string(Cluster2Server), string(Server2Cluster), string(Bidirectional)}
 	if !stringsContains(syncDirectionSupportList, string(r.Spec.Strategy.SyncDirection)) {
 		return field.NotSupported(
 			field.NewPath("spec").Child("strategy").Child("syncDirection"),
@@ -168,6 +441,59 @@ func (r *DynamicConfiguration) validateSyncStrategy() *field.Error {
 			r.Spec.Strategy.SyncPolicy,
 			syncPolicySupportList)
 	}
+	if r.Spec.Strategy.SyncDirection == Bidirectional {
+		conflictPolicySupportList := []string{string(ServerWins), string(ClusterWins), string(Manual)}
+		if !stringsContains(conflictPolicySupportList, string(r.Spec.Strategy.ConflictPolicy)) {
+			return field.NotSupported(
+				field.NewPath("spec").Child("strategy").Child("conflictPolicy"),
+				r.Spec.Strategy.ConflictPolicy,
+				conflictPolicySupportList)
+		}
+	}
+	if r.Spec.Strategy.ResyncInterval != nil && r.Spec.Strategy.ResyncInterval.Duration < 0 {
+		return field.Invalid(
+			field.NewPath("spec").Child("strategy").Child("resyncInterval"),
+			r.Spec.Strategy.ResyncInterval.Duration.String(),
+			"resyncInterval must not be negative")
+	}
+	driftPolicySupportList := []string{"", string(DriftCorrect), string(DriftReport)}
+	if !stringsContains(driftPolicySupportList, string(r.Spec.Strategy.DriftPolicy)) {
+		return field.NotSupported(
+			field.NewPath("spec").Child("strategy").Child("driftPolicy"),
+			r.Spec.Strategy.DriftPolicy,
+			driftPolicySupportList[1:])
+	}
+	if r.Spec.Strategy.DryRun && r.Spec.Strategy.SyncDirection == Bidirectional {
+		return field.Forbidden(field.NewPath("spec").Child("strategy").Child("dryRun"), "dryRun is not supported by bidirectional sync direction")
+	}
+	return r.validateGray()
+}
+
+func (r *DynamicConfiguration) validateGray() *field.Error {
+	gray := r.Spec.Strategy.Gray
+	if gray == nil {
+		return nil
+	}
+	p := field.NewPath("spec").Child("strategy").Child("gray")
+	if r.Spec.Strategy.SyncDirection != Cluster2Server {
+		return field.Forbidden(p, "gray is only supported by cluster2server sync direction")
+	}
+	if len(gray.BetaIps) == 0 && gray.PodSelector == nil {
+		return field.Required(p, "at least one of betaIps and podSelector should be set")
+	}
+	for i, ip := range gray.BetaIps {
+		if net.ParseIP(ip) == nil {
+			return field.Invalid(p.Child("betaIps").Index(i), ip, "invalid IP address")
+		}
+	}
+	if gray.PodSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(gray.PodSelector); err != nil {
+			return field.Invalid(p.Child("podSelector"), gray.PodSelector, err.Error())
+		}
+	}
+	if gray.PromoteAfter != nil && gray.PromoteAfter.Duration < 0 {
+		return field.Invalid(p.Child("promoteAfter"), gray.PromoteAfter.Duration.String(), "promoteAfter must not be negative")
+	}
 	return nil
 }
 
@@ -0,0 +1,62 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=dcr
+//+kubebuilder:printcolumn:name="DataId",type=string,JSONPath=`.dataId`
+//+kubebuilder:printcolumn:name="Revision",type=integer,JSONPath=`.revision`
+//+kubebuilder:printcolumn:name="Md5",type=string,JSONPath=`.md5`
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// DynamicConfigurationRevision is a content of dataId synced by a DynamicConfiguration. It is immutable except
+// revision and syncTime, which are updated when the same content is synced again.
+type DynamicConfigurationRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	DataId string `json:"dataId"`
+	Group  string `json:"group,omitempty"`
+	// Revision increases for each dataId of a DynamicConfiguration, the latest synced content has the largest revision
+	Revision int64  `json:"revision"`
+	Md5      string `json:"md5"`
+	Content  string `json:"content,omitempty"`
+	// ContentSecretRef is the Secret owned by the revision which stores content, instead of Content. It is used if
+	// objectRef is a Secret or content is rendered with values of Secrets.
+	ContentSecretRef *v1.LocalObjectReference `json:"contentSecretRef,omitempty"`
+	// SyncFrom is the source side of content, server or cluster
+	SyncFrom string      `json:"syncFrom,omitempty"`
+	SyncTime metav1.Time `json:"syncTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DynamicConfigurationRevisionList contains a list of DynamicConfigurationRevision
+type DynamicConfigurationRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DynamicConfigurationRevision `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DynamicConfigurationRevision{}, &DynamicConfigurationRevisionList{})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NacosNamespaceSpec defines a namespace of nacos server managed by console API
+type NacosNamespaceSpec struct {
+	// NacosServerRef refers to a NacosServer or ClusterNacosServer which the namespace is created in.
+	// Only serverAddr and username/password of the auth Secret are used.
+	NacosServerRef NacosServerReference `json:"nacosServerRef"`
+	// NamespaceId is the id of namespace in nacos server, name of NacosNamespace is used if empty
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="namespaceId is immutable"
+	NamespaceId string `json:"namespaceId,omitempty"`
+	// DisplayName is the name shown in nacos console, name of NacosNamespace is used if empty
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+	// ForceDelete deletes the namespace in nacos server even if it still holds configs.
+	// Otherwise, deletion of NacosNamespace is blocked until all configs of the namespace are removed.
+	ForceDelete bool `json:"forceDelete,omitempty"`
+}
+
+// NacosNamespaceStatus defines the observed state of NacosNamespace
+type NacosNamespaceStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// NamespaceId is the id of namespace created in nacos server
+	NamespaceId string `json:"namespaceId,omitempty"`
+	// Created is true if the namespace was created by this NacosNamespace, only created namespaces are deleted
+	// with it. Adopted namespaces are left in nacos server.
+	Created bool `json:"created,omitempty"`
+	// ConfigCount is the number of configs in the namespace at last sync
+	ConfigCount int `json:"configCount,omitempty"`
+	// Conditions of NacosNamespace, type is Ready
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=nns
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="NamespaceId",type=string,JSONPath=`.status.namespaceId`
+//+kubebuilder:printcolumn:name="Configs",type=integer,JSONPath=`.status.configCount`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// NacosNamespace is the Schema for the nacosnamespaces API
+type NacosNamespace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NacosNamespaceSpec   `json:"spec,omitempty"`
+	Status NacosNamespaceStatus `json:"status,omitempty"`
+}
+
+// GetNamespaceId returns spec.namespaceId, or name of NacosNamespace if it is empty
+func (n *NacosNamespace) GetNamespaceId() string {
+	if len(n.Spec.NamespaceId) > 0 {
+		return n.Spec.NamespaceId
+	}
+	return n.Name
+}
+
+// GetDisplayName returns spec.displayName, or name of NacosNamespace if it is empty
+func (n *NacosNamespace) GetDisplayName() string {
+	if len(n.Spec.DisplayName) > 0 {
+		return n.Spec.DisplayName
+	}
+	return n.Name
+}
+
+//+kubebuilder:object:root=true
+
+// NacosNamespaceList contains a list of NacosNamespace
+type NacosNamespaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NacosNamespace `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NacosNamespace{}, &NacosNamespaceList{})
+}
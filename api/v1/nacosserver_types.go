@@ -0,0 +1,107 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NacosServerSpec defines the connection, auth and client options of a nacos server
+type NacosServerSpec struct {
+	Endpoint *string `json:"endpoint,omitempty"`
+	// ServerAddr is a comma separated list of nacos server addresses, each is [scheme://]host[:port][/contextPath],
+	// e.g. 10.0.0.1:8848,10.0.0.2:8848. IPv6 should be in brackets, e.g. [fd00::1]:8848
+	ServerAddr *string `json:"serverAddr,omitempty"`
+	// Namespace is the default nacos namespace id, it can be overridden by spec.nacosServer.namespace of DynamicConfiguration
+	Namespace string `json:"namespace,omitempty"`
+	// AuthRef is the reference of Secret which contains credentials. For NacosServer, the Secret should be in the
+	// same namespace. For ClusterNacosServer, namespace of the Secret is required.
+	AuthRef       *v1.ObjectReference `json:"authRef,omitempty"`
+	AuthKeys      *NacosAuthKeys      `json:"authKeys,omitempty"`
+	ClientOptions *NacosClientOptions `json:"clientOptions,omitempty"`
+	// Scheme is the scheme of serverAddr, https is used by default if tls is set
+	// +kubebuilder:validation:Enum=http;https
+	Scheme string `json:"scheme,omitempty"`
+	// TLS configures certificates used to connect nacos server by https and grpc
+	TLS *NacosServerTLS `json:"tls,omitempty"`
+}
+
+// NacosServerTLS defines the CA bundle and client certificate used to connect nacos server
+type NacosServerTLS struct {
+	// SecretRef refers to a Secret which holds the CA bundle in ca.crt, and optionally the client certificate and key
+	// in tls.crt and tls.key. For NacosServer and DynamicConfiguration, the Secret should be in the same namespace.
+	// For ClusterNacosServer, namespace of the Secret is required.
+	SecretRef *v1.SecretReference `json:"secretRef,omitempty"`
+	// ServerName is used to verify the certificate of nacos server, host of serverAddr is used if empty
+	ServerName string `json:"serverName,omitempty"`
+	// InsecureSkipVerify doesn't verify the certificate of nacos server, it is required if ca.crt is not provided
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// NacosClientOptions defines the tuning of nacos config client. Fields not set are read from the flags of controller.
+type NacosClientOptions struct {
+	// TimeoutMs is the timeout of requests to nacos server in milliseconds
+	TimeoutMs *int64 `json:"timeoutMs,omitempty"`
+	// LogLevel is the log level of nacos client, one of debug, info, warn and error
+	// +kubebuilder:validation:Enum=debug;info;warn;error
+	LogLevel string `json:"logLevel,omitempty"`
+	// LogDir is the directory of nacos client logs
+	LogDir string `json:"logDir,omitempty"`
+	// CacheDir is the directory where nacos client caches configs
+	CacheDir string `json:"cacheDir,omitempty"`
+	// LoadCacheAtStart loads configs cached in CacheDir when client is created
+	LoadCacheAtStart *bool `json:"loadCacheAtStart,omitempty"`
+	// DisableSnapshot disables reading configs cached in CacheDir when requests to nacos server failed
+	DisableSnapshot *bool `json:"disableSnapshot,omitempty"`
+	// BeatIntervalMs is the interval of heartbeats to nacos server in milliseconds
+	// +kubebuilder:validation:Minimum=1
+	BeatIntervalMs *int64 `json:"beatIntervalMs,omitempty"`
+	// ListenIntervalMs is the interval of long polling for listened configs in milliseconds, only used by nacos 1.x
+	// +kubebuilder:validation:Minimum=1
+	ListenIntervalMs *int64 `json:"listenIntervalMs,omitempty"`
+	// UpdateThreadNum is the number of goroutines which update cached configs
+	// +kubebuilder:validation:Minimum=1
+	UpdateThreadNum *int32 `json:"updateThreadNum,omitempty"`
+	// ContextPath is the context path of nacos server, /nacos by default
+	// +kubebuilder:validation:Pattern=`^/`
+	ContextPath string `json:"contextPath,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=ncs
+
+// NacosServer is the Schema for the nacosservers API
+type NacosServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NacosServerSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NacosServerList contains a list of NacosServer
+type NacosServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NacosServer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NacosServer{}, &NacosServerList{})
+}
@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultServerPort is the port of nacos server if it is not set in serverAddr
+const DefaultServerPort = 8848
+
+// ServerAddress is an address of nacos server in serverAddr
+// +kubebuilder:object:generate=false
+type ServerAddress struct {
+	// Scheme is http or https, empty if not set
+	Scheme string
+	// Host is a hostname or an IP, IPv6 is without brackets
+	Host string
+	Port uint64
+	// ContextPath is empty if not set
+	ContextPath string
+}
+
+// HostPort returns host and port of address, IPv6 is in brackets
+func (a ServerAddress) HostPort() string {
+	return net.JoinHostPort(a.Host, strconv.FormatUint(a.Port, 10))
+}
+
+// ServerAddressError is the error of an invalid address in serverAddr
+// +kubebuilder:object:generate=false
+type ServerAddressError struct {
+	// Index is the index of address in serverAddr, starting from 0
+	Index   int
+	Address string
+	Reason  string
+}
+
+func (e *ServerAddressError) Error() string {
+	return fmt.Sprintf("invalid address %q at index %d: %s", e.Address, e.Index, e.Reason)
+}
+
+// ParseServerAddrs parses comma separated addresses of serverAddr, each of them is [scheme://]host[:port][/contextPath],
+// e.g. 10.0.0.1:8848,https://[fd00::1]:8848/nacos. IPv6 should be in brackets, and port is 8848 if not set.
+func ParseServerAddrs(serverAddr string) ([]ServerAddress, error) {
+	var addrs []ServerAddress
+	for i, s := range strings.Split(serverAddr, ",") {
+		s = strings.TrimSpace(s)
+		addr, reason := parseServerAddr(s)
+		if len(reason) > 0 {
+			return nil, &ServerAddressError{Index: i, Address: s, Reason: reason}
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// parseServerAddr returns the reason if s is invalid
+func parseServerAddr(s string) (ServerAddress, string) {
+	addr := ServerAddress{Port: DefaultServerPort}
+	if len(s) == 0 {
+		return addr, "address is empty"
+	}
+	if i := strings.Index(s, "://"); i >= 0 {
+		addr.Scheme = s[:i]
+		s = s[i+3:]
+		if addr.Scheme != "http" && addr.Scheme != "https" {
+			return addr, fmt.Sprintf("unsupported scheme %q, should be http or https", addr.Scheme)
+		}
+	}
+	if i := strings.Index(s, "/"); i >= 0 {
+		addr.ContextPath = strings.TrimSuffix(s[i:], "/")
+		s = s[:i]
+		if strings.ContainsAny(addr.ContextPath, "?#") {
+			return addr, "context path should not contain query or fragment"
+		}
+	}
+	port := ""
+	if strings.HasPrefix(s, "[") {
+		end := strings.Index(s, "]")
+		if end < 0 {
+			return addr, "missing ] of IPv6"
+		}
+		addr.Host = s[1:end]
+		if ip := net.ParseIP(addr.Host); ip == nil || ip.To4() != nil {
+			return addr, fmt.Sprintf("invalid IPv6 %q", addr.Host)
+		}
+		rest := s[end+1:]
+		if len(rest) > 0 {
+			if !strings.HasPrefix(rest, ":") {
+				return addr, "port should follow ] of IPv6 after :"
+			}
+			port = rest[1:]
+		}
+	} else {
+		switch strings.Count(s, ":") {
+		case 0:
+			addr.Host = s
+		case 1:
+			addr.Host, port, _ = strings.Cut(s, ":")
+		default:
+			return addr, "IPv6 should be in brackets, e.g. [::1]:8848"
+		}
+		if len(addr.Host) == 0 {
+			return addr, "host is empty"
+		}
+		if strings.ContainsAny(addr.Host, " @?#[]") {
+			return addr, fmt.Sprintf("invalid host %q", addr.Host)
+		}
+	}
+	if len(port) > 0 || strings.HasSuffix(s, ":") {
+		v, err := strconv.ParseUint(port, 10, 16)
+		if err != nil || v == 0 {
+			return addr, fmt.Sprintf("invalid port %q, should be in 1-65535", port)
+		}
+		addr.Port = v
+	}
+	return addr, ""
+}
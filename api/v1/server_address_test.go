@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestParseServerAddrs(t *testing.T) {
+	cases := []struct {
+		serverAddr string
+		expected   []ServerAddress
+	}{
+		{"10.0.0.1", []ServerAddress{{Host: "10.0.0.1", Port: 8848}}},
+		{"nacos.local:9848", []ServerAddress{{Host: "nacos.local", Port: 9848}}},
+		{"[fd00::1]", []ServerAddress{{Host: "fd00::1", Port: 8848}}},
+		{"https://[fd00::1]:9848/config/", []ServerAddress{{Scheme: "https", Host: "fd00::1", Port: 9848, ContextPath: "/config"}}},
+		{"10.0.0.1:8848, http://10.0.0.2/nacos ,nacos.local", []ServerAddress{
+			{Host: "10.0.0.1", Port: 8848},
+			{Scheme: "http", Host: "10.0.0.2", Port: 8848, ContextPath: "/nacos"},
+			{Host: "nacos.local", Port: 8848},
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.serverAddr, func(t *testing.T) {
+			g := NewWithT(t)
+			addrs, err := ParseServerAddrs(c.serverAddr)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(addrs).To(Equal(c.expected))
+		})
+	}
+	g := NewWithT(t)
+	addrs, err := ParseServerAddrs("[fd00::1]:9848")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addrs[0].HostPort()).To(Equal("[fd00::1]:9848"))
+}
+
+func TestValidateServerAddr(t *testing.T) {
+	cases := []struct {
+		serverAddr string
+		reason     string
+	}{
+		{"10.0.0.1:8848,", "address is empty"},
+		{"10.0.0.1,fd00::1:8848", "IPv6 should be in brackets"},
+		{"10.0.0.1,[fd00::1:8848", "missing ]"},
+		{"10.0.0.1,[10.0.0.2]:8848", "invalid IPv6"},
+		{"10.0.0.1,ftp://10.0.0.2", "unsupported scheme"},
+		{"10.0.0.1,10.0.0.2:70000", "invalid port"},
+		{"10.0.0.1,10.0.0.2:", "invalid port"},
+		{"10.0.0.1,10.0.0.2/nacos?a=b", "context path"},
+		{"10.0.0.1,:8848", "host is empty"},
+		{"10.0.0.1,user@10.0.0.2:8848", "invalid host"},
+		{"10.0.0.1:8848,[fd00::1],https://nacos.local/nacos", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.serverAddr, func(t *testing.T) {
+			g := NewWithT(t)
+			dc := &DynamicConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "dc", Namespace: "default"},
+				Spec: DynamicConfigurationSpec{
+					DataIds:  []string{"app.yaml"},
+					Strategy: SyncStrategy{SyncPolicy: Always, SyncDirection: Cluster2Server},
+					NacosServer: NacosServerConfiguration{
+						ServerAddr: pointer.String(c.serverAddr),
+						Group:      "DEFAULT_GROUP",
+						AuthRef:    &corev1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+					},
+					ObjectRef: &corev1.ObjectReference{Name: "dc", APIVersion: "v1", Kind: "ConfigMap"},
+				},
+			}
+			_, err := dc.ValidateCreate()
+			if len(c.reason) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err.Error()).To(ContainSubstring("spec.nacosServer.serverAddr"))
+			g.Expect(err.Error()).To(ContainSubstring("at index 1"))
+			g.Expect(err.Error()).To(ContainSubstring(c.reason))
+		})
+	}
+}
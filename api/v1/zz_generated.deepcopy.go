@@ -23,6 +23,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -62,6 +63,189 @@ func (in *AdditionalConfiguration) DeepCopy() *AdditionalConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedNamespaces) DeepCopyInto(out *AllowedNamespaces) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedNamespaces.
+func (in *AllowedNamespaces) DeepCopy() *AllowedNamespaces {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedNamespaces)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNacosServer) DeepCopyInto(out *ClusterNacosServer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNacosServer.
+func (in *ClusterNacosServer) DeepCopy() *ClusterNacosServer {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterNacosServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterNacosServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNacosServerList) DeepCopyInto(out *ClusterNacosServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterNacosServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNacosServerList.
+func (in *ClusterNacosServerList) DeepCopy() *ClusterNacosServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterNacosServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterNacosServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNacosServerSpec) DeepCopyInto(out *ClusterNacosServerSpec) {
+	*out = *in
+	in.NacosServerSpec.DeepCopyInto(&out.NacosServerSpec)
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = new(AllowedNamespaces)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNacosServerSpec.
+func (in *ClusterNacosServerSpec) DeepCopy() *ClusterNacosServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterNacosServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContentTemplate) DeepCopyInto(out *ContentTemplate) {
+	*out = *in
+	if in.DataIds != nil {
+		in, out := &in.DataIds, &out.DataIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]TemplateValuesSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContentTemplate.
+func (in *ContentTemplate) DeepCopy() *ContentTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ContentTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataIdConfig) DeepCopyInto(out *DataIdConfig) {
+	*out = *in
+	if in.SyncDeletion != nil {
+		in, out := &in.SyncDeletion, &out.SyncDeletion
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataIdConfig.
+func (in *DataIdConfig) DeepCopy() *DataIdConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DataIdConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataIdSelector) DeepCopyInto(out *DataIdSelector) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataIdSelector.
+func (in *DataIdSelector) DeepCopy() *DataIdSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(DataIdSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DynamicConfiguration) DeepCopyInto(out *DynamicConfiguration) {
 	*out = *in
@@ -121,6 +305,69 @@ func (in *DynamicConfigurationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicConfigurationRevision) DeepCopyInto(out *DynamicConfigurationRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.ContentSecretRef != nil {
+		in, out := &in.ContentSecretRef, &out.ContentSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	in.SyncTime.DeepCopyInto(&out.SyncTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicConfigurationRevision.
+func (in *DynamicConfigurationRevision) DeepCopy() *DynamicConfigurationRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicConfigurationRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicConfigurationRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicConfigurationRevisionList) DeepCopyInto(out *DynamicConfigurationRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DynamicConfigurationRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicConfigurationRevisionList.
+func (in *DynamicConfigurationRevisionList) DeepCopy() *DynamicConfigurationRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicConfigurationRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicConfigurationRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DynamicConfigurationSpec) DeepCopyInto(out *DynamicConfigurationSpec) {
 	*out = *in
@@ -129,18 +376,64 @@ func (in *DynamicConfigurationSpec) DeepCopyInto(out *DynamicConfigurationSpec)
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Configs != nil {
+		in, out := &in.Configs, &out.Configs
+		*out = make([]DataIdConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DataIdSelector != nil {
+		in, out := &in.DataIdSelector, &out.DataIdSelector
+		*out = new(DataIdSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AdditionalConf != nil {
 		in, out := &in.AdditionalConf, &out.AdditionalConf
 		*out = new(AdditionalConfiguration)
 		(*in).DeepCopyInto(*out)
 	}
-	out.Strategy = in.Strategy
+	in.Strategy.DeepCopyInto(&out.Strategy)
 	in.NacosServer.DeepCopyInto(&out.NacosServer)
 	if in.ObjectRef != nil {
 		in, out := &in.ObjectRef, &out.ObjectRef
 		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
+	if in.NacosServerRef != nil {
+		in, out := &in.NacosServerRef, &out.NacosServerRef
+		*out = new(NacosServerReference)
+		**out = **in
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(ContentTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutTargets != nil {
+		in, out := &in.RolloutTargets, &out.RolloutTargets
+		*out = make([]RolloutTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(RollbackSpec)
+		**out = **in
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]SyncTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicConfigurationSpec.
@@ -168,6 +461,53 @@ func (in *DynamicConfigurationStatus) DeepCopyInto(out *DynamicConfigurationStat
 		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
+	if in.SelectedDataIds != nil {
+		in, out := &in.SelectedDataIds, &out.SelectedDataIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastResyncTime != nil {
+		in, out := &in.LastResyncTime, &out.LastResyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRollback != nil {
+		in, out := &in.LastRollback, &out.LastRollback
+		*out = new(RollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(SyncPlan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GrayStatuses != nil {
+		in, out := &in.GrayStatuses, &out.GrayStatuses
+		*out = make([]GrayStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TargetStatuses != nil {
+		in, out := &in.TargetStatuses, &out.TargetStatuses
+		*out = make([]TargetStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollouts != nil {
+		in, out := &in.Rollouts, &out.Rollouts
+		*out = make([]RolloutStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicConfigurationStatus.
@@ -181,43 +521,511 @@ func (in *DynamicConfigurationStatus) DeepCopy() *DynamicConfigurationStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NacosServerConfiguration) DeepCopyInto(out *NacosServerConfiguration) {
+func (in *GrayStatus) DeepCopyInto(out *GrayStatus) {
 	*out = *in
-	if in.Endpoint != nil {
-		in, out := &in.Endpoint, &out.Endpoint
-		*out = new(string)
-		**out = **in
-	}
-	if in.ServerAddr != nil {
-		in, out := &in.ServerAddr, &out.ServerAddr
-		*out = new(string)
-		**out = **in
+	if in.BetaIps != nil {
+		in, out := &in.BetaIps, &out.BetaIps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.AuthRef != nil {
-		in, out := &in.AuthRef, &out.AuthRef
-		*out = new(corev1.ObjectReference)
-		**out = **in
+	in.BetaTime.DeepCopyInto(&out.BetaTime)
+	if in.PromoteTime != nil {
+		in, out := &in.PromoteTime, &out.PromoteTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosServerConfiguration.
-func (in *NacosServerConfiguration) DeepCopy() *NacosServerConfiguration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrayStatus.
+func (in *GrayStatus) DeepCopy() *GrayStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NacosServerConfiguration)
+	out := new(GrayStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SyncStatus) DeepCopyInto(out *SyncStatus) {
+func (in *GrayStrategy) DeepCopyInto(out *GrayStrategy) {
 	*out = *in
-	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.BetaIps != nil {
+		in, out := &in.BetaIps, &out.BetaIps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PromoteAfter != nil {
+		in, out := &in.PromoteAfter, &out.PromoteAfter
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncStatus.
-func (in *SyncStatus) DeepCopy() *SyncStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrayStrategy.
+func (in *GrayStrategy) DeepCopy() *GrayStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(GrayStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosAuthKeys) DeepCopyInto(out *NacosAuthKeys) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosAuthKeys.
+func (in *NacosAuthKeys) DeepCopy() *NacosAuthKeys {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosAuthKeys)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosClientOptions) DeepCopyInto(out *NacosClientOptions) {
+	*out = *in
+	if in.TimeoutMs != nil {
+		in, out := &in.TimeoutMs, &out.TimeoutMs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LoadCacheAtStart != nil {
+		in, out := &in.LoadCacheAtStart, &out.LoadCacheAtStart
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DisableSnapshot != nil {
+		in, out := &in.DisableSnapshot, &out.DisableSnapshot
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BeatIntervalMs != nil {
+		in, out := &in.BeatIntervalMs, &out.BeatIntervalMs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ListenIntervalMs != nil {
+		in, out := &in.ListenIntervalMs, &out.ListenIntervalMs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.UpdateThreadNum != nil {
+		in, out := &in.UpdateThreadNum, &out.UpdateThreadNum
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosClientOptions.
+func (in *NacosClientOptions) DeepCopy() *NacosClientOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosClientOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosNamespace) DeepCopyInto(out *NacosNamespace) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosNamespace.
+func (in *NacosNamespace) DeepCopy() *NacosNamespace {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosNamespace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NacosNamespace) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosNamespaceList) DeepCopyInto(out *NacosNamespaceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NacosNamespace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosNamespaceList.
+func (in *NacosNamespaceList) DeepCopy() *NacosNamespaceList {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosNamespaceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NacosNamespaceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosNamespaceSpec) DeepCopyInto(out *NacosNamespaceSpec) {
+	*out = *in
+	out.NacosServerRef = in.NacosServerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosNamespaceSpec.
+func (in *NacosNamespaceSpec) DeepCopy() *NacosNamespaceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosNamespaceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosNamespaceStatus) DeepCopyInto(out *NacosNamespaceStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosNamespaceStatus.
+func (in *NacosNamespaceStatus) DeepCopy() *NacosNamespaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosNamespaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosServer) DeepCopyInto(out *NacosServer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosServer.
+func (in *NacosServer) DeepCopy() *NacosServer {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NacosServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosServerConfiguration) DeepCopyInto(out *NacosServerConfiguration) {
+	*out = *in
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServerAddr != nil {
+		in, out := &in.ServerAddr, &out.ServerAddr
+		*out = new(string)
+		**out = **in
+	}
+	if in.AuthRef != nil {
+		in, out := &in.AuthRef, &out.AuthRef
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.AuthKeys != nil {
+		in, out := &in.AuthKeys, &out.AuthKeys
+		*out = new(NacosAuthKeys)
+		**out = **in
+	}
+	if in.ClientOptions != nil {
+		in, out := &in.ClientOptions, &out.ClientOptions
+		*out = new(NacosClientOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(NacosServerTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosServerConfiguration.
+func (in *NacosServerConfiguration) DeepCopy() *NacosServerConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosServerConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosServerList) DeepCopyInto(out *NacosServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NacosServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosServerList.
+func (in *NacosServerList) DeepCopy() *NacosServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NacosServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosServerReference) DeepCopyInto(out *NacosServerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosServerReference.
+func (in *NacosServerReference) DeepCopy() *NacosServerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosServerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosServerSpec) DeepCopyInto(out *NacosServerSpec) {
+	*out = *in
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServerAddr != nil {
+		in, out := &in.ServerAddr, &out.ServerAddr
+		*out = new(string)
+		**out = **in
+	}
+	if in.AuthRef != nil {
+		in, out := &in.AuthRef, &out.AuthRef
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.AuthKeys != nil {
+		in, out := &in.AuthKeys, &out.AuthKeys
+		*out = new(NacosAuthKeys)
+		**out = **in
+	}
+	if in.ClientOptions != nil {
+		in, out := &in.ClientOptions, &out.ClientOptions
+		*out = new(NacosClientOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(NacosServerTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosServerSpec.
+func (in *NacosServerSpec) DeepCopy() *NacosServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosServerTLS) DeepCopyInto(out *NacosServerTLS) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosServerTLS.
+func (in *NacosServerTLS) DeepCopy() *NacosServerTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosServerTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedChange) DeepCopyInto(out *PlannedChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedChange.
+func (in *PlannedChange) DeepCopy() *PlannedChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackSpec) DeepCopyInto(out *RollbackSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackSpec.
+func (in *RollbackSpec) DeepCopy() *RollbackSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackStatus) DeepCopyInto(out *RollbackStatus) {
+	*out = *in
+	in.RollbackTime.DeepCopyInto(&out.RollbackTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackStatus.
+func (in *RollbackStatus) DeepCopy() *RollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	in.LastRolloutTime.DeepCopyInto(&out.LastRolloutTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutTarget) DeepCopyInto(out *RolloutTarget) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutTarget.
+func (in *RolloutTarget) DeepCopy() *RolloutTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncPlan) DeepCopyInto(out *SyncPlan) {
+	*out = *in
+	in.PlanTime.DeepCopyInto(&out.PlanTime)
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]PlannedChange, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncPlan.
+func (in *SyncPlan) DeepCopy() *SyncPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncStatus) DeepCopyInto(out *SyncStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncStatus.
+func (in *SyncStatus) DeepCopy() *SyncStatus {
 	if in == nil {
 		return nil
 	}
@@ -229,6 +1037,16 @@ func (in *SyncStatus) DeepCopy() *SyncStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncStrategy) DeepCopyInto(out *SyncStrategy) {
 	*out = *in
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Gray != nil {
+		in, out := &in.Gray, &out.Gray
+		*out = new(GrayStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncStrategy.
@@ -240,3 +1058,78 @@ func (in *SyncStrategy) DeepCopy() *SyncStrategy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncTarget) DeepCopyInto(out *SyncTarget) {
+	*out = *in
+	in.NacosServer.DeepCopyInto(&out.NacosServer)
+	if in.NacosServerRef != nil {
+		in, out := &in.NacosServerRef, &out.NacosServerRef
+		*out = new(NacosServerReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncTarget.
+func (in *SyncTarget) DeepCopy() *SyncTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetStatus) DeepCopyInto(out *TargetStatus) {
+	*out = *in
+	if in.SyncStatuses != nil {
+		in, out := &in.SyncStatuses, &out.SyncStatuses
+		*out = make([]SyncStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GrayStatuses != nil {
+		in, out := &in.GrayStatuses, &out.GrayStatuses
+		*out = make([]GrayStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetStatus.
+func (in *TargetStatus) DeepCopy() *TargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateValuesSource) DeepCopyInto(out *TemplateValuesSource) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateValuesSource.
+func (in *TemplateValuesSource) DeepCopy() *TemplateValuesSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateValuesSource)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -19,7 +19,9 @@ package main
 import (
 	"flag"
 	"github.com/nacos-group/nacos-controller/pkg/nacos"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -34,6 +36,7 @@ import (
 
 	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
 	"github.com/nacos-group/nacos-controller/pkg/controller"
+	"github.com/nacos-group/nacos-controller/pkg/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -54,12 +57,23 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var enableWebhook bool
+	var resyncInterval time.Duration
+	var listenerVerifyInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&enableWebhook, "enable-webhook", false, "Enable webhook for validation and defaulting")
+	flag.DurationVar(&resyncInterval, "resync-interval", 0,
+		"Default interval of periodic resync of DynamicConfiguration, overridden by spec.strategy.resyncInterval. "+
+			"Zero means resync is disabled.")
+	flag.DurationVar(&listenerVerifyInterval, "listener-verify-interval", time.Minute,
+		"Interval of verifying listeners of nacos configs and listening missing ones again, "+
+			"listeners of nacos sdk clients are not verified. "+
+			"Zero means verification is disabled.")
+	clientOptionsFlags := auth.ClientOptionsFlags{}
+	clientOptionsFlags.BindFlags(flag.CommandLine)
 	opts := zap.Options{
 		Development: true,
 	}
@@ -67,6 +81,7 @@ func main() {
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	clientOptions := clientOptionsFlags.ClientOptions()
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
@@ -92,16 +107,31 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = controller.NewDynamicConfigurationReconciler(mgr.GetClient(), mgr.GetScheme(), nacos.SyncConfigOptions{}).SetupWithManager(mgr); err != nil {
+	if err = controller.NewDynamicConfigurationReconciler(mgr.GetClient(), mgr.GetScheme(), nacos.SyncConfigOptions{
+		EventRecorder:          mgr.GetEventRecorderFor("nacos-controller"),
+		ResyncInterval:         resyncInterval,
+		ListenerVerifyInterval: listenerVerifyInterval,
+		ClientOptions:          clientOptions,
+		APIReader:              mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DynamicConfiguration")
 		os.Exit(1)
 	}
+	if err = controller.NewNacosNamespaceReconciler(mgr.GetClient(), mgr.GetScheme(),
+		mgr.GetEventRecorderFor("nacos-controller"), nil, clientOptions).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NacosNamespace")
+		os.Exit(1)
+	}
 	if enableWebhook {
 		setupLog.Info("webhook enabled")
 		if err = (&nacosiov1.DynamicConfiguration{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "DynamicConfiguration")
 			os.Exit(1)
 		}
+		if err = webhook.SetupObjectReferenceWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ObjectReference")
+			os.Exit(1)
+		}
 	}
 	//+kubebuilder:scaffold:builder
 
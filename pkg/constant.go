@@ -1,5 +1,9 @@
 package pkg
 
 const (
+	// ConfigMapLabel marks ConfigMaps and Secrets which are managed by a DynamicConfiguration
 	ConfigMapLabel string = "nacos.io/owned-by-dc"
+	// GrayPromoteAnnotation promotes beta configs of a DynamicConfiguration to all clients when it is "true",
+	// it is removed after promotion
+	GrayPromoteAnnotation string = "nacos.io/gray-promote"
 )
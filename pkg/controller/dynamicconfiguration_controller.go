@@ -20,12 +20,18 @@ import (
 	"context"
 	"fmt"
 	"github.com/nacos-group/nacos-controller/pkg"
+	"github.com/nacos-group/nacos-controller/pkg/metrics"
 	"github.com/nacos-group/nacos-controller/pkg/nacos"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"net/http"
 	runtimehandler "sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,23 +45,42 @@ import (
 const (
 	PhaseSucceed string = "succeed"
 	PhaseFailed  string = "failed"
+	// PhaseConflict means some dataIds are conflicted in bidirectional mode, and wait for manual resolving
+	PhaseConflict string = "conflict"
+	// PhaseSuspended means syncing is paused by spec.suspend
+	PhaseSuspended string = "suspended"
 )
 
 const (
 	FinalizerName string = "nacos.io/dc-finalizer"
 )
 
+const (
+	ReasonSyncFailed     string = "SyncFailed"
+	ReasonFinalizeFailed string = "FinalizeFailed"
+)
+
+const (
+	nacosServerRefIndexKey     string = "spec.nacosServerRef"
+	templateValuesFromIndexKey string = "spec.template.valuesFrom"
+)
+
 // DynamicConfigurationReconciler reconciles a DynamicConfiguration object
 type DynamicConfigurationReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
+	Recorder   record.EventRecorder
 	controller *nacos.SyncConfigurationController
 }
 
 func NewDynamicConfigurationReconciler(c client.Client, s *runtime.Scheme, opt nacos.SyncConfigOptions) *DynamicConfigurationReconciler {
+	if opt.EventRecorder == nil {
+		opt.EventRecorder = nacos.NewNopEventRecorder()
+	}
 	return &DynamicConfigurationReconciler{
 		Client:     c,
 		Scheme:     s,
+		Recorder:   opt.EventRecorder,
 		controller: nacos.NewSyncConfigurationController(c, opt),
 	}
 }
@@ -63,6 +88,12 @@ func NewDynamicConfigurationReconciler(c client.Client, s *runtime.Scheme, opt n
 //+kubebuilder:rbac:groups=nacos.io,resources=dynamicconfigurations,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=nacos.io,resources=dynamicconfigurations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=nacos.io,resources=dynamicconfigurations/finalizers,verbs=update
+//+kubebuilder:rbac:groups=nacos.io,resources=nacosservers;clusternacosservers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=nacos.io,resources=dynamicconfigurationrevisions,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -98,15 +129,32 @@ func (r *DynamicConfigurationReconciler) Reconcile(ctx context.Context, req ctrl
 	if err := r.ensureFinalizer(ctx, &dc); err != nil {
 		return ctrl.Result{}, err
 	}
+	if dc.Spec.Suspend {
+		return ctrl.Result{}, r.suspend(ctx, &dc)
+	}
 	err := r.controller.SyncDynamicConfiguration(ctx, &dc)
 	if err != nil {
 		l.Error(err, "sync error")
+		r.Recorder.Eventf(&dc, v1.EventTypeWarning, ReasonSyncFailed, "sync error: %s", err.Error())
 		failedStatus(&dc, err.Error())
 		_ = r.Status().Update(ctx, &dc)
 		return ctrl.Result{}, err
 	}
 	updateStatus(&dc)
-	return ctrl.Result{}, r.Status().Update(ctx, &dc)
+	return ctrl.Result{RequeueAfter: r.controller.RequeueAfter(&dc)}, r.Status().Update(ctx, &dc)
+}
+
+// suspend skips syncing of dc, and reports it in status. Listeners are kept, and callbacks are ignored.
+func (r *DynamicConfigurationReconciler) suspend(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
+	if !nacos.WasSuspended(dc) {
+		log.FromContext(ctx).Info("syncing suspended")
+		r.Recorder.Event(dc, v1.EventTypeNormal, nacos.ReasonSuspended, "syncing suspended")
+	}
+	nacos.SetSuspendedCondition(dc)
+	dc.Status.Phase = PhaseSuspended
+	dc.Status.Message = "syncing is suspended"
+	dc.Status.ObservedGeneration = dc.Generation
+	return r.Status().Update(ctx, dc)
 }
 
 func (r *DynamicConfigurationReconciler) ensureFinalizer(ctx context.Context, obj client.Object) error {
@@ -130,12 +178,15 @@ func (r *DynamicConfigurationReconciler) doFinalization(ctx context.Context, dc
 	l := log.FromContext(ctx)
 	// 执行清理逻辑
 	if err := r.controller.Finalize(ctx, dc); err != nil {
+		r.Recorder.Eventf(dc, v1.EventTypeWarning, ReasonFinalizeFailed, "finalize error: %s", err.Error())
 		failedStatus(dc, err.Error())
+		nacos.SetNotReadyCondition(dc, ReasonFinalizeFailed, err.Error())
 		if e := r.Status().Update(ctx, dc); e != nil {
 			l.Error(e, "update status error")
 		}
 		return err
 	}
+	metrics.NotReadyDataIds.DeleteLabelValues(dc.Namespace, dc.Name)
 	l.Info("Remove finalizer")
 	dc.SetFinalizers(pkg.Remove(dc.GetFinalizers(), FinalizerName))
 	if err := r.Update(ctx, dc); err != nil {
@@ -160,6 +211,77 @@ func (r *DynamicConfigurationReconciler) findDynamicConfiguration(ctx context.Co
 	}
 }
 
+// findDynamicConfigurationByConfigMap finds owner of the ConfigMap, and DynamicConfigurations which read template
+// values from it.
+func (r *DynamicConfigurationReconciler) findDynamicConfigurationByConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	requests := r.findDynamicConfiguration(ctx, obj)
+	return append(requests, r.findDynamicConfigurationsByTemplateValues(ctx, "ConfigMap", obj)...)
+}
+
+// findDynamicConfigurationBySecret finds owner of the Secret, and DynamicConfigurations which use it as auth or tls
+// Secret, so that nacos config clients are rebuilt when credentials or certificates are rotated. DynamicConfigurations which read template
+// values from it are also found.
+func (r *DynamicConfigurationReconciler) findDynamicConfigurationBySecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	requests := r.findDynamicConfiguration(ctx, obj)
+	dcList := r.controller.GetDCListByAuthSecret(types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+	for _, nn := range dcList {
+		requests = append(requests, reconcile.Request{NamespacedName: nn})
+	}
+	return append(requests, r.findDynamicConfigurationsByTemplateValues(ctx, "Secret", obj)...)
+}
+
+func (r *DynamicConfigurationReconciler) findDynamicConfigurationsByTemplateValues(ctx context.Context, kind string, obj client.Object) []reconcile.Request {
+	dcList := nacosiov1.DynamicConfigurationList{}
+	if err := r.List(ctx, &dcList, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{templateValuesFromIndexKey: kind + "/" + obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "list DynamicConfiguration by template values error", "kind", kind, "name", obj.GetName())
+		return []reconcile.Request{}
+	}
+	var requests []reconcile.Request
+	for _, dc := range dcList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      dc.Name,
+				Namespace: dc.Namespace,
+			},
+		})
+	}
+	return requests
+}
+
+func (r *DynamicConfigurationReconciler) findDynamicConfigurationsByNacosServer(ctx context.Context, obj client.Object) []reconcile.Request {
+	kind := nacosiov1.NacosServerKind
+	var opts []client.ListOption
+	if _, ok := obj.(*nacosiov1.ClusterNacosServer); ok {
+		kind = nacosiov1.ClusterNacosServerKind
+	} else {
+		opts = append(opts, client.InNamespace(obj.GetNamespace()))
+	}
+	opts = append(opts, client.MatchingFields{nacosServerRefIndexKey: nacosServerRefIndexValue(kind, obj.GetName())})
+	dcList := nacosiov1.DynamicConfigurationList{}
+	if err := r.List(ctx, &dcList, opts...); err != nil {
+		log.FromContext(ctx).Error(err, "list DynamicConfiguration by nacos server error", "kind", kind, "name", obj.GetName())
+		return []reconcile.Request{}
+	}
+	var requests []reconcile.Request
+	for _, dc := range dcList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      dc.Name,
+				Namespace: dc.Namespace,
+			},
+		})
+	}
+	return requests
+}
+
+func nacosServerRefIndexValue(kind, name string) string {
+	if len(kind) == 0 {
+		kind = nacosiov1.NacosServerKind
+	}
+	return kind + "/" + name
+}
+
 func failedStatus(dc *nacosiov1.DynamicConfiguration, message string) {
 	if dc == nil {
 		return
@@ -167,6 +289,13 @@ func failedStatus(dc *nacosiov1.DynamicConfiguration, message string) {
 	dc.Status.Phase = PhaseFailed
 	dc.Status.Message = message
 	dc.Status.ObservedGeneration = dc.Generation
+	notReady := 0
+	for _, syncStatus := range dc.Status.SyncStatuses {
+		if !syncStatus.Ready {
+			notReady++
+		}
+	}
+	metrics.NotReadyDataIds.WithLabelValues(dc.Namespace, dc.Name).Set(float64(notReady))
 }
 
 func updateStatus(dc *nacosiov1.DynamicConfiguration) {
@@ -178,22 +307,87 @@ func updateStatus(dc *nacosiov1.DynamicConfiguration) {
 	dc.Status.Message = ""
 
 	var notReadyDataIds []string
+	var conflictDataIds []string
 	for _, syncStatus := range dc.Status.SyncStatuses {
-		if !syncStatus.Ready {
+		if syncStatus.Conflict {
+			conflictDataIds = append(conflictDataIds, syncStatus.DataId)
+		} else if !syncStatus.Ready {
 			notReadyDataIds = append(notReadyDataIds, syncStatus.DataId)
 		}
 	}
+	metrics.NotReadyDataIds.WithLabelValues(dc.Namespace, dc.Name).Set(float64(len(notReadyDataIds) + len(conflictDataIds)))
 	if len(notReadyDataIds) > 0 {
 		dc.Status.Phase = PhaseFailed
 		dc.Status.Message = fmt.Sprintf("not ready dataIds: %s", strings.Join(notReadyDataIds, ","))
+	} else if len(conflictDataIds) > 0 {
+		dc.Status.Phase = PhaseConflict
+		dc.Status.Message = fmt.Sprintf("conflict dataIds: %s", strings.Join(conflictDataIds, ","))
+	}
+}
+
+// ListenersReadyCheck fails until listeners are rehydrated after elected as leader. Standby replicas don't listen,
+// so they are always ready.
+func (r *DynamicConfigurationReconciler) ListenersReadyCheck(elected <-chan struct{}) healthz.Checker {
+	return func(_ *http.Request) error {
+		select {
+		case <-elected:
+		default:
+			return nil
+		}
+		if !r.controller.ListenersRehydrated() {
+			return fmt.Errorf("listeners not rehydrated yet")
+		}
+		return nil
 	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Listeners are run by the leader only, and released when leadership is lost.
 func (r *DynamicConfigurationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.Add(manager.RunnableFunc(r.controller.RunListeners)); err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("listeners", r.ListenersReadyCheck(mgr.Elected())); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &nacosiov1.DynamicConfiguration{}, nacosServerRefIndexKey, func(obj client.Object) []string {
+		ref := obj.(*nacosiov1.DynamicConfiguration).Spec.NacosServerRef
+		if ref == nil {
+			return nil
+		}
+		return []string{nacosServerRefIndexValue(ref.Kind, ref.Name)}
+	}); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &nacosiov1.DynamicConfiguration{}, templateValuesFromIndexKey, func(obj client.Object) []string {
+		tpl := obj.(*nacosiov1.DynamicConfiguration).Spec.Template
+		if tpl == nil {
+			return nil
+		}
+		var values []string
+		for _, source := range tpl.ValuesFrom {
+			if source.ConfigMapRef != nil {
+				values = append(values, "ConfigMap/"+source.ConfigMapRef.Name)
+			}
+			if source.SecretRef != nil {
+				values = append(values, "Secret/"+source.SecretRef.Name)
+			}
+		}
+		return values
+	}); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&nacosiov1.DynamicConfiguration{}).
 		WatchesMetadata(&v1.ConfigMap{},
-			runtimehandler.EnqueueRequestsFromMapFunc(r.findDynamicConfiguration)).
+			runtimehandler.EnqueueRequestsFromMapFunc(r.findDynamicConfigurationByConfigMap)).
+		WatchesMetadata(&v1.Secret{},
+			runtimehandler.EnqueueRequestsFromMapFunc(r.findDynamicConfigurationBySecret)).
+		Watches(&nacosiov1.NacosServer{},
+			runtimehandler.EnqueueRequestsFromMapFunc(r.findDynamicConfigurationsByNacosServer)).
+		Watches(&nacosiov1.ClusterNacosServer{},
+			runtimehandler.EnqueueRequestsFromMapFunc(r.findDynamicConfigurationsByNacosServer)).
+		WatchesRawSource(&source.Channel{Source: r.controller.Events()},
+			&runtimehandler.EnqueueRequestForObject{}).
 		Complete(r)
 }
@@ -3,28 +3,24 @@ package controller
 import (
 	"fmt"
 	v12 "github.com/nacos-group/nacos-controller/api/v1"
-	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
-	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 	. "github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	"math/rand"
-	"os"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"time"
 )
 
 const (
-	nacosServerAk        = "NACOS_SERVER_ACCESSKEY"
-	nacosServerSk        = "NACOS_SERVER_SECRETKEY"
-	nacosServerEndpoint  = "NACOS_SERVER_ENDPOINT"
-	nacosServerNamespace = "NACOS_SERVER_NAMESPACE"
+	nacosServerAddr      = "127.0.0.1:8848"
+	nacosServerNamespace = "dc-suite-test"
 )
 
 const (
@@ -35,10 +31,6 @@ const (
 
 var _ = Describe("DynamicConfigurationController", func() {
 	BeforeEach(func() {
-		ensureEnvKeyExist(nacosServerAk)
-		ensureEnvKeyExist(nacosServerSk)
-		ensureEnvKeyExist(nacosServerEndpoint)
-		ensureEnvKeyExist(nacosServerNamespace)
 		ensureNamespaceExist(dcTestNamespaceStr)
 		nacosCredential := v1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
@@ -46,8 +38,8 @@ var _ = Describe("DynamicConfigurationController", func() {
 				Namespace: dcTestNamespaceStr,
 			},
 			Data: map[string][]byte{
-				"ak": []byte(os.Getenv(nacosServerAk)),
-				"sk": []byte(os.Getenv(nacosServerSk)),
+				"ak": []byte("suite-test-ak"),
+				"sk": []byte("suite-test-sk"),
 			},
 		}
 		ensureSecretExist(&nacosCredential)
@@ -80,9 +72,9 @@ var _ = Describe("DynamicConfigurationController", func() {
 						SyncDirection: v12.Cluster2Server,
 					},
 					NacosServer: v12.NacosServerConfiguration{
-						Endpoint:  pointer.String(os.Getenv(nacosServerEndpoint)),
-						Namespace: os.Getenv(nacosServerNamespace),
-						Group:     fmt.Sprintf("suite-test-group-cluster2server-%d", randInt),
+						ServerAddr: pointer.String(nacosServerAddr),
+						Namespace:  nacosServerNamespace,
+						Group:      fmt.Sprintf("suite-test-group-cluster2server-%d", randInt),
 						AuthRef: &v1.ObjectReference{
 							Name:       dcTestNacosCredentialName,
 							APIVersion: "v1",
@@ -164,9 +156,9 @@ var _ = Describe("DynamicConfigurationController", func() {
 						SyncDirection: v12.Server2Cluster,
 					},
 					NacosServer: v12.NacosServerConfiguration{
-						Endpoint:  pointer.String(os.Getenv(nacosServerEndpoint)),
-						Namespace: os.Getenv(nacosServerNamespace),
-						Group:     group,
+						ServerAddr: pointer.String(nacosServerAddr),
+						Namespace:  nacosServerNamespace,
+						Group:      group,
 						AuthRef: &v1.ObjectReference{
 							Name:       dcTestNacosCredentialName,
 							APIVersion: "v1",
@@ -209,6 +201,54 @@ var _ = Describe("DynamicConfigurationController", func() {
 				return true
 			}, time.Second*30, time.Second*5).Should(gomega.BeTrue())
 		})
+		It("Suspend & Resume", func() {
+			rand.Seed(time.Now().Unix())
+			randInt := rand.Int()
+			dataId := fmt.Sprintf("randon-data-id-%d", randInt)
+			content := fmt.Sprintf("content-%d", randInt)
+			group := fmt.Sprintf("suite-test-group-suspend-%d", randInt)
+			dc := v12.DynamicConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dc-suite-test-suspend",
+					Namespace: dcTestNamespaceStr,
+				},
+				Spec: v12.DynamicConfigurationSpec{
+					DataIds: []string{dataId},
+					Suspend: true,
+					Strategy: v12.SyncStrategy{
+						SyncPolicy:    v12.Always,
+						SyncDirection: v12.Server2Cluster,
+					},
+					NacosServer: v12.NacosServerConfiguration{
+						ServerAddr: pointer.String(nacosServerAddr),
+						Namespace:  nacosServerNamespace,
+						Group:      group,
+						AuthRef: &v1.ObjectReference{
+							Name:       dcTestNacosCredentialName,
+							APIVersion: "v1",
+							Kind:       "Secret",
+						},
+					},
+				},
+			}
+			By("create a suspended DynamicConfiguration")
+			createOrUpdateContentInNaocs(&dc, dataId, content)
+			ensureDynamicConfigurationExist(&dc)
+			gomega.Eventually(func() string {
+				gomega.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: dc.Name, Namespace: dc.Namespace}, &dc)).Should(gomega.Succeed())
+				return dc.Status.Phase
+			}, time.Second*30, time.Second*5).Should(gomega.Equal(PhaseSuspended))
+			gomega.Expect(meta.IsStatusConditionTrue(dc.Status.Conditions, v12.ConditionSuspended)).Should(gomega.BeTrue())
+			gomega.Expect(checkConfigMapWithDataIdAndContent(dc.Name, dc.Namespace, dataId, content)).Should(gomega.BeFalse())
+			By("resume the DynamicConfiguration")
+			dc.Spec.Suspend = false
+			gomega.Expect(k8sClient.Update(ctx, &dc)).Should(gomega.Succeed())
+			checkDynamicConfigurationStatus(&dc)
+			gomega.Expect(meta.FindStatusCondition(dc.Status.Conditions, v12.ConditionSuspended)).Should(gomega.BeNil())
+			gomega.Expect(checkConfigMapWithDataIdAndContent(dc.Name, dc.Namespace, dataId, content)).Should(gomega.BeTrue())
+			deleteDataIdInNaocs(&dc, dataId)
+			gomega.Expect(k8sClient.Delete(ctx, &dc)).Should(gomega.Succeed())
+		})
 		It("With spec.ObjectRef", func() {
 			rand.Seed(time.Now().Unix() + 2)
 			randInt := rand.Int()
@@ -234,9 +274,9 @@ var _ = Describe("DynamicConfigurationController", func() {
 						SyncDirection: v12.Server2Cluster,
 					},
 					NacosServer: v12.NacosServerConfiguration{
-						Endpoint:  pointer.String(os.Getenv(nacosServerEndpoint)),
-						Namespace: os.Getenv(nacosServerNamespace),
-						Group:     group,
+						ServerAddr: pointer.String(nacosServerAddr),
+						Namespace:  nacosServerNamespace,
+						Group:      group,
 						AuthRef: &v1.ObjectReference{
 							Name:       dcTestNacosCredentialName,
 							APIVersion: "v1",
@@ -391,42 +431,15 @@ func checkDynamicConfigurationStatus(dc *v12.DynamicConfiguration) {
 }
 
 func getContentByDataId(dc *v12.DynamicConfiguration) (string, bool) {
-	configClient, err := auth.GetNacosAuthManger().GetNacosConfigClient(&auth.DefaultNaocsAuthProvider{Client: k8sClient}, dc)
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
-	content, err := configClient.GetConfig(vo.ConfigParam{
-		Group:  dc.Spec.NacosServer.Group,
-		DataId: dc.Spec.DataIds[0],
-	})
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
-	return content, len(content) > 0
+	return nacosServer.Get(dc.Spec.NacosServer.Namespace, dc.Spec.NacosServer.Group, dc.Spec.DataIds[0])
 }
 
 func createOrUpdateContentInNaocs(dc *v12.DynamicConfiguration, dataId, content string) {
-	configClient, err := auth.GetNacosAuthManger().GetNacosConfigClient(&auth.DefaultNaocsAuthProvider{Client: k8sClient}, dc)
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
-	serverContent, err := configClient.GetConfig(vo.ConfigParam{
-		Group:  dc.Spec.NacosServer.Group,
-		DataId: dataId,
-	})
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
-	if len(serverContent) == 0 || serverContent != content {
-		_, err := configClient.PublishConfig(vo.ConfigParam{
-			Group:   dc.Spec.NacosServer.Group,
-			DataId:  dataId,
-			Content: content,
-		})
-		gomega.Expect(err).NotTo(gomega.HaveOccurred())
-	}
+	nacosServer.Publish(dc.Spec.NacosServer.Namespace, dc.Spec.NacosServer.Group, dataId, content)
 }
 
 func deleteDataIdInNaocs(dc *v12.DynamicConfiguration, dataId string) {
-	configClient, err := auth.GetNacosAuthManger().GetNacosConfigClient(&auth.DefaultNaocsAuthProvider{Client: k8sClient}, dc)
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
-	_, err = configClient.DeleteConfig(vo.ConfigParam{
-		Group:  dc.Spec.NacosServer.Group,
-		DataId: dataId,
-	})
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	nacosServer.Delete(dc.Spec.NacosServer.Namespace, dc.Spec.NacosServer.Group, dataId)
 }
 
 func checkConfigMapWithDataIdAndContent(name, namespace, dataId, content string) bool {
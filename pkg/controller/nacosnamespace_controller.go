@@ -0,0 +1,284 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nacos-group/nacos-controller/pkg"
+	"github.com/nacos-group/nacos-controller/pkg/nacos"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/console"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimehandler "sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+)
+
+const (
+	NamespaceFinalizerName string = "nacos.io/namespace-finalizer"
+)
+
+// Reasons of events and Ready condition of NacosNamespace
+const (
+	ReasonNamespaceCreated         string = "Created"
+	ReasonNamespaceUpdated         string = "Updated"
+	ReasonNamespaceDeleted         string = "Deleted"
+	ReasonNamespaceDeletionBlocked string = "DeletionBlocked"
+	ReasonNamespaceSynced          string = "Synced"
+	ReasonInvalidServerRef         string = "InvalidServerRef"
+)
+
+// namespaceDeletionRetryInterval is the interval to check configs of a namespace again, when its deletion is blocked
+const namespaceDeletionRetryInterval = time.Minute
+
+// NacosNamespaceReconciler reconciles a NacosNamespace object
+type NacosNamespaceReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Recorder     record.EventRecorder
+	authProvider *auth.DefaultNaocsAuthProvider
+	httpClient   *http.Client
+}
+
+// NewNacosNamespaceReconciler returns a NacosNamespaceReconciler, default http client is used if httpClient is nil.
+// clientOptions are default client options, only contextPath is used by console API.
+func NewNacosNamespaceReconciler(c client.Client, s *runtime.Scheme, recorder record.EventRecorder, httpClient *http.Client, clientOptions *nacosiov1.NacosClientOptions) *NacosNamespaceReconciler {
+	if recorder == nil {
+		recorder = nacos.NewNopEventRecorder()
+	}
+	return &NacosNamespaceReconciler{
+		Client:       c,
+		Scheme:       s,
+		Recorder:     recorder,
+		authProvider: &auth.DefaultNaocsAuthProvider{Client: c, DefaultClientOptions: clientOptions},
+		httpClient:   httpClient,
+	}
+}
+
+//+kubebuilder:rbac:groups=nacos.io,resources=nacosnamespaces,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=nacos.io,resources=nacosnamespaces/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=nacos.io,resources=nacosnamespaces/finalizers,verbs=update
+
+// Reconcile creates or updates the namespace in nacos server, and deletes it when NacosNamespace is deleted
+func (r *NacosNamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx)
+	ns := nacosiov1.NacosNamespace{}
+	if err := r.Get(ctx, req.NamespacedName, &ns); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		l.Error(err, "get NacosNamespace error")
+		return ctrl.Result{}, err
+	}
+	if ns.DeletionTimestamp == nil && !pkg.Contains(ns.GetFinalizers(), NamespaceFinalizerName) {
+		ns.SetFinalizers(append(ns.GetFinalizers(), NamespaceFinalizerName))
+		if err := r.Update(ctx, &ns); err != nil {
+			l.Error(err, "update finalizer error")
+			return ctrl.Result{}, err
+		}
+	}
+	if ns.DeletionTimestamp != nil && !ns.Status.Created {
+		// nothing was created in nacos server, no console client is required
+		return ctrl.Result{}, r.removeFinalizer(ctx, &ns)
+	}
+	consoleClient, err := r.getConsoleClient(&ns)
+	if err != nil {
+		l.Error(err, "resolve nacos server error")
+		r.Recorder.Eventf(&ns, v1.EventTypeWarning, ReasonInvalidServerRef, "resolve nacos server error: %s", err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &ns, metav1.ConditionFalse, ReasonInvalidServerRef, err.Error())
+	}
+	if ns.DeletionTimestamp != nil {
+		return r.finalize(ctx, &ns, consoleClient)
+	}
+	if err := r.sync(ctx, &ns, consoleClient); err != nil {
+		l.Error(err, "sync namespace error")
+		r.Recorder.Eventf(&ns, v1.EventTypeWarning, ReasonSyncFailed, "sync namespace error: %s", err.Error())
+		_ = r.updateStatus(ctx, &ns, metav1.ConditionFalse, ReasonSyncFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.updateStatus(ctx, &ns, metav1.ConditionTrue, ReasonNamespaceSynced, "")
+}
+
+// sync creates the namespace if it doesn't exist, or updates its show name and description if they are changed.
+// An existing namespace is adopted, and it is not recorded as created.
+func (r *NacosNamespaceReconciler) sync(ctx context.Context, ns *nacosiov1.NacosNamespace, consoleClient *console.Client) error {
+	id := ns.GetNamespaceId()
+	if len(ns.Status.NamespaceId) > 0 && ns.Status.NamespaceId != id {
+		return fmt.Errorf("namespaceId can not be changed from %s to %s", ns.Status.NamespaceId, id)
+	}
+	current, err := consoleClient.GetNamespace(ctx, id)
+	if err != nil {
+		return err
+	}
+	name, desc := ns.GetDisplayName(), ns.Spec.Description
+	if current == nil {
+		if err := consoleClient.CreateNamespace(ctx, id, name, desc); err != nil {
+			return err
+		}
+		log.FromContext(ctx).Info("namespace created", "namespaceId", id)
+		r.Recorder.Eventf(ns, v1.EventTypeNormal, ReasonNamespaceCreated, "namespace %s created in nacos server", id)
+		ns.Status.NamespaceId = id
+		ns.Status.ConfigCount = 0
+		ns.Status.Created = true
+		// record it at once, otherwise the namespace would be adopted as an existing one by the next reconcile
+		if err := r.Status().Update(ctx, ns); err != nil {
+			return err
+		}
+	} else {
+		if current.NamespaceShowName != name || current.NamespaceDesc != desc {
+			if err := consoleClient.UpdateNamespace(ctx, id, name, desc); err != nil {
+				return err
+			}
+			log.FromContext(ctx).Info("namespace updated", "namespaceId", id)
+			r.Recorder.Eventf(ns, v1.EventTypeNormal, ReasonNamespaceUpdated, "namespace %s updated in nacos server", id)
+		}
+		ns.Status.ConfigCount = current.ConfigCount
+	}
+	ns.Status.NamespaceId = id
+	return nil
+}
+
+// finalize deletes the namespace created by NacosNamespace in nacos server. Deletion is blocked if the namespace
+// still holds configs, unless spec.forceDelete is true.
+func (r *NacosNamespaceReconciler) finalize(ctx context.Context, ns *nacosiov1.NacosNamespace, consoleClient *console.Client) (ctrl.Result, error) {
+	if !pkg.Contains(ns.GetFinalizers(), NamespaceFinalizerName) {
+		return ctrl.Result{}, nil
+	}
+	l := log.FromContext(ctx)
+	id := ns.Status.NamespaceId
+	if len(id) > 0 && ns.Status.Created {
+		current, err := consoleClient.GetNamespace(ctx, id)
+		if err != nil {
+			l.Error(err, "get namespace error")
+			_ = r.updateStatus(ctx, ns, metav1.ConditionFalse, ReasonFinalizeFailed, err.Error())
+			return ctrl.Result{}, err
+		}
+		if current != nil {
+			if current.ConfigCount > 0 && !ns.Spec.ForceDelete {
+				message := fmt.Sprintf("namespace %s still holds %d configs, set spec.forceDelete to delete it anyway", id, current.ConfigCount)
+				l.Info("namespace deletion blocked", "configCount", current.ConfigCount)
+				r.Recorder.Event(ns, v1.EventTypeWarning, ReasonNamespaceDeletionBlocked, message)
+				ns.Status.ConfigCount = current.ConfigCount
+				return ctrl.Result{RequeueAfter: namespaceDeletionRetryInterval}, r.updateStatus(ctx, ns, metav1.ConditionFalse, ReasonNamespaceDeletionBlocked, message)
+			}
+			if err := consoleClient.DeleteNamespace(ctx, id); err != nil {
+				l.Error(err, "delete namespace error")
+				r.Recorder.Eventf(ns, v1.EventTypeWarning, ReasonFinalizeFailed, "delete namespace error: %s", err.Error())
+				_ = r.updateStatus(ctx, ns, metav1.ConditionFalse, ReasonFinalizeFailed, err.Error())
+				return ctrl.Result{}, err
+			}
+			l.Info("namespace deleted", "namespaceId", id)
+			r.Recorder.Eventf(ns, v1.EventTypeNormal, ReasonNamespaceDeleted, "namespace %s deleted in nacos server", id)
+		}
+	}
+	return ctrl.Result{}, r.removeFinalizer(ctx, ns)
+}
+
+func (r *NacosNamespaceReconciler) removeFinalizer(ctx context.Context, ns *nacosiov1.NacosNamespace) error {
+	if !pkg.Contains(ns.GetFinalizers(), NamespaceFinalizerName) {
+		return nil
+	}
+	l := log.FromContext(ctx)
+	l.Info("Remove finalizer")
+	ns.SetFinalizers(pkg.Remove(ns.GetFinalizers(), NamespaceFinalizerName))
+	if err := r.Update(ctx, ns); err != nil {
+		l.Error(err, "remove finalizer error")
+		return err
+	}
+	return nil
+}
+
+func (r *NacosNamespaceReconciler) getConsoleClient(ns *nacosiov1.NacosNamespace) (*console.Client, error) {
+	params, err := r.authProvider.GetNacosServerParams(ns.Namespace, &ns.Spec.NacosServerRef)
+	if err != nil {
+		return nil, err
+	}
+	return console.NewClient(params, r.httpClient)
+}
+
+func (r *NacosNamespaceReconciler) updateStatus(ctx context.Context, ns *nacosiov1.NacosNamespace, status metav1.ConditionStatus, reason, message string) error {
+	ns.Status.ObservedGeneration = ns.Generation
+	meta.SetStatusCondition(&ns.Status.Conditions, metav1.Condition{
+		Type:               nacosiov1.ConditionReady,
+		Status:             status,
+		ObservedGeneration: ns.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if err := r.Status().Update(ctx, ns); err != nil {
+		log.FromContext(ctx).Error(err, "update status error")
+		return err
+	}
+	return nil
+}
+
+func (r *NacosNamespaceReconciler) findNacosNamespacesByNacosServer(ctx context.Context, obj client.Object) []reconcile.Request {
+	kind := nacosiov1.NacosServerKind
+	var opts []client.ListOption
+	if _, ok := obj.(*nacosiov1.ClusterNacosServer); ok {
+		kind = nacosiov1.ClusterNacosServerKind
+	} else {
+		opts = append(opts, client.InNamespace(obj.GetNamespace()))
+	}
+	opts = append(opts, client.MatchingFields{nacosServerRefIndexKey: nacosServerRefIndexValue(kind, obj.GetName())})
+	nsList := nacosiov1.NacosNamespaceList{}
+	if err := r.List(ctx, &nsList, opts...); err != nil {
+		log.FromContext(ctx).Error(err, "list NacosNamespace by nacos server error", "kind", kind, "name", obj.GetName())
+		return []reconcile.Request{}
+	}
+	var requests []reconcile.Request
+	for _, ns := range nsList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      ns.Name,
+				Namespace: ns.Namespace,
+			},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NacosNamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &nacosiov1.NacosNamespace{}, nacosServerRefIndexKey, func(obj client.Object) []string {
+		ref := obj.(*nacosiov1.NacosNamespace).Spec.NacosServerRef
+		return []string{nacosServerRefIndexValue(ref.Kind, ref.Name)}
+	}); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nacosiov1.NacosNamespace{}).
+		Watches(&nacosiov1.NacosServer{},
+			runtimehandler.EnqueueRequestsFromMapFunc(r.findNacosNamespacesByNacosServer)).
+		Watches(&nacosiov1.ClusterNacosServer{},
+			runtimehandler.EnqueueRequestsFromMapFunc(r.findNacosNamespacesByNacosServer)).
+		Complete(r)
+}
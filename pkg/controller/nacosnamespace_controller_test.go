@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	v12 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	nnsTestNacosServerName = "nns-suite-test"
+	nnsTestNamespaceId     = "nns-suite-test"
+)
+
+var _ = Describe("NacosNamespaceController", func() {
+	BeforeEach(func() {
+		ensureNamespaceExist(dcTestNamespaceStr)
+		ensureSecretExist(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      dcTestNacosCredentialName,
+				Namespace: dcTestNamespaceStr,
+			},
+			Data: map[string][]byte{
+				"ak": []byte("suite-test-ak"),
+				"sk": []byte("suite-test-sk"),
+			},
+		})
+		ensureObjectExist(&v12.NacosServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      nnsTestNacosServerName,
+				Namespace: dcTestNamespaceStr,
+			},
+			Spec: v12.NacosServerSpec{
+				ServerAddr: pointer.String(strings.TrimPrefix(consoleServer.URL, "http://")),
+				AuthRef:    &v1.ObjectReference{Name: dcTestNacosCredentialName, APIVersion: "v1", Kind: "Secret"},
+			},
+		}, v12.GroupVersion.WithKind(v12.NacosServerKind))
+	})
+
+	It("Create, update & protected deletion", func() {
+		nns := v12.NacosNamespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nns-suite-test",
+				Namespace: dcTestNamespaceStr,
+			},
+			Spec: v12.NacosNamespaceSpec{
+				NacosServerRef: v12.NacosServerReference{Name: nnsTestNacosServerName},
+				NamespaceId:    nnsTestNamespaceId,
+				DisplayName:    "suite test",
+			},
+		}
+		nn := types.NamespacedName{Namespace: nns.Namespace, Name: nns.Name}
+		gomega.Expect(k8sClient.Create(ctx, &nns)).Should(gomega.Succeed())
+		gomega.Eventually(func() bool {
+			ns, ok := namespaceServer.Namespace(nnsTestNamespaceId)
+			return ok && ns.ShowName == "suite test"
+		}, time.Second*30, time.Second).Should(gomega.BeTrue())
+		gomega.Eventually(func() bool {
+			_ = k8sClient.Get(ctx, nn, &nns)
+			return meta.IsStatusConditionTrue(nns.Status.Conditions, v12.ConditionReady)
+		}, time.Second*30, time.Second).Should(gomega.BeTrue())
+
+		nns.Spec.Description = "updated"
+		gomega.Expect(k8sClient.Update(ctx, &nns)).Should(gomega.Succeed())
+		gomega.Eventually(func() string {
+			ns, _ := namespaceServer.Namespace(nnsTestNamespaceId)
+			return ns.Description
+		}, time.Second*30, time.Second).Should(gomega.Equal("updated"))
+
+		nacosServer.Publish(nnsTestNamespaceId, "DEFAULT_GROUP", "app.yaml", "a: 1")
+		gomega.Expect(k8sClient.Delete(ctx, &nns)).Should(gomega.Succeed())
+		gomega.Eventually(func() string {
+			_ = k8sClient.Get(ctx, nn, &nns)
+			c := meta.FindStatusCondition(nns.Status.Conditions, v12.ConditionReady)
+			if c == nil {
+				return ""
+			}
+			return c.Reason
+		}, time.Second*30, time.Second).Should(gomega.Equal(ReasonNamespaceDeletionBlocked))
+		_, ok := namespaceServer.Namespace(nnsTestNamespaceId)
+		gomega.Expect(ok).Should(gomega.BeTrue())
+
+		nns.Spec.ForceDelete = true
+		gomega.Expect(k8sClient.Update(ctx, &nns)).Should(gomega.Succeed())
+		gomega.Eventually(func() bool {
+			return errors.IsNotFound(k8sClient.Get(ctx, nn, &nns))
+		}, time.Second*30, time.Second).Should(gomega.BeTrue())
+		_, ok = namespaceServer.Namespace(nnsTestNamespaceId)
+		gomega.Expect(ok).Should(gomega.BeFalse())
+	})
+})
+
+var _ = Describe("NacosNamespaceReconciler finalizer", func() {
+	var ctx context.Context
+	var c client.Client
+	var server *fake.NamespaceServer
+	var httpServer *httptest.Server
+	var r *NacosNamespaceReconciler
+
+	newNacosNamespace := func(name string) *v12.NacosNamespace {
+		return &v12.NacosNamespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: dcTestNamespaceStr},
+			Spec: v12.NacosNamespaceSpec{
+				NacosServerRef: v12.NacosServerReference{Name: nnsTestNacosServerName},
+				NamespaceId:    nnsTestNamespaceId,
+			},
+		}
+	}
+	reconcile := func(ns *v12.NacosNamespace) error {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns.Namespace, Name: ns.Name}})
+		return err
+	}
+	deleteAndReconcile := func(ns *v12.NacosNamespace) {
+		gomega.Expect(c.Delete(ctx, ns)).Should(gomega.Succeed())
+		gomega.Expect(reconcile(ns)).Should(gomega.Succeed())
+		err := c.Get(ctx, types.NamespacedName{Namespace: ns.Namespace, Name: ns.Name}, ns)
+		gomega.Expect(errors.IsNotFound(err)).Should(gomega.BeTrue())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(clientgoscheme.AddToScheme(s)).Should(gomega.Succeed())
+		gomega.Expect(v12.AddToScheme(s)).Should(gomega.Succeed())
+		server = fake.NewNamespaceServer(nil, "", "")
+		httpServer = httptest.NewServer(server)
+		c = fakeclient.NewClientBuilder().
+			WithScheme(s).
+			WithStatusSubresource(&v12.NacosNamespace{}).
+			WithObjects(
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: dcTestNacosCredentialName, Namespace: dcTestNamespaceStr},
+					Data:       map[string][]byte{"ak": []byte("ak"), "sk": []byte("sk")},
+				},
+				&v12.NacosServer{
+					ObjectMeta: metav1.ObjectMeta{Name: nnsTestNacosServerName, Namespace: dcTestNamespaceStr},
+					Spec: v12.NacosServerSpec{
+						ServerAddr: pointer.String(strings.TrimPrefix(httpServer.URL, "http://")),
+						AuthRef:    &v1.ObjectReference{Name: dcTestNacosCredentialName, APIVersion: "v1", Kind: "Secret"},
+					},
+				},
+			).
+			Build()
+		r = NewNacosNamespaceReconciler(c, s, nil, httpServer.Client(), nil)
+	})
+
+	AfterEach(func() {
+		httpServer.Close()
+	})
+
+	It("removes finalizer without nacos server when nothing was created", func() {
+		nns := newNacosNamespace("nns-not-created")
+		nns.Spec.NacosServerRef.Name = "missing"
+		gomega.Expect(c.Create(ctx, nns)).Should(gomega.Succeed())
+		gomega.Expect(reconcile(nns)).Should(gomega.Succeed())
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: nns.Namespace, Name: nns.Name}, nns)).Should(gomega.Succeed())
+		gomega.Expect(nns.Finalizers).Should(gomega.ContainElement(NamespaceFinalizerName))
+		gomega.Expect(meta.FindStatusCondition(nns.Status.Conditions, v12.ConditionReady).Reason).Should(gomega.Equal(ReasonInvalidServerRef))
+		gomega.Expect(nns.Status.Created).Should(gomega.BeFalse())
+
+		deleteAndReconcile(nns)
+		gomega.Expect(server.Requests()).Should(gomega.BeEmpty())
+	})
+
+	It("deletes only namespaces created by itself", func() {
+		owner := newNacosNamespace("nns-owner")
+		gomega.Expect(c.Create(ctx, owner)).Should(gomega.Succeed())
+		gomega.Expect(reconcile(owner)).Should(gomega.Succeed())
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: owner.Namespace, Name: owner.Name}, owner)).Should(gomega.Succeed())
+		gomega.Expect(owner.Status.Created).Should(gomega.BeTrue())
+
+		adopter := newNacosNamespace("nns-adopter")
+		gomega.Expect(c.Create(ctx, adopter)).Should(gomega.Succeed())
+		gomega.Expect(reconcile(adopter)).Should(gomega.Succeed())
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: adopter.Namespace, Name: adopter.Name}, adopter)).Should(gomega.Succeed())
+		gomega.Expect(adopter.Status.NamespaceId).Should(gomega.Equal(nnsTestNamespaceId))
+		gomega.Expect(adopter.Status.Created).Should(gomega.BeFalse())
+
+		deleteAndReconcile(adopter)
+		_, ok := server.Namespace(nnsTestNamespaceId)
+		gomega.Expect(ok).Should(gomega.BeTrue())
+
+		deleteAndReconcile(owner)
+		_, ok = server.Namespace(nnsTestNamespaceId)
+		gomega.Expect(ok).Should(gomega.BeFalse())
+	})
+})
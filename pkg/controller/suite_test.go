@@ -19,11 +19,10 @@ package controller
 import (
 	"context"
 	"github.com/nacos-group/nacos-controller/pkg/nacos"
-	"k8s.io/utils/pointer"
-	"os"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	"net/http/httptest"
 	"path/filepath"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -49,6 +48,13 @@ var testEnv *envtest.Environment
 var ctx context.Context
 var cancel context.CancelFunc
 
+// nacosServer is an in-memory nacos server used by controller, so that no real nacos server is required
+var nacosServer *fake.ConfigServer
+
+// namespaceServer is a stand-in of nacos console API served by consoleServer, it counts configs of nacosServer
+var namespaceServer *fake.NamespaceServer
+var consoleServer *httptest.Server
+
 func TestControllers(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -59,12 +65,9 @@ var _ = BeforeSuite(func() {
 	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
 
 	By("bootstrapping test environment")
-	ensureEnvKeyExist("KUBECONFIG")
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
 		ErrorIfCRDPathMissing: true,
-		Config:                config.GetConfigOrDie(),
-		UseExistingCluster:    pointer.Bool(true),
 	}
 
 	var err error
@@ -82,7 +85,17 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
-	err = NewDynamicConfigurationReconciler(k8sManager.GetClient(), k8sManager.GetScheme(), nacos.SyncConfigOptions{}).SetupWithManager(k8sManager)
+	nacosServer = fake.NewConfigServer()
+	err = NewDynamicConfigurationReconciler(k8sManager.GetClient(), k8sManager.GetScheme(), nacos.SyncConfigOptions{
+		EventRecorder:       k8sManager.GetEventRecorderFor("nacos-controller"),
+		ConfigClientFactory: nacosServer.ConfigClientFactory(),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	namespaceServer = fake.NewNamespaceServer(nacosServer, "", "")
+	consoleServer = httptest.NewServer(namespaceServer)
+	err = NewNacosNamespaceReconciler(k8sManager.GetClient(), k8sManager.GetScheme(),
+		k8sManager.GetEventRecorderFor("nacos-controller"), consoleServer.Client(), nil).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
 	//+kubebuilder:scaffold:scheme
@@ -101,10 +114,9 @@ var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	cleanDynamicConfigurationTestResource()
 	cancel()
+	if consoleServer != nil {
+		consoleServer.Close()
+	}
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })
-
-func ensureEnvKeyExist(key string) {
-	Expect(len(os.Getenv(key)) > 0).Should(BeTrue(), "missing env key: "+key)
-}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"time"
+)
+
+const (
+	namespace = "nacos_controller"
+
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+var (
+	// NacosRequestsTotal counts publish, get and delete requests to nacos server
+	NacosRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nacos_requests_total",
+		Help:      "Total number of requests to nacos server by operation, sync direction and result",
+	}, []string{"operation", "direction", "result"})
+
+	// NacosRequestDuration observes latency of publish, get and delete requests to nacos server
+	NacosRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "nacos_request_duration_seconds",
+		Help:      "Latency of requests to nacos server by operation, sync direction and result",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "direction", "result"})
+
+	// Server2ClusterCallbacksTotal counts callbacks of configuration changed in nacos server
+	Server2ClusterCallbacksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "server2cluster_callbacks_total",
+		Help:      "Total number of server2cluster callbacks applied to DynamicConfigurations by result",
+	}, []string{"result"})
+
+	// Server2ClusterCallbackDuration observes latency of applying callbacks to DynamicConfigurations
+	Server2ClusterCallbackDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "server2cluster_callback_duration_seconds",
+		Help:      "Latency of applying server2cluster callbacks to DynamicConfigurations by result",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	// ActiveListeners is the number of configurations listened from nacos server
+	ActiveListeners = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_listeners",
+		Help:      "Number of configurations listened from nacos server",
+	})
+
+	// CachedClients is the number of cached nacos config clients
+	CachedClients = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cached_clients",
+		Help:      "Number of cached nacos config clients",
+	})
+
+	// NotReadyDataIds is the number of not ready dataIds of each DynamicConfiguration
+	NotReadyDataIds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dynamicconfiguration_not_ready_dataids",
+		Help:      "Number of not ready dataIds of DynamicConfiguration",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		NacosRequestsTotal,
+		NacosRequestDuration,
+		Server2ClusterCallbacksTotal,
+		Server2ClusterCallbackDuration,
+		ActiveListeners,
+		CachedClients,
+		NotReadyDataIds,
+	)
+}
+
+// ObserveNacosRequest records a request to nacos server which started at start
+func ObserveNacosRequest(operation, direction string, start time.Time, err error) {
+	result := resultOf(err)
+	NacosRequestsTotal.WithLabelValues(operation, direction, result).Inc()
+	NacosRequestDuration.WithLabelValues(operation, direction, result).Observe(time.Since(start).Seconds())
+}
+
+// ObserveServer2ClusterCallback records a callback applied to a DynamicConfiguration which started at start
+func ObserveServer2ClusterCallback(start time.Time, err error) {
+	result := resultOf(err)
+	Server2ClusterCallbacksTotal.WithLabelValues(result).Inc()
+	Server2ClusterCallbackDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+}
+
+func resultOf(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
@@ -1,87 +1,171 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/metrics"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
-	"strconv"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"path/filepath"
 	"strings"
 	"sync"
 )
 
 type NacosAuthManager struct {
-	cache sync.Map
+	cache   sync.Map
+	factory ConfigClientFactory
 }
 
+// ConfigClientFactory creates a config client by params, it can be replaced by an in-memory fake for testing
+type ConfigClientFactory func(clientParams *ConfigClientParam) (config_client.IConfigClient, error)
+
 type ConfigClientParam struct {
-	Endpoint   string
-	ServerAddr string
-	Namespace  string
-	AuthInfo   ConfigClientAuthInfo
+	Endpoint      string
+	ServerAddr    string
+	Namespace     string
+	AuthInfo      ConfigClientAuthInfo
+	ClientOptions *nacosiov1.NacosClientOptions
+	// Scheme is the scheme of ServerAddr, https is used if empty and TLS is set
+	Scheme string
+	TLS    *ConfigClientTLS
+	// AuthRef is the object which AuthInfo read from, with namespace resolved
+	AuthRef *v1.ObjectReference
 }
 
 type ConfigClientAuthInfo struct {
 	AccessKey string
 	SecretKey string
+	Username  string
+	Password  string
+}
+
+// SecretRefs returns Secrets which credentials and certificates are read from
+func (p *ConfigClientParam) SecretRefs() []types.NamespacedName {
+	var refs []types.NamespacedName
+	if p.AuthRef != nil {
+		refs = append(refs, types.NamespacedName{Namespace: p.AuthRef.Namespace, Name: p.AuthRef.Name})
+	}
+	if p.TLS != nil && p.TLS.SecretRef != nil {
+		refs = append(refs, types.NamespacedName{Namespace: p.TLS.SecretRef.Namespace, Name: p.TLS.SecretRef.Name})
+	}
+	return refs
+}
+
+// Fingerprint return a digest of credentials, so that clients with different credentials are not shared
+func (info ConfigClientAuthInfo) Fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{info.AccessKey, info.SecretKey, info.Username, info.Password}, "\x00")))
+	return hex.EncodeToString(sum[:8])
 }
 
 var manager = NacosAuthManager{
-	cache: sync.Map{},
+	cache:   sync.Map{},
+	factory: NewNacosConfigClient,
 }
 
 func GetNacosAuthManger() *NacosAuthManager {
 	return &manager
 }
 
+// NewNacosAuthManager returns a NacosAuthManager which creates config clients by factory,
+// NewNacosConfigClient is used if factory is nil
+func NewNacosAuthManager(factory ConfigClientFactory) *NacosAuthManager {
+	if factory == nil {
+		factory = NewNacosConfigClient
+	}
+	return &NacosAuthManager{factory: factory}
+}
+
 func (m *NacosAuthManager) GetNacosConfigClient(authProvider NacosAuthProvider, dc *nacosiov1.DynamicConfiguration) (config_client.IConfigClient, error) {
 	if dc == nil {
 		return nil, fmt.Errorf("empty DynamicConfiguration")
 	}
-	nacosServer := dc.Spec.NacosServer
-	// 简化判空逻辑，cacheKey仅内部使用
-	cacheKey := fmt.Sprintf("%s-%s-%s", nacosServer.Endpoint, nacosServer.ServerAddr, nacosServer.Namespace)
+	clientParams, err := authProvider.GetNacosClientParams(dc)
+	if err != nil {
+		return nil, err
+	}
+	return m.GetNacosConfigClientByParams(clientParams)
+}
+
+// GetNacosConfigClientByParams return a cached config client if exist, otherwise create a new one
+func (m *NacosAuthManager) GetNacosConfigClientByParams(clientParams *ConfigClientParam) (config_client.IConfigClient, error) {
+	if clientParams == nil {
+		return nil, fmt.Errorf("empty nacos client params")
+	}
+	cacheKey := getCacheKey(clientParams)
 	cachedClient, ok := m.cache.Load(cacheKey)
 	if ok && cachedClient != nil {
 		return cachedClient.(config_client.IConfigClient), nil
 	}
-	clientParams, err := authProvider.GetNacosClientParams(dc)
+	configClient, err := m.factory(clientParams)
 	if err != nil {
 		return nil, err
 	}
+	m.cache.Store(cacheKey, configClient)
+	m.updateCachedClientsMetric()
+	return configClient, nil
+}
+
+// NewNacosConfigClient creates a config client of nacos server by params
+func NewNacosConfigClient(clientParams *ConfigClientParam) (config_client.IConfigClient, error) {
 	var sc []constant.ServerConfig
+	opts := clientParams.ClientOptions
+	if opts == nil {
+		opts = &nacosiov1.NacosClientOptions{}
+	}
+	timeoutMs := pointer.Int64Deref(opts.TimeoutMs, 0)
+	if timeoutMs <= 0 {
+		timeoutMs = DefaultTimeoutMs
+	}
 	clientOpts := []constant.ClientOption{
 		constant.WithAccessKey(clientParams.AuthInfo.AccessKey),
 		constant.WithSecretKey(clientParams.AuthInfo.SecretKey),
-		constant.WithTimeoutMs(5000),
-		constant.WithNotLoadCacheAtStart(true),
-		constant.WithLogDir("/tmp/nacos/log"),
-		constant.WithCacheDir("/tmp/nacos/cache"),
-		constant.WithLogLevel("debug"),
+		constant.WithUsername(clientParams.AuthInfo.Username),
+		constant.WithPassword(clientParams.AuthInfo.Password),
+		constant.WithTimeoutMs(uint64(timeoutMs)),
+		constant.WithNotLoadCacheAtStart(!pointer.BoolDeref(opts.LoadCacheAtStart, false)),
+		constant.WithDisableUseSnapShot(pointer.BoolDeref(opts.DisableSnapshot, false)),
+		constant.WithLogDir(stringOrDefault(opts.LogDir, DefaultLogDir)),
+		constant.WithCacheDir(stringOrDefault(opts.CacheDir, DefaultCacheDir)),
+		constant.WithLogLevel(stringOrDefault(opts.LogLevel, DefaultLogLevel)),
 		constant.WithNamespaceId(clientParams.Namespace),
 	}
+	if opts.BeatIntervalMs != nil && *opts.BeatIntervalMs > 0 {
+		clientOpts = append(clientOpts, constant.WithBeatInterval(*opts.BeatIntervalMs))
+	}
+	if opts.UpdateThreadNum != nil && *opts.UpdateThreadNum > 0 {
+		clientOpts = append(clientOpts, constant.WithUpdateThreadNum(int(*opts.UpdateThreadNum)))
+	}
+	if clientParams.TLS != nil {
+		// certificates are kept in cache dir, named by fingerprint so that clients with different certificates don't conflict
+		dir := filepath.Join(stringOrDefault(opts.CacheDir, DefaultCacheDir), "tls", clientParams.TLS.Fingerprint())
+		tlsCfg, err := clientParams.TLS.SDKConfig(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls config: %w", err)
+		}
+		clientOpts = append(clientOpts, constant.WithTLS(tlsCfg))
+	}
 	if len(clientParams.Endpoint) > 0 {
 		clientOpts = append(clientOpts, constant.WithEndpoint(clientParams.Endpoint))
 	} else if len(clientParams.ServerAddr) > 0 {
-		port := 8848
-		ip := clientParams.ServerAddr
-		if strings.Contains(ip, ":") {
-			split := strings.Split(ip, ":")
-			ip = split[0]
-			if v, err := strconv.Atoi(split[1]); err != nil {
-				return nil, fmt.Errorf("invalid ServerAddr: %s", clientParams.ServerAddr)
-			} else {
-				port = v
-			}
-
-		}
-		sc = []constant.ServerConfig{
-			*constant.NewServerConfig(ip, uint64(port)),
+		var err error
+		if sc, err = BuildServerConfigs(clientParams); err != nil {
+			return nil, err
 		}
 	}
 	cc := *constant.NewClientConfig(clientOpts...)
+	// ListenInterval has no option function since it is deprecated by nacos 2.x
+	if opts.ListenIntervalMs != nil && *opts.ListenIntervalMs > 0 {
+		cc.ListenInterval = uint64(*opts.ListenIntervalMs)
+	}
+	cc.ContextPath = opts.ContextPath
 	configClient, err := clients.NewConfigClient(
 		vo.NacosClientParam{
 			ClientConfig:  &cc,
@@ -90,6 +174,80 @@ func (m *NacosAuthManager) GetNacosConfigClient(authProvider NacosAuthProvider,
 	if err != nil {
 		return nil, err
 	}
-	m.cache.Store(cacheKey, configClient)
 	return configClient, nil
 }
+
+// BuildServerConfigs returns a server config for every address of ServerAddr, so that nacos sdk can fail over between them.
+// Scheme and context path of an address override the ones of params.
+func BuildServerConfigs(clientParams *ConfigClientParam) ([]constant.ServerConfig, error) {
+	addrs, err := nacosiov1.ParseServerAddrs(clientParams.ServerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ServerAddr %s: %w", clientParams.ServerAddr, err)
+	}
+	scheme := clientParams.Scheme
+	if len(scheme) == 0 && clientParams.TLS != nil {
+		scheme = "https"
+	}
+	var contextPath string
+	if clientParams.ClientOptions != nil {
+		contextPath = clientParams.ClientOptions.ContextPath
+	}
+	var sc []constant.ServerConfig
+	for _, addr := range addrs {
+		var serverOpts []constant.ServerOption
+		if s := stringOrDefault(addr.Scheme, scheme); len(s) > 0 {
+			serverOpts = append(serverOpts, constant.WithScheme(s))
+		}
+		if p := stringOrDefault(addr.ContextPath, contextPath); len(p) > 0 {
+			serverOpts = append(serverOpts, constant.WithContextPath(p))
+		}
+		// nacos sdk joins ip and port without brackets, so IPv6 is kept in brackets
+		ip := addr.Host
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		sc = append(sc, *constant.NewServerConfig(ip, addr.Port, serverOpts...))
+	}
+	return sc, nil
+}
+
+// CloseClient removes config client from cache and closes it, should be called when nobody uses it
+func (m *NacosAuthManager) CloseClient(configClient config_client.IConfigClient) {
+	if configClient == nil {
+		return
+	}
+	m.cache.Range(func(key, value any) bool {
+		if value == configClient {
+			m.cache.Delete(key)
+		}
+		return true
+	})
+	m.updateCachedClientsMetric()
+	configClient.CloseClient()
+}
+
+func (m *NacosAuthManager) updateCachedClientsMetric() {
+	count := 0
+	m.cache.Range(func(key, value any) bool {
+		count++
+		return true
+	})
+	metrics.CachedClients.Set(float64(count))
+}
+
+// getCacheKey identifies a config client by server, namespace, client options, tls and credentials
+func getCacheKey(clientParams *ConfigClientParam) string {
+	// 简化判空逻辑，cacheKey仅内部使用
+	cacheKey := fmt.Sprintf("%s-%s-%s", clientParams.Endpoint, clientParams.ServerAddr, clientParams.Namespace)
+	if opts := clientParams.ClientOptions; opts != nil {
+		b, _ := json.Marshal(opts)
+		cacheKey = cacheKey + "-" + string(b)
+	}
+	if len(clientParams.Scheme) > 0 {
+		cacheKey = cacheKey + "-" + clientParams.Scheme
+	}
+	if clientParams.TLS != nil {
+		cacheKey = cacheKey + "-tls:" + clientParams.TLS.Fingerprint()
+	}
+	return cacheKey + "-" + clientParams.AuthInfo.Fingerprint()
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BuildServerConfigs", func() {
+	DescribeTable("builds a server config for every address",
+		func(params *ConfigClientParam, expected []constant.ServerConfig) {
+			sc, err := BuildServerConfigs(params)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sc).To(HaveLen(len(expected)))
+			for i := range expected {
+				Expect(sc[i].IpAddr).To(Equal(expected[i].IpAddr))
+				Expect(sc[i].Port).To(Equal(expected[i].Port))
+				Expect(sc[i].Scheme).To(Equal(expected[i].Scheme))
+				Expect(sc[i].ContextPath).To(Equal(expected[i].ContextPath))
+			}
+		},
+		Entry("default port, scheme and context path", &ConfigClientParam{ServerAddr: "10.0.0.1"}, []constant.ServerConfig{
+			{IpAddr: "10.0.0.1", Port: 8848, Scheme: "http", ContextPath: "/nacos"},
+		}),
+		Entry("scheme and context path of address override the others", &ConfigClientParam{
+			ServerAddr:    "10.0.0.1,http://[fd00::1]:8849/config",
+			Scheme:        "https",
+			ClientOptions: &nacosiov1.NacosClientOptions{ContextPath: "/nacos2"},
+		}, []constant.ServerConfig{
+			{IpAddr: "10.0.0.1", Port: 8848, Scheme: "https", ContextPath: "/nacos2"},
+			{IpAddr: "[fd00::1]", Port: 8849, Scheme: "http", ContextPath: "/config"},
+		}),
+	)
+
+	It("rejects invalid addresses", func() {
+		_, err := BuildServerConfigs(&ConfigClientParam{ServerAddr: "10.0.0.1,ftp://10.0.0.2"})
+		Expect(err).To(MatchError(ContainSubstring("at index 1")))
+	})
+})
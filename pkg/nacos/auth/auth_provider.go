@@ -5,6 +5,8 @@ import (
 	"fmt"
 	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -13,6 +15,8 @@ import (
 const (
 	secretAuthKeyAccessKey = "ak"
 	secretAuthKeySecretKey = "sk"
+	secretAuthKeyUsername  = "username"
+	secretAuthKeyPassword  = "password"
 )
 
 var (
@@ -25,62 +29,252 @@ type NacosAuthProvider interface {
 
 type DefaultNaocsAuthProvider struct {
 	client.Client
+	// APIReader reads auth and tls Secrets when their resourceVersion in the metadata cache changed, so that Secrets
+	// are not cached by Client. Client is used to read Secrets if it is nil.
+	APIReader client.Reader
+	// DefaultClientOptions are client options set by flags of controller, overridden by nacos server and DynamicConfiguration
+	DefaultClientOptions *nacosiov1.NacosClientOptions
+	secrets              secretReader
 }
 
 func (p *DefaultNaocsAuthProvider) GetNacosClientParams(dc *nacosiov1.DynamicConfiguration) (*ConfigClientParam, error) {
 	if dc == nil {
 		return nil, fmt.Errorf("empty DynamicConfiguration")
 	}
-	serverConf := &dc.Spec.NacosServer
-	authRef := serverConf.AuthRef.DeepCopy()
-	authRef.Namespace = dc.Namespace
+	var serverSpec *nacosiov1.NacosServerSpec
+	if dc.Spec.NacosServerRef != nil {
+		spec, err := p.getNacosServerSpecFromRef(dc.Namespace, dc.Spec.NacosServerRef)
+		if err != nil {
+			return nil, err
+		}
+		if len(dc.Spec.NacosServer.Namespace) > 0 {
+			spec.Namespace = dc.Spec.NacosServer.Namespace
+		}
+		spec.ClientOptions = MergeClientOptions(spec.ClientOptions, dc.Spec.NacosServer.ClientOptions)
+		serverSpec = spec
+	} else {
+		serverConf := &dc.Spec.NacosServer
+		if serverConf.AuthRef == nil {
+			return nil, fmt.Errorf("nacos auth reference should be set")
+		}
+		authRef := serverConf.AuthRef.DeepCopy()
+		authRef.Namespace = dc.Namespace
+		serverSpec = &nacosiov1.NacosServerSpec{
+			Endpoint:      serverConf.Endpoint,
+			ServerAddr:    serverConf.ServerAddr,
+			Namespace:     serverConf.Namespace,
+			AuthRef:       authRef,
+			AuthKeys:      serverConf.AuthKeys,
+			ClientOptions: serverConf.ClientOptions,
+			Scheme:        serverConf.Scheme,
+			TLS:           serverConf.TLS.DeepCopy(),
+		}
+		if serverSpec.TLS != nil && serverSpec.TLS.SecretRef != nil {
+			serverSpec.TLS.SecretRef.Namespace = dc.Namespace
+		}
+	}
+	return p.getClientParams(serverSpec)
+}
 
-	authInfo, err := p.getNacosAuthInfo(authRef)
+// GetNacosServerParams returns params of NacosServer or ClusterNacosServer referenced from namespace
+func (p *DefaultNaocsAuthProvider) GetNacosServerParams(namespace string, ref *nacosiov1.NacosServerReference) (*ConfigClientParam, error) {
+	if ref == nil {
+		return nil, fmt.Errorf("empty nacos server reference")
+	}
+	spec, err := p.getNacosServerSpecFromRef(namespace, ref)
+	if err != nil {
+		return nil, err
+	}
+	return p.getClientParams(spec)
+}
+
+func (p *DefaultNaocsAuthProvider) getClientParams(serverSpec *nacosiov1.NacosServerSpec) (*ConfigClientParam, error) {
+	authInfo, err := p.getNacosAuthInfo(serverSpec.AuthRef, serverSpec.AuthKeys)
+	if err != nil {
+		return nil, err
+	}
+	tlsInfo, err := p.getNacosTLS(serverSpec.TLS)
 	if err != nil {
 		return nil, err
 	}
-	if serverConf.Endpoint != nil {
+	clientOptions := MergeClientOptions(p.DefaultClientOptions, serverSpec.ClientOptions)
+	if serverSpec.Endpoint != nil {
 		return &ConfigClientParam{
-			Endpoint:  *serverConf.Endpoint,
-			Namespace: serverConf.Namespace,
-			AuthInfo:  *authInfo,
+			Endpoint:      *serverSpec.Endpoint,
+			Namespace:     serverSpec.Namespace,
+			AuthInfo:      *authInfo,
+			ClientOptions: clientOptions,
+			Scheme:        serverSpec.Scheme,
+			TLS:           tlsInfo,
+			AuthRef:       serverSpec.AuthRef,
 		}, nil
 	}
-	if serverConf.ServerAddr != nil {
+	if serverSpec.ServerAddr != nil {
 		return &ConfigClientParam{
-			ServerAddr: *serverConf.ServerAddr,
-			Namespace:  serverConf.Namespace,
-			AuthInfo:   *authInfo,
+			ServerAddr:    *serverSpec.ServerAddr,
+			Namespace:     serverSpec.Namespace,
+			AuthInfo:      *authInfo,
+			ClientOptions: clientOptions,
+			Scheme:        serverSpec.Scheme,
+			TLS:           tlsInfo,
+			AuthRef:       serverSpec.AuthRef,
 		}, nil
 	}
 	return nil, fmt.Errorf("either endpoint or serverAddr should be set")
 }
 
-func (p *DefaultNaocsAuthProvider) getNacosAuthInfo(obj *v1.ObjectReference) (*ConfigClientAuthInfo, error) {
+// getNacosTLS reads the CA bundle and client certificate from the tls Secret, nil is returned if tls is not set
+func (p *DefaultNaocsAuthProvider) getNacosTLS(spec *nacosiov1.NacosServerTLS) (*ConfigClientTLS, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	info := ConfigClientTLS{
+		ServerName:         spec.ServerName,
+		InsecureSkipVerify: spec.InsecureSkipVerify,
+	}
+	if spec.SecretRef != nil {
+		s, err := p.getSecret(spec.SecretRef.Namespace, spec.SecretRef.Name)
+		if err != nil {
+			return nil, err
+		}
+		info.SecretRef = spec.SecretRef
+		info.CACert = s.Data[secretTLSKeyCA]
+		info.Cert = s.Data[secretTLSKeyCert]
+		info.Key = s.Data[secretTLSKeyKey]
+		if len(info.Cert) > 0 && len(info.Key) == 0 {
+			return nil, fmt.Errorf("empty field %s in secret %s", secretTLSKeyKey, spec.SecretRef.Name)
+		}
+		if len(info.Key) > 0 && len(info.Cert) == 0 {
+			return nil, fmt.Errorf("empty field %s in secret %s", secretTLSKeyCert, spec.SecretRef.Name)
+		}
+	}
+	if len(info.CACert) == 0 && !info.InsecureSkipVerify {
+		return nil, fmt.Errorf("%s should be provided by tls secret unless insecureSkipVerify is set", secretTLSKeyCA)
+	}
+	return &info, nil
+}
+
+// getNacosServerSpecFromRef reads NacosServer or ClusterNacosServer referenced from namespace
+func (p *DefaultNaocsAuthProvider) getNacosServerSpecFromRef(namespace string, ref *nacosiov1.NacosServerReference) (*nacosiov1.NacosServerSpec, error) {
+	var spec *nacosiov1.NacosServerSpec
+	switch ref.Kind {
+	case "", nacosiov1.NacosServerKind:
+		server := nacosiov1.NacosServer{}
+		if err := p.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ref.Name}, &server); err != nil {
+			return nil, err
+		}
+		spec = server.Spec.DeepCopy()
+		if spec.AuthRef != nil {
+			spec.AuthRef.Namespace = namespace
+		}
+		if spec.TLS != nil && spec.TLS.SecretRef != nil {
+			spec.TLS.SecretRef.Namespace = namespace
+		}
+	case nacosiov1.ClusterNacosServerKind:
+		server := nacosiov1.ClusterNacosServer{}
+		if err := p.Get(context.TODO(), types.NamespacedName{Name: ref.Name}, &server); err != nil {
+			return nil, err
+		}
+		allowed, err := p.isNamespaceAllowed(server.Spec.AllowedNamespaces, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("namespace %s is not allowed to use ClusterNacosServer %s", namespace, ref.Name)
+		}
+		spec = server.Spec.NacosServerSpec.DeepCopy()
+		if spec.AuthRef != nil && len(spec.AuthRef.Namespace) == 0 {
+			return nil, fmt.Errorf("namespace of authRef should be set in ClusterNacosServer %s", ref.Name)
+		}
+		if spec.TLS != nil && spec.TLS.SecretRef != nil && len(spec.TLS.SecretRef.Namespace) == 0 {
+			return nil, fmt.Errorf("namespace of tls.secretRef should be set in ClusterNacosServer %s", ref.Name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported nacos server reference kind: %s", ref.Kind)
+	}
+	if spec.AuthRef == nil {
+		return nil, fmt.Errorf("nacos auth reference should be set in %s %s", ref.Kind, ref.Name)
+	}
+	return spec, nil
+}
+
+func (p *DefaultNaocsAuthProvider) isNamespaceAllowed(allowed *nacosiov1.AllowedNamespaces, namespace string) (bool, error) {
+	if allowed == nil || (len(allowed.Names) == 0 && allowed.Selector == nil) {
+		return true, nil
+	}
+	for _, v := range allowed.Names {
+		if v == namespace {
+			return true, nil
+		}
+	}
+	if allowed.Selector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(allowed.Selector)
+	if err != nil {
+		return false, err
+	}
+	ns := v1.Namespace{}
+	if err := p.Get(context.TODO(), types.NamespacedName{Name: namespace}, &ns); err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+func (p *DefaultNaocsAuthProvider) getNacosAuthInfo(obj *v1.ObjectReference, keys *nacosiov1.NacosAuthKeys) (*ConfigClientAuthInfo, error) {
 	switch obj.GroupVersionKind().String() {
 	case secretGVK.String():
-		return p.getNaocsAuthFromSecret(obj)
+		return p.getNaocsAuthFromSecret(obj, keys)
 	default:
 		return nil, fmt.Errorf("unsupported nacos auth reference type: %s", obj.GroupVersionKind().String())
 	}
 }
 
-func (p *DefaultNaocsAuthProvider) getNaocsAuthFromSecret(obj *v1.ObjectReference) (*ConfigClientAuthInfo, error) {
-	s := v1.Secret{}
-	err := p.Get(context.TODO(), types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}, &s)
+func (p *DefaultNaocsAuthProvider) getSecret(namespace, name string) (*v1.Secret, error) {
+	return p.secrets.get(context.TODO(), p.Client, p.APIReader, types.NamespacedName{Namespace: namespace, Name: name})
+}
+
+// getNaocsAuthFromSecret reads ak/sk or username/password from secret, at least one pair of them should be provided
+func (p *DefaultNaocsAuthProvider) getNaocsAuthFromSecret(obj *v1.ObjectReference, keys *nacosiov1.NacosAuthKeys) (*ConfigClientAuthInfo, error) {
+	s, err := p.getSecret(obj.Namespace, obj.Name)
 	if err != nil {
 		return nil, err
 	}
-	info := ConfigClientAuthInfo{}
-	if v, ok := s.Data[secretAuthKeyAccessKey]; ok && len(v) > 0 {
-		info.AccessKey = string(v)
-	} else {
-		return nil, fmt.Errorf("empty field %s in secret %s", secretAuthKeyAccessKey, obj.Name)
+	akKey, skKey := secretAuthKeyAccessKey, secretAuthKeySecretKey
+	usernameKey, passwordKey := secretAuthKeyUsername, secretAuthKeyPassword
+	if keys != nil {
+		akKey = stringOrDefault(keys.AccessKey, akKey)
+		skKey = stringOrDefault(keys.SecretKey, skKey)
+		usernameKey = stringOrDefault(keys.Username, usernameKey)
+		passwordKey = stringOrDefault(keys.Password, passwordKey)
 	}
-	if v, ok := s.Data[secretAuthKeySecretKey]; ok && len(v) > 0 {
-		info.SecretKey = string(v)
-	} else {
-		return nil, fmt.Errorf("empty field %s in secret %s", secretAuthKeySecretKey, obj.Name)
+	info := ConfigClientAuthInfo{
+		AccessKey: string(s.Data[akKey]),
+		SecretKey: string(s.Data[skKey]),
+		Username:  string(s.Data[usernameKey]),
+		Password:  string(s.Data[passwordKey]),
+	}
+	if len(info.AccessKey) > 0 && len(info.SecretKey) == 0 {
+		return nil, fmt.Errorf("empty field %s in secret %s", skKey, obj.Name)
+	}
+	if len(info.SecretKey) > 0 && len(info.AccessKey) == 0 {
+		return nil, fmt.Errorf("empty field %s in secret %s", akKey, obj.Name)
+	}
+	if len(info.Username) > 0 && len(info.Password) == 0 {
+		return nil, fmt.Errorf("empty field %s in secret %s", passwordKey, obj.Name)
+	}
+	if len(info.Password) > 0 && len(info.Username) == 0 {
+		return nil, fmt.Errorf("empty field %s in secret %s", usernameKey, obj.Name)
+	}
+	if len(info.AccessKey) == 0 && len(info.Username) == 0 {
+		return nil, fmt.Errorf("neither %s/%s nor %s/%s found in secret %s", akKey, skKey, usernameKey, passwordKey, obj.Name)
 	}
 	return &info, nil
 }
+
+func stringOrDefault(s, defaultValue string) string {
+	if len(s) == 0 {
+		return defaultValue
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("DefaultNaocsAuthProvider", func() {
+	var ctx context.Context
+	var k8sClient client.Client
+	var secret *v1.Secret
+	newDC := func() *nacosiov1.DynamicConfiguration {
+		return &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "dc", Namespace: testNamespace},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String("127.0.0.1:8848"),
+					AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "nacos-auth", Namespace: testNamespace},
+			Data:       map[string][]byte{"username": []byte("nacos"), "password": []byte("nacos")},
+		}
+		k8sClient = newTestClient(secret)
+	})
+
+	It("reads Secrets by APIReader only when they changed", func() {
+		apiReader := &countingReader{Reader: k8sClient}
+		p := &DefaultNaocsAuthProvider{Client: k8sClient, APIReader: apiReader}
+		for i := 0; i < 2; i++ {
+			params, err := p.GetNacosClientParams(newDC())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(params.AuthInfo.Password).To(Equal("nacos"))
+		}
+		Expect(apiReader.gets).To(Equal(1))
+
+		secret.Data["password"] = []byte("rotated")
+		Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+		params, err := p.GetNacosClientParams(newDC())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(params.AuthInfo.Password).To(Equal("rotated"))
+		Expect(apiReader.gets).To(Equal(2))
+
+		Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+		_, err = p.GetNacosClientParams(newDC())
+		Expect(err).To(HaveOccurred())
+		Expect(p.secrets.secrets).To(BeEmpty())
+	})
+
+	It("reads Secrets by Client without APIReader", func() {
+		p := &DefaultNaocsAuthProvider{Client: k8sClient}
+		params, err := p.GetNacosClientParams(newDC())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(params.AuthInfo.Username).To(Equal("nacos"))
+		Expect(p.secrets.secrets).To(BeEmpty())
+	})
+})
+
+// countingReader counts reads of Secrets
+type countingReader struct {
+	client.Reader
+	gets int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.gets++
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
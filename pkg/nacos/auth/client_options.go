@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"flag"
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"k8s.io/utils/pointer"
+)
+
+// Built-in client options, used if neither flags nor nacos server specify them
+const (
+	DefaultTimeoutMs = 5000
+	DefaultLogLevel  = "info"
+	DefaultLogDir    = "/tmp/nacos/log"
+	DefaultCacheDir  = "/tmp/nacos/cache"
+)
+
+// MergeClientOptions returns a copy of base, overridden by fields set in override
+func MergeClientOptions(base, override *nacosiov1.NacosClientOptions) *nacosiov1.NacosClientOptions {
+	if base == nil {
+		return override.DeepCopy()
+	}
+	merged := base.DeepCopy()
+	if override == nil {
+		return merged
+	}
+	if override.TimeoutMs != nil {
+		merged.TimeoutMs = pointer.Int64(*override.TimeoutMs)
+	}
+	if len(override.LogLevel) > 0 {
+		merged.LogLevel = override.LogLevel
+	}
+	if len(override.LogDir) > 0 {
+		merged.LogDir = override.LogDir
+	}
+	if len(override.CacheDir) > 0 {
+		merged.CacheDir = override.CacheDir
+	}
+	if override.LoadCacheAtStart != nil {
+		merged.LoadCacheAtStart = pointer.Bool(*override.LoadCacheAtStart)
+	}
+	if override.DisableSnapshot != nil {
+		merged.DisableSnapshot = pointer.Bool(*override.DisableSnapshot)
+	}
+	if override.BeatIntervalMs != nil {
+		merged.BeatIntervalMs = pointer.Int64(*override.BeatIntervalMs)
+	}
+	if override.ListenIntervalMs != nil {
+		merged.ListenIntervalMs = pointer.Int64(*override.ListenIntervalMs)
+	}
+	if override.UpdateThreadNum != nil {
+		merged.UpdateThreadNum = pointer.Int32(*override.UpdateThreadNum)
+	}
+	if len(override.ContextPath) > 0 {
+		merged.ContextPath = override.ContextPath
+	}
+	return merged
+}
+
+// ClientOptionsFlags holds default client options set by flags of controller
+type ClientOptionsFlags struct {
+	fs               *flag.FlagSet
+	timeoutMs        int64
+	logLevel         string
+	logDir           string
+	cacheDir         string
+	loadCacheAtStart bool
+	disableSnapshot  bool
+	beatIntervalMs   int64
+	listenIntervalMs int64
+	updateThreadNum  int
+	contextPath      string
+}
+
+// BindFlags binds flags of default client options to fs
+func (f *ClientOptionsFlags) BindFlags(fs *flag.FlagSet) {
+	f.fs = fs
+	fs.Int64Var(&f.timeoutMs, "nacos-client-timeout-ms", DefaultTimeoutMs, "Timeout of requests to nacos server in milliseconds.")
+	fs.StringVar(&f.logLevel, "nacos-client-log-level", DefaultLogLevel, "Log level of nacos client, one of debug, info, warn and error.")
+	fs.StringVar(&f.logDir, "nacos-client-log-dir", DefaultLogDir, "Directory of nacos client logs.")
+	fs.StringVar(&f.cacheDir, "nacos-client-cache-dir", DefaultCacheDir, "Directory where nacos client caches configs.")
+	fs.BoolVar(&f.loadCacheAtStart, "nacos-client-load-cache-at-start", false, "Load configs cached in cache dir when nacos client is created.")
+	fs.BoolVar(&f.disableSnapshot, "nacos-client-disable-snapshot", false, "Don't read configs cached in cache dir when requests to nacos server failed.")
+	fs.Int64Var(&f.beatIntervalMs, "nacos-client-beat-interval-ms", 0, "Interval of heartbeats to nacos server in milliseconds. Zero means default of nacos sdk.")
+	fs.Int64Var(&f.listenIntervalMs, "nacos-client-listen-interval-ms", 0, "Interval of long polling for listened configs in milliseconds, only used by nacos 1.x. Zero means default of nacos sdk.")
+	fs.IntVar(&f.updateThreadNum, "nacos-client-update-thread-num", 0, "Number of goroutines which update cached configs. Zero means default of nacos sdk.")
+	fs.StringVar(&f.contextPath, "nacos-client-context-path", "", "Context path of nacos server. Empty means /nacos.")
+}
+
+// ClientOptions returns client options set by flags, flags not set are left empty so that built-in defaults are used
+func (f *ClientOptionsFlags) ClientOptions() *nacosiov1.NacosClientOptions {
+	opts := &nacosiov1.NacosClientOptions{}
+	if f.fs == nil {
+		return opts
+	}
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "nacos-client-timeout-ms":
+			opts.TimeoutMs = pointer.Int64(f.timeoutMs)
+		case "nacos-client-log-level":
+			opts.LogLevel = f.logLevel
+		case "nacos-client-log-dir":
+			opts.LogDir = f.logDir
+		case "nacos-client-cache-dir":
+			opts.CacheDir = f.cacheDir
+		case "nacos-client-load-cache-at-start":
+			opts.LoadCacheAtStart = pointer.Bool(f.loadCacheAtStart)
+		case "nacos-client-disable-snapshot":
+			opts.DisableSnapshot = pointer.Bool(f.disableSnapshot)
+		case "nacos-client-beat-interval-ms":
+			if f.beatIntervalMs > 0 {
+				opts.BeatIntervalMs = pointer.Int64(f.beatIntervalMs)
+			}
+		case "nacos-client-listen-interval-ms":
+			if f.listenIntervalMs > 0 {
+				opts.ListenIntervalMs = pointer.Int64(f.listenIntervalMs)
+			}
+		case "nacos-client-update-thread-num":
+			if f.updateThreadNum > 0 {
+				opts.UpdateThreadNum = pointer.Int32(int32(f.updateThreadNum))
+			}
+		case "nacos-client-context-path":
+			opts.ContextPath = f.contextPath
+		}
+	})
+	return opts
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"flag"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("client options", func() {
+	DescribeTable("merges options of override into a copy of base",
+		func(base, override, expected *nacosiov1.NacosClientOptions) {
+			var baseCopy *nacosiov1.NacosClientOptions
+			if base != nil {
+				baseCopy = base.DeepCopy()
+			}
+			Expect(MergeClientOptions(base, override)).To(Equal(expected))
+			Expect(base).To(Equal(baseCopy))
+		},
+		Entry("both empty", nil, nil, nil),
+		Entry("base only", &nacosiov1.NacosClientOptions{LogLevel: "warn"}, nil,
+			&nacosiov1.NacosClientOptions{LogLevel: "warn"}),
+		Entry("override only", nil, &nacosiov1.NacosClientOptions{TimeoutMs: pointer.Int64(1000)},
+			&nacosiov1.NacosClientOptions{TimeoutMs: pointer.Int64(1000)}),
+		Entry("fields set in override",
+			&nacosiov1.NacosClientOptions{TimeoutMs: pointer.Int64(3000), LogLevel: "warn", LogDir: "/var/log/nacos"},
+			&nacosiov1.NacosClientOptions{TimeoutMs: pointer.Int64(10000), DisableSnapshot: pointer.Bool(false), ContextPath: "/config"},
+			&nacosiov1.NacosClientOptions{TimeoutMs: pointer.Int64(10000), LogLevel: "warn", LogDir: "/var/log/nacos",
+				DisableSnapshot: pointer.Bool(false), ContextPath: "/config"}),
+	)
+
+	DescribeTable("returns options of flags set only",
+		func(args []string, expected *nacosiov1.NacosClientOptions) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			f := ClientOptionsFlags{}
+			f.BindFlags(fs)
+			Expect(fs.Parse(args)).To(Succeed())
+			Expect(f.ClientOptions()).To(Equal(expected))
+		},
+		Entry("no flags", nil, &nacosiov1.NacosClientOptions{}),
+		Entry("flags with default values", []string{"--nacos-client-log-level=info", "--nacos-client-load-cache-at-start=false"},
+			&nacosiov1.NacosClientOptions{LogLevel: "info", LoadCacheAtStart: pointer.Bool(false)}),
+		Entry("zero intervals mean default of nacos sdk", []string{"--nacos-client-beat-interval-ms=0", "--nacos-client-update-thread-num=0"},
+			&nacosiov1.NacosClientOptions{}),
+		Entry("all flags", []string{
+			"--nacos-client-timeout-ms=1000", "--nacos-client-log-dir=/logs", "--nacos-client-cache-dir=/cache",
+			"--nacos-client-disable-snapshot", "--nacos-client-listen-interval-ms=30000",
+			"--nacos-client-update-thread-num=4", "--nacos-client-context-path=/config",
+		}, &nacosiov1.NacosClientOptions{
+			TimeoutMs: pointer.Int64(1000), LogDir: "/logs", CacheDir: "/cache", DisableSnapshot: pointer.Bool(true),
+			ListenIntervalMs: pointer.Int64(30000), UpdateThreadNum: pointer.Int32(4), ContextPath: "/config",
+		}),
+	)
+
+	DescribeTable("overrides options of flags by NacosServer and DynamicConfiguration",
+		func(serverRef bool, expected *nacosiov1.NacosClientOptions) {
+			p := &DefaultNaocsAuthProvider{
+				Client: newTestClient(&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "nacos-auth", Namespace: testNamespace},
+					Data:       map[string][]byte{"ak": []byte("ak"), "sk": []byte("sk")},
+				}, &nacosiov1.NacosServer{
+					ObjectMeta: metav1.ObjectMeta{Name: "nacos", Namespace: testNamespace},
+					Spec: nacosiov1.NacosServerSpec{
+						ServerAddr: pointer.String("127.0.0.1:8848"),
+						AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+						ClientOptions: &nacosiov1.NacosClientOptions{
+							TimeoutMs:   pointer.Int64(10000),
+							ContextPath: "/config",
+						},
+					},
+				}),
+				DefaultClientOptions: &nacosiov1.NacosClientOptions{
+					TimeoutMs: pointer.Int64(3000),
+					LogLevel:  "warn",
+					LogDir:    "/var/log/nacos",
+				},
+			}
+			dc := &nacosiov1.DynamicConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "dc", Namespace: testNamespace},
+				Spec: nacosiov1.DynamicConfigurationSpec{
+					NacosServer: nacosiov1.NacosServerConfiguration{
+						ServerAddr:    pointer.String("127.0.0.1:8848"),
+						AuthRef:       &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+						ClientOptions: &nacosiov1.NacosClientOptions{LogLevel: "error"},
+					},
+				},
+			}
+			if serverRef {
+				dc.Spec.NacosServerRef = &nacosiov1.NacosServerReference{Name: "nacos"}
+			}
+			params, err := p.GetNacosClientParams(dc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(params.ClientOptions).To(Equal(expected))
+		},
+		Entry("inline nacos server", false, &nacosiov1.NacosClientOptions{
+			TimeoutMs: pointer.Int64(3000),
+			LogLevel:  "error",
+			LogDir:    "/var/log/nacos",
+		}),
+		Entry("referenced NacosServer", true, &nacosiov1.NacosClientOptions{
+			TimeoutMs:   pointer.Int64(10000),
+			LogLevel:    "error",
+			LogDir:      "/var/log/nacos",
+			ContextPath: "/config",
+		}),
+	)
+})
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+)
+
+// secretReader reads auth and tls Secrets without caching all Secrets of cluster. Metadata of Secrets is read from the
+// metadata cache of controller, and the Secret is read again by the uncached reader only when its resourceVersion
+// changed. Only Secrets referenced by nacos servers are kept.
+type secretReader struct {
+	lock    sync.Mutex
+	secrets map[types.NamespacedName]*v1.Secret
+}
+
+func (r *secretReader) get(ctx context.Context, c client.Reader, apiReader client.Reader, nn types.NamespacedName) (*v1.Secret, error) {
+	if apiReader == nil {
+		s := &v1.Secret{}
+		if err := c.Get(ctx, nn, s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	meta := &metav1.PartialObjectMetadata{}
+	meta.SetGroupVersionKind(secretGVK)
+	if err := c.Get(ctx, nn, meta); err != nil {
+		if errors.IsNotFound(err) {
+			r.forget(nn)
+		}
+		return nil, err
+	}
+	r.lock.Lock()
+	s, ok := r.secrets[nn]
+	r.lock.Unlock()
+	if ok && s.ResourceVersion == meta.ResourceVersion {
+		return s, nil
+	}
+	s = &v1.Secret{}
+	if err := apiReader.Get(ctx, nn, s); err != nil {
+		return nil, err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.secrets == nil {
+		r.secrets = map[types.NamespacedName]*v1.Secret{}
+	}
+	r.secrets[nn] = s
+	return s, nil
+}
+
+func (r *secretReader) forget(nn types.NamespacedName) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.secrets, nn)
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"testing"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "default"
+
+func TestAuth(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Nacos Auth Suite")
+}
+
+// newTestClient returns a fake client with objs, which knows types of client-go and nacos.io
+func newTestClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(nacosiov1.AddToScheme(scheme)).To(Succeed())
+	return fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
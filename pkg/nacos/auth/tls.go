@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	v1 "k8s.io/api/core/v1"
+	"os"
+	"path/filepath"
+)
+
+const (
+	secretTLSKeyCA   = "ca.crt"
+	secretTLSKeyCert = v1.TLSCertKey
+	secretTLSKeyKey  = v1.TLSPrivateKeyKey
+)
+
+// ConfigClientTLS holds the CA bundle and client certificate used to connect nacos server
+type ConfigClientTLS struct {
+	CACert             []byte
+	Cert               []byte
+	Key                []byte
+	ServerName         string
+	InsecureSkipVerify bool
+	// SecretRef is the Secret which certificates are read from, with namespace resolved
+	SecretRef *v1.SecretReference
+}
+
+// Fingerprint return a digest of certificates and settings, so that clients with different certificates are not shared
+func (t *ConfigClientTLS) Fingerprint() string {
+	h := sha256.New()
+	for _, b := range [][]byte{t.CACert, t.Cert, t.Key, []byte(t.ServerName), []byte(fmt.Sprint(t.InsecureSkipVerify))} {
+		h.Write(b)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// Config returns the tls config of http clients which connect nacos server
+func (t *ConfigClientTLS) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if len(t.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(t.CACert) {
+			return nil, fmt.Errorf("no valid certificate found in %s", secretTLSKeyCA)
+		}
+		cfg.RootCAs = pool
+	} else if !t.InsecureSkipVerify {
+		return nil, fmt.Errorf("%s is required unless insecureSkipVerify is set", secretTLSKeyCA)
+	}
+	if len(t.Cert) > 0 || len(t.Key) > 0 {
+		cert, err := tls.X509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// SDKConfig writes certificates into dir and returns the tls config of nacos sdk, since nacos sdk reads them from files.
+// It is used by both http and grpc connections, and environment variables of nacos sdk are ignored.
+func (t *ConfigClientTLS) SDKConfig(dir string) (constant.TLSConfig, error) {
+	if _, err := t.Config(); err != nil {
+		return constant.TLSConfig{}, err
+	}
+	cfg := constant.TLSConfig{
+		Appointed:          true,
+		Enable:             true,
+		TrustAll:           t.InsecureSkipVerify,
+		ServerNameOverride: t.ServerName,
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return cfg, err
+	}
+	files := []struct {
+		name    string
+		content []byte
+		path    *string
+	}{
+		{secretTLSKeyCA, t.CACert, &cfg.CaFile},
+		{secretTLSKeyCert, t.Cert, &cfg.CertFile},
+		{secretTLSKeyKey, t.Key, &cfg.KeyFile},
+	}
+	for _, f := range files {
+		if len(f.content) == 0 {
+			continue
+		}
+		*f.path = filepath.Join(dir, f.name)
+		if err := writeFileAtomic(*f.path, f.content); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+// writeFileAtomic replaces file by renaming, since nacos sdk exits if it reads a partially written certificate
+// when reconnecting
+func writeFileAtomic(path string, content []byte) error {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,152 @@
+package auth_test
+
+import (
+	"os"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("tls", func() {
+	const namespace = "default"
+	var certs *fake.Certificates
+	newProvider := func(secrets ...*v1.Secret) *auth.DefaultNaocsAuthProvider {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		builder := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "nacos-login", Namespace: namespace},
+			Data:       map[string][]byte{"username": []byte("nacos"), "password": []byte("nacos")},
+		})
+		for _, s := range secrets {
+			builder = builder.WithObjects(s)
+		}
+		return &auth.DefaultNaocsAuthProvider{Client: builder.Build()}
+	}
+	newDC := func(serverAddr string, tls *nacosiov1.NacosServerTLS) *nacosiov1.DynamicConfiguration {
+		return &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "dc", Namespace: namespace},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String(serverAddr),
+					AuthRef:    &v1.ObjectReference{Name: "nacos-login", APIVersion: "v1", Kind: "Secret"},
+					TLS:        tls,
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		certs, err = fake.NewCertificates("nacos-controller", "127.0.0.1")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("builds tls config from certificates",
+		func(build func() *auth.ConfigClientTLS, errMessage string) {
+			_, err := build().Config()
+			if len(errMessage) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(errMessage)))
+			}
+		},
+		Entry("CA bundle and client certificate", func() *auth.ConfigClientTLS {
+			return &auth.ConfigClientTLS{CACert: certs.CA, Cert: certs.ClientCert, Key: certs.ClientKey}
+		}, ""),
+		Entry("insecureSkipVerify without CA bundle", func() *auth.ConfigClientTLS {
+			return &auth.ConfigClientTLS{InsecureSkipVerify: true}
+		}, ""),
+		Entry("missing CA bundle", func() *auth.ConfigClientTLS {
+			return &auth.ConfigClientTLS{}
+		}, "ca.crt is required"),
+		Entry("invalid CA bundle", func() *auth.ConfigClientTLS {
+			return &auth.ConfigClientTLS{CACert: []byte("invalid")}
+		}, "no valid certificate"),
+		Entry("client certificate without key", func() *auth.ConfigClientTLS {
+			return &auth.ConfigClientTLS{CACert: certs.CA, Cert: certs.ClientCert}
+		}, "invalid client certificate"),
+	)
+
+	DescribeTable("reads certificates from tls Secret",
+		func(data map[string][]byte, insecureSkipVerify bool, errMessage string) {
+			p := newProvider(&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "nacos-tls", Namespace: namespace}, Data: data})
+			params, err := p.GetNacosClientParams(newDC("127.0.0.1:8848", &nacosiov1.NacosServerTLS{
+				SecretRef:          &v1.SecretReference{Name: "nacos-tls"},
+				InsecureSkipVerify: insecureSkipVerify,
+			}))
+			if len(errMessage) > 0 {
+				Expect(err).To(MatchError(ContainSubstring(errMessage)))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(params.TLS.InsecureSkipVerify).To(Equal(insecureSkipVerify))
+			Expect(params.SecretRefs()).To(ConsistOf(
+				types.NamespacedName{Namespace: namespace, Name: "nacos-login"},
+				types.NamespacedName{Namespace: namespace, Name: "nacos-tls"},
+			))
+		},
+		Entry("CA bundle", map[string][]byte{"ca.crt": []byte("ca")}, false, ""),
+		Entry("missing CA bundle", map[string][]byte{}, false, "ca.crt"),
+		Entry("insecureSkipVerify without CA bundle", map[string][]byte{}, true, ""),
+		Entry("client certificate without key", map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")}, false, "tls.key"),
+		Entry("client key without certificate", map[string][]byte{"ca.crt": []byte("ca"), "tls.key": []byte("key")}, false, "tls.crt"),
+	)
+
+	It("writes certificates into files for nacos sdk", func() {
+		dir := GinkgoT().TempDir()
+		t := &auth.ConfigClientTLS{CACert: certs.CA, Cert: certs.ClientCert, Key: certs.ClientKey, ServerName: "nacos"}
+		cfg, err := t.SDKConfig(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Enable).To(BeTrue())
+		Expect(cfg.ServerNameOverride).To(Equal("nacos"))
+		for path, content := range map[string][]byte{cfg.CaFile: certs.CA, cfg.CertFile: certs.ClientCert, cfg.KeyFile: certs.ClientKey} {
+			Expect(os.ReadFile(path)).To(Equal(content))
+		}
+		Expect(t.Fingerprint()).NotTo(Equal((&auth.ConfigClientTLS{CACert: certs.CA}).Fingerprint()))
+	})
+
+	It("connects nacos server by https and grpc with certificates of tls Secret", func() {
+		tlsServer, err := fake.NewTLSServer(certs)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(tlsServer.Close)
+		p := newProvider(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "nacos-tls", Namespace: namespace},
+			Data: map[string][]byte{
+				"ca.crt":  certs.CA,
+				"tls.crt": certs.ClientCert,
+				"tls.key": certs.ClientKey,
+			},
+		})
+		dc := newDC(tlsServer.Addr(), &nacosiov1.NacosServerTLS{SecretRef: &v1.SecretReference{Name: "nacos-tls"}})
+		dir := GinkgoT().TempDir()
+		dc.Spec.NacosServer.ClientOptions = &nacosiov1.NacosClientOptions{
+			TimeoutMs: pointer.Int64(1000),
+			LogDir:    dir,
+			CacheDir:  dir,
+		}
+
+		params, err := p.GetNacosClientParams(dc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(params.TLS.CACert).To(Equal(certs.CA))
+		configClient, err := auth.NewNacosConfigClient(params)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(configClient.CloseClient)
+		// login is requested by https when client is created
+		Expect(tlsServer.Requests()).To(ContainElement("POST /nacos/v1/auth/users/login"))
+		// grpc server is not served, so getting config fails after grpc connection is set up by TLS
+		_, err = configClient.GetConfig(vo.ConfigParam{DataId: "app.yaml", Group: "DEFAULT_GROUP"})
+		Expect(err).To(HaveOccurred())
+		Expect(tlsServer.GrpcHandshakes()).To(ContainElement("nacos-controller"))
+	})
+})
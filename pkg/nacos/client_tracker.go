@@ -0,0 +1,102 @@
+package nacos
+
+import (
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"k8s.io/apimachinery/pkg/types"
+	"sync"
+)
+
+// ClientTracker records which nacos config client and Secrets of credentials and certificates each DynamicConfiguration
+// is using, so that listeners can be moved to a new client when they are rotated.
+// Clients of spec.targets are tracked by target name, the one of spec.nacosServer has an empty target name.
+type ClientTracker struct {
+	clients map[trackerKey]config_client.IConfigClient
+	secrets map[trackerKey][]types.NamespacedName
+	lock    sync.RWMutex
+}
+
+type trackerKey struct {
+	dc     types.NamespacedName
+	target string
+}
+
+func NewClientTracker() *ClientTracker {
+	return &ClientTracker{
+		clients: map[trackerKey]config_client.IConfigClient{},
+		secrets: map[trackerKey][]types.NamespacedName{},
+		lock:    sync.RWMutex{},
+	}
+}
+
+// Track records client and Secrets of dc, return the previous client if it is a different one
+func (t *ClientTracker) Track(dc types.NamespacedName, c config_client.IConfigClient, secrets []types.NamespacedName) config_client.IConfigClient {
+	return t.TrackTarget(dc, "", c, secrets)
+}
+
+// TrackTarget records client and Secrets of a target of dc, return the previous client if it is a different one
+func (t *ClientTracker) TrackTarget(dc types.NamespacedName, target string, c config_client.IConfigClient, secrets []types.NamespacedName) config_client.IConfigClient {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	key := trackerKey{dc: dc, target: target}
+	if len(secrets) > 0 {
+		t.secrets[key] = secrets
+	} else {
+		delete(t.secrets, key)
+	}
+	old, ok := t.clients[key]
+	t.clients[key] = c
+	if ok && old != c {
+		return old
+	}
+	return nil
+}
+
+// Untrack forgets dc and all its targets, return the clients they were using
+func (t *ClientTracker) Untrack(dc types.NamespacedName) []config_client.IConfigClient {
+	return t.RetainTargets(dc, nil, false)
+}
+
+// RetainTargets forgets targets of dc which are not in targets, and the client of spec.nacosServer if keepServer is false.
+// It returns the clients they were using.
+func (t *ClientTracker) RetainTargets(dc types.NamespacedName, targets []string, keepServer bool) []config_client.IConfigClient {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var removed []config_client.IConfigClient
+	for key, c := range t.clients {
+		if key.dc != dc || StringSliceContains(targets, key.target) || (keepServer && len(key.target) == 0) {
+			continue
+		}
+		delete(t.clients, key)
+		delete(t.secrets, key)
+		removed = append(removed, c)
+	}
+	return removed
+}
+
+// InUse return true if any DynamicConfiguration is using the client
+func (t *ClientTracker) InUse(c config_client.IConfigClient) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	for _, v := range t.clients {
+		if v == c {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDCListBySecret return DynamicConfigurations whose credentials or certificates are read from the Secret
+func (t *ClientTracker) GetDCListBySecret(secret types.NamespacedName) []types.NamespacedName {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	var dcList []types.NamespacedName
+	for key, secrets := range t.secrets {
+		for _, s := range secrets {
+			if s == secret {
+				dcList = appendDC(dcList, key.dc)
+				break
+			}
+		}
+	}
+	return dcList
+}
@@ -0,0 +1,250 @@
+package nacos
+
+import (
+	"errors"
+	"fmt"
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/console"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Reasons of conditions, besides reasons of SyncStatus
+const (
+	ConditionReasonReady              = "Ready"
+	ConditionReasonReachable          = "Reachable"
+	ConditionReasonAuthenticated      = "Authenticated"
+	ConditionReasonListening          = "Listening"
+	ConditionReasonUnknown            = "Unknown"
+	ConditionReasonInvalidAuthConfig  = "InvalidAuthConfig"
+	ConditionReasonClientCreateFailed = "ClientCreateFailed"
+	ConditionReasonDataIdsNotReady    = "DataIdsNotReady"
+	ConditionReasonSyncFailed         = "SyncFailed"
+	ConditionReasonSuspended          = "Suspended"
+)
+
+// clientSetupError means nacos config client can not be created, so that nothing is synced
+type clientSetupError struct {
+	reason string
+	err    error
+}
+
+func (e *clientSetupError) Error() string {
+	return e.err.Error()
+}
+
+func (e *clientSetupError) Unwrap() error {
+	return e.err
+}
+
+// UpdateConditions sets conditions of DynamicConfiguration according to sync statuses and error of last sync
+func UpdateConditions(dc *nacosiov1.DynamicConfiguration, syncErr error) {
+	if dc == nil {
+		return
+	}
+	var setupErr *clientSetupError
+	if !errors.As(syncErr, &setupErr) {
+		setupErr = nil
+	}
+
+	meta.RemoveStatusCondition(&dc.Status.Conditions, nacosiov1.ConditionSuspended)
+	var notReady, conflicts, drifted, authFailed, unreachable, listenFailed []string
+	for _, s := range dc.Status.SyncStatuses {
+		if s.Conflict {
+			conflicts = append(conflicts, s.DataId)
+			continue
+		}
+		if s.Ready {
+			continue
+		}
+		notReady = append(notReady, s.DataId)
+		switch s.Reason {
+		case nacosiov1.SyncReasonAuthFailed:
+			authFailed = append(authFailed, s.DataId)
+		case nacosiov1.SyncReasonServerUnreachable:
+			unreachable = append(unreachable, s.DataId)
+		case nacosiov1.SyncReasonListenFailed:
+			listenFailed = append(listenFailed, s.DataId)
+		case nacosiov1.SyncReasonDrifted:
+			drifted = append(drifted, s.DataId)
+		}
+	}
+
+	switch {
+	case setupErr != nil && setupErr.reason == ConditionReasonClientCreateFailed:
+		setCondition(dc, nacosiov1.ConditionServerReachable, metav1.ConditionFalse, setupErr.reason, setupErr.Error())
+	case setupErr != nil:
+		setCondition(dc, nacosiov1.ConditionServerReachable, metav1.ConditionUnknown, setupErr.reason, "nacos config client not created")
+	case len(unreachable) > 0:
+		setCondition(dc, nacosiov1.ConditionServerReachable, metav1.ConditionFalse, nacosiov1.SyncReasonServerUnreachable, dataIdsMessage("server unreachable for dataIds", unreachable))
+	default:
+		setCondition(dc, nacosiov1.ConditionServerReachable, metav1.ConditionTrue, ConditionReasonReachable, "")
+	}
+
+	switch {
+	case setupErr != nil && setupErr.reason == ConditionReasonInvalidAuthConfig:
+		setCondition(dc, nacosiov1.ConditionAuthenticated, metav1.ConditionFalse, setupErr.reason, setupErr.Error())
+	case setupErr != nil:
+		setCondition(dc, nacosiov1.ConditionAuthenticated, metav1.ConditionUnknown, setupErr.reason, "nacos config client not created")
+	case len(authFailed) > 0:
+		setCondition(dc, nacosiov1.ConditionAuthenticated, metav1.ConditionFalse, nacosiov1.SyncReasonAuthFailed, dataIdsMessage("authentication failed for dataIds", authFailed))
+	case len(unreachable) > 0 && len(unreachable) == len(notReady):
+		setCondition(dc, nacosiov1.ConditionAuthenticated, metav1.ConditionUnknown, nacosiov1.SyncReasonServerUnreachable, "nacos server unreachable")
+	default:
+		setCondition(dc, nacosiov1.ConditionAuthenticated, metav1.ConditionTrue, ConditionReasonAuthenticated, "")
+	}
+
+	if needListening(dc) {
+		switch {
+		case setupErr != nil:
+			setCondition(dc, nacosiov1.ConditionListening, metav1.ConditionUnknown, setupErr.reason, "nacos config client not created")
+		case len(listenFailed) > 0:
+			setCondition(dc, nacosiov1.ConditionListening, metav1.ConditionFalse, nacosiov1.SyncReasonListenFailed, dataIdsMessage("listen failed for dataIds", listenFailed))
+		default:
+			setCondition(dc, nacosiov1.ConditionListening, metav1.ConditionTrue, ConditionReasonListening, "")
+		}
+	} else {
+		meta.RemoveStatusCondition(&dc.Status.Conditions, nacosiov1.ConditionListening)
+	}
+
+	switch {
+	case setupErr != nil:
+		setCondition(dc, nacosiov1.ConditionSynced, metav1.ConditionFalse, setupErr.reason, setupErr.Error())
+	case len(notReady) > 0 && len(notReady) == len(drifted):
+		setCondition(dc, nacosiov1.ConditionSynced, metav1.ConditionFalse, nacosiov1.SyncReasonDrifted, dataIdsMessage("drifted dataIds", drifted))
+	case len(notReady) > 0:
+		setCondition(dc, nacosiov1.ConditionSynced, metav1.ConditionFalse, ConditionReasonDataIdsNotReady, dataIdsMessage("not ready dataIds", notReady))
+	case len(conflicts) > 0:
+		setCondition(dc, nacosiov1.ConditionSynced, metav1.ConditionFalse, nacosiov1.SyncReasonConflict, dataIdsMessage("conflict dataIds", conflicts))
+	case syncErr != nil:
+		setCondition(dc, nacosiov1.ConditionSynced, metav1.ConditionFalse, ConditionReasonSyncFailed, syncErr.Error())
+	default:
+		setCondition(dc, nacosiov1.ConditionSynced, metav1.ConditionTrue, nacosiov1.SyncReasonSynced, "")
+	}
+
+	for _, t := range []string{nacosiov1.ConditionServerReachable, nacosiov1.ConditionAuthenticated, nacosiov1.ConditionListening, nacosiov1.ConditionSynced} {
+		c := meta.FindStatusCondition(dc.Status.Conditions, t)
+		if c == nil || c.Status == metav1.ConditionTrue {
+			continue
+		}
+		setCondition(dc, nacosiov1.ConditionReady, metav1.ConditionFalse, c.Reason, fmt.Sprintf("%s: %s", t, c.Message))
+		return
+	}
+	setCondition(dc, nacosiov1.ConditionReady, metav1.ConditionTrue, ConditionReasonReady, "")
+}
+
+// SetNotReadyCondition sets Ready condition to false, used when DynamicConfiguration is not synced at all
+func SetNotReadyCondition(dc *nacosiov1.DynamicConfiguration, reason, message string) {
+	if dc == nil {
+		return
+	}
+	setCondition(dc, nacosiov1.ConditionReady, metav1.ConditionFalse, reason, message)
+}
+
+// SetSuspendedCondition sets Suspended condition to true and Ready condition to false, other conditions are kept
+// as they were at last sync
+func SetSuspendedCondition(dc *nacosiov1.DynamicConfiguration) {
+	if dc == nil {
+		return
+	}
+	setCondition(dc, nacosiov1.ConditionSuspended, metav1.ConditionTrue, ConditionReasonSuspended, "syncing is suspended by spec.suspend")
+	setCondition(dc, nacosiov1.ConditionReady, metav1.ConditionFalse, ConditionReasonSuspended, "syncing is suspended by spec.suspend")
+}
+
+// WasSuspended returns true if dc was suspended at last reconciling, and is not synced since then
+func WasSuspended(dc *nacosiov1.DynamicConfiguration) bool {
+	return meta.IsStatusConditionTrue(dc.Status.Conditions, nacosiov1.ConditionSuspended)
+}
+
+func setCondition(dc *nacosiov1.DynamicConfiguration, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&dc.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: dc.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// needListening returns whether dataIds are listened from nacos server
+func needListening(dc *nacosiov1.DynamicConfiguration) bool {
+	if dc.Spec.Strategy.DryRun {
+		return false
+	}
+	switch dc.Spec.Strategy.SyncDirection {
+	case nacosiov1.Server2Cluster:
+		for _, entry := range GetConfigEntries(dc) {
+			if entry.SyncPolicy != nacosiov1.IfAbsent {
+				return true
+			}
+		}
+		return false
+	case nacosiov1.Bidirectional:
+		return true
+	default:
+		return false
+	}
+}
+
+func dataIdsMessage(prefix string, dataIds []string) string {
+	return fmt.Sprintf("%s: %s", prefix, strings.Join(dataIds, ","))
+}
+
+// syncReasonForError returns reason of SyncStatus for err, defaultReason is returned unless err is caused by
+// authorization or network
+func syncReasonForError(err error, defaultReason string) string {
+	switch {
+	case isAuthError(err):
+		return nacosiov1.SyncReasonAuthFailed
+	case isUnreachableError(err):
+		return nacosiov1.SyncReasonServerUnreachable
+	default:
+		return defaultReason
+	}
+}
+
+// authFailedMessages are messages of nacos server when authentication or authorization failed, they are matched
+// since nacos sdk drops the error code of grpc responses
+var authFailedMessages = []string{"user not found!", "token invalid!", "token expired!", "authorization failed!",
+	"unknown user!", "access denied", "forbidden", "unauthorized"}
+
+// isAuthError returns true if err is caused by authentication or authorization failure, status code is matched if
+// it is kept in err, otherwise message of nacos server is matched
+func isAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var nacosErr *nacos_error.NacosError
+	if errors.As(err, &nacosErr) {
+		code := nacosErr.ErrorCode()
+		return code == strconv.Itoa(http.StatusUnauthorized) || code == strconv.Itoa(http.StatusForbidden)
+	}
+	var statusErr *console.StatusError
+	if errors.As(err, &statusErr) {
+		return console.IsForbidden(statusErr)
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range authFailedMessages {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isUnreachableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"connection refused", "no such host", "timeout", "deadline exceeded", "client not connected", "read config from both server and cache fail"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
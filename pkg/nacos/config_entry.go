@@ -0,0 +1,98 @@
+package nacos
+
+import (
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+)
+
+// ConfigEntry is a dataId to be synced, with group, key and sync strategy resolved from
+// spec.configs, spec.dataIds and dataIds selected by spec.dataIdSelector
+type ConfigEntry struct {
+	DataId string
+	Group  string
+	// Key in object reference which stores content of the dataId
+	Key          string
+	SyncPolicy   nacosiov1.DynamicConfigurationSyncPolicy
+	SyncDeletion bool
+	// Type is the explicit config type of spec.configs, published to nacos server
+	Type nacosiov1.ConfigFormat
+	// Format is the content format used to validate content, Type or detected by extension of dataId
+	Format nacosiov1.ConfigFormat
+}
+
+// GetConfigEntries returns all dataIds of dc. spec.configs comes first, and then spec.dataIds and selected dataIds.
+// DataId appears more than once is ignored, even in different groups, since status of dc is kept by dataId.
+func GetConfigEntries(dc *nacosiov1.DynamicConfiguration) []ConfigEntry {
+	var entries []ConfigEntry
+	contains := func(dataId string) bool {
+		for _, e := range entries {
+			if e.DataId == dataId {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range dc.Spec.Configs {
+		if len(c.DataId) == 0 || contains(c.DataId) {
+			continue
+		}
+		entry := newDefaultConfigEntry(dc, c.DataId)
+		if len(c.Group) > 0 {
+			entry.Group = c.Group
+		}
+		if len(c.Key) > 0 {
+			entry.Key = c.Key
+		}
+		if len(c.SyncPolicy) > 0 {
+			entry.SyncPolicy = c.SyncPolicy
+		}
+		if c.SyncDeletion != nil {
+			entry.SyncDeletion = *c.SyncDeletion
+		}
+		if len(c.Type) > 0 {
+			entry.Type = c.Type
+			entry.Format = c.Type
+		}
+		entries = append(entries, entry)
+	}
+	dataIds := dc.Spec.DataIds
+	if dc.Spec.DataIdSelector != nil {
+		dataIds = append(append([]string{}, dataIds...), dc.Status.SelectedDataIds...)
+	}
+	for _, dataId := range dataIds {
+		if contains(dataId) {
+			continue
+		}
+		entries = append(entries, newDefaultConfigEntry(dc, dataId))
+	}
+	return entries
+}
+
+// FindConfigEntry returns the entry of dataId in group, nil if dc doesn't sync it
+func FindConfigEntry(dc *nacosiov1.DynamicConfiguration, group, dataId string) *ConfigEntry {
+	for _, e := range GetConfigEntries(dc) {
+		if e.DataId == dataId && e.Group == group {
+			return &e
+		}
+	}
+	return nil
+}
+
+// GetDataIds returns dataIds of all entries of dc
+func GetDataIds(dc *nacosiov1.DynamicConfiguration) []string {
+	var dataIds []string
+	for _, e := range GetConfigEntries(dc) {
+		dataIds = append(dataIds, e.DataId)
+	}
+	return dataIds
+}
+
+func newDefaultConfigEntry(dc *nacosiov1.DynamicConfiguration, dataId string) ConfigEntry {
+	return ConfigEntry{
+		DataId:       dataId,
+		Group:        dc.Spec.NacosServer.Group,
+		Key:          dataId,
+		SyncPolicy:   dc.Spec.Strategy.SyncPolicy,
+		SyncDeletion: dc.Spec.Strategy.SyncDeletion,
+		Format:       DetectContentFormat(dataId, ""),
+	}
+}
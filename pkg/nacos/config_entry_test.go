@@ -0,0 +1,139 @@
+package nacos
+
+import (
+	"context"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ConfigEntry", func() {
+	var ctx context.Context
+	var server *fake.ConfigServer
+	var k8sClient client.Client
+	var controller *SyncConfigurationController
+
+	newConfigsDC := func(name string, direction nacosiov1.DynamicConfigurationSyncDirection, configs ...nacosiov1.DataIdConfig) *nacosiov1.DynamicConfiguration {
+		return &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, UID: types.UID(name)},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				Configs:  configs,
+				Strategy: nacosiov1.SyncStrategy{SyncPolicy: nacosiov1.Always, SyncDirection: direction},
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String("127.0.0.1:8848"),
+					Namespace:  testNacosNamespace,
+					Group:      testGroup,
+					AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+				},
+				ObjectRef: &v1.ObjectReference{Name: name, APIVersion: "v1", Kind: "ConfigMap"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = fake.NewConfigServer()
+		k8sClient = fakeclient.NewClientBuilder().
+			WithScheme(testScheme).
+			WithStatusSubresource(&nacosiov1.DynamicConfiguration{}).
+			WithObjects(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "nacos-auth", Namespace: testNamespace},
+				Data:       map[string][]byte{"ak": []byte("ak"), "sk": []byte("sk")},
+			}).
+			Build()
+		controller = NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+			ConfigClientFactory: server.ConfigClientFactory(),
+		})
+	})
+
+	DescribeTable("resolves entries from spec.configs, spec.dataIds and selected dataIds",
+		func(mutate func(dc *nacosiov1.DynamicConfiguration), expected []ConfigEntry) {
+			dc := newConfigsDC("entries", nacosiov1.Cluster2Server)
+			mutate(dc)
+			Expect(GetConfigEntries(dc)).To(Equal(expected))
+		},
+		Entry("defaults of dataIds", func(dc *nacosiov1.DynamicConfiguration) {
+			dc.Spec.DataIds = []string{"app.yaml", "app.conf"}
+		}, []ConfigEntry{
+			{DataId: "app.yaml", Group: testGroup, Key: "app.yaml", SyncPolicy: nacosiov1.Always, Format: nacosiov1.ConfigFormatYaml},
+			{DataId: "app.conf", Group: testGroup, Key: "app.conf", SyncPolicy: nacosiov1.Always, Format: nacosiov1.ConfigFormatText},
+		}),
+		Entry("overrides of configs", func(dc *nacosiov1.DynamicConfiguration) {
+			dc.Spec.Strategy.SyncDeletion = true
+			dc.Spec.Configs = []nacosiov1.DataIdConfig{{
+				DataId: "app.conf", Group: "APP", Key: "app", SyncPolicy: nacosiov1.IfAbsent,
+				SyncDeletion: pointer.Bool(false), Type: nacosiov1.ConfigFormatJson,
+			}}
+		}, []ConfigEntry{
+			{DataId: "app.conf", Group: "APP", Key: "app", SyncPolicy: nacosiov1.IfAbsent, SyncDeletion: false,
+				Type: nacosiov1.ConfigFormatJson, Format: nacosiov1.ConfigFormatJson},
+		}),
+		Entry("configs come first and duplicated dataIds are ignored", func(dc *nacosiov1.DynamicConfiguration) {
+			dc.Spec.Configs = []nacosiov1.DataIdConfig{{DataId: "app.yaml", Group: "APP"}, {DataId: "app.yaml", Group: "OTHER"}}
+			dc.Spec.DataIds = []string{"app.yaml", "db.yaml"}
+			dc.Spec.DataIdSelector = &nacosiov1.DataIdSelector{}
+			dc.Status.SelectedDataIds = []string{"db.yaml", "selected.yaml"}
+		}, []ConfigEntry{
+			{DataId: "app.yaml", Group: "APP", Key: "app.yaml", SyncPolicy: nacosiov1.Always, Format: nacosiov1.ConfigFormatYaml},
+			{DataId: "db.yaml", Group: testGroup, Key: "db.yaml", SyncPolicy: nacosiov1.Always, Format: nacosiov1.ConfigFormatYaml},
+			{DataId: "selected.yaml", Group: testGroup, Key: "selected.yaml", SyncPolicy: nacosiov1.Always, Format: nacosiov1.ConfigFormatYaml},
+		}),
+	)
+
+	It("publishes content of keys to groups of configs with their formats", func() {
+		server.Publish(testNacosNamespace, "APP", "exists.yaml", "a: 0")
+		dc := newConfigsDC("c2s-configs", nacosiov1.Cluster2Server,
+			nacosiov1.DataIdConfig{DataId: "app.yaml", Group: "APP", Key: "app"},
+			nacosiov1.DataIdConfig{DataId: "settings.conf", Type: nacosiov1.ConfigFormatJson},
+			nacosiov1.DataIdConfig{DataId: "broken.conf", Type: nacosiov1.ConfigFormatJson},
+			nacosiov1.DataIdConfig{DataId: "exists.yaml", Group: "APP", SyncPolicy: nacosiov1.IfAbsent},
+		)
+		Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "c2s-configs", Namespace: testNamespace},
+			Data: map[string]string{
+				"app":           "a: 1",
+				"app.yaml":      "not: synced",
+				"settings.conf": `{"a": 1}`,
+				"broken.conf":   `{"a": `,
+				"exists.yaml":   "a: 2",
+			},
+		})).To(Succeed())
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+		content, _ := server.Get(testNacosNamespace, "APP", "app.yaml")
+		Expect(content).To(Equal("a: 1"))
+		_, exist := server.Get(testNacosNamespace, testGroup, "app.yaml")
+		Expect(exist).To(BeFalse())
+		content, _ = server.Get(testNacosNamespace, testGroup, "settings.conf")
+		Expect(content).To(Equal(`{"a": 1}`))
+		Expect(server.Type(testNacosNamespace, testGroup, "settings.conf")).To(Equal("json"))
+		_, exist = server.Get(testNacosNamespace, testGroup, "broken.conf")
+		Expect(exist).To(BeFalse())
+		Expect(GetSyncStatusByDataId(dc.Status.SyncStatuses, "broken.conf").Ready).To(BeFalse())
+		content, _ = server.Get(testNacosNamespace, "APP", "exists.yaml")
+		Expect(content).To(Equal("a: 0"))
+	})
+
+	It("stores content of groups of configs to their keys", func() {
+		server.Publish(testNacosNamespace, "APP", "app.yaml", "a: 1")
+		server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: default")
+		server.Publish(testNacosNamespace, testGroup, "db.yaml", "db: 1")
+		dc := newConfigsDC("s2c-configs", nacosiov1.Server2Cluster,
+			nacosiov1.DataIdConfig{DataId: "app.yaml", Group: "APP", Key: "app"},
+			nacosiov1.DataIdConfig{DataId: "db.yaml"},
+		)
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		cm := &v1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "s2c-configs"}, cm)).To(Succeed())
+		Expect(cm.Data).To(Equal(map[string]string{"app": "a: 1", "db.yaml": "db: 1"}))
+	})
+})
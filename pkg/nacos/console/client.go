@@ -0,0 +1,288 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
+)
+
+const (
+	// DefaultContextPath is the context path of nacos server if neither serverAddr nor client options contain one
+	DefaultContextPath = "/nacos"
+	defaultTimeout     = 10 * time.Second
+)
+
+// Namespace is a namespace of nacos server returned by console API
+type Namespace struct {
+	Namespace         string `json:"namespace"`
+	NamespaceShowName string `json:"namespaceShowName"`
+	NamespaceDesc     string `json:"namespaceDesc"`
+	Quota             int    `json:"quota"`
+	ConfigCount       int    `json:"configCount"`
+	Type              int    `json:"type"`
+}
+
+// Client manages namespaces of nacos server by console API, and stops beta configs by open API, authenticated by username and password if they are set
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	lock        sync.Mutex
+	accessToken string
+	tokenExpire time.Time
+}
+
+// NewClient returns a console client of nacos server, only serverAddr of params is supported
+func NewClient(params *auth.ConfigClientParam, httpClient *http.Client) (*Client, error) {
+	if params == nil {
+		return nil, fmt.Errorf("empty nacos client params")
+	}
+	if len(params.ServerAddr) == 0 {
+		return nil, fmt.Errorf("serverAddr should be set to use console API, endpoint is not supported")
+	}
+	// console API is requested on the first address, since namespaces are shared by nodes of a cluster
+	addrs, err := nacosiov1.ParseServerAddrs(params.ServerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid serverAddr %s: %w", params.ServerAddr, err)
+	}
+	addr := addrs[0]
+	u := url.URL{Scheme: addr.Scheme, Host: addr.HostPort(), Path: addr.ContextPath}
+	if len(u.Scheme) == 0 {
+		u.Scheme = params.Scheme
+	}
+	if len(u.Scheme) == 0 {
+		u.Scheme = "http"
+		if params.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	if len(u.Path) == 0 {
+		u.Path = DefaultContextPath
+		if params.ClientOptions != nil && len(params.ClientOptions.ContextPath) > 0 {
+			u.Path = params.ClientOptions.ContextPath
+		}
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if params.TLS != nil {
+		// certificates differ between nacos servers, so the transport of httpClient is replaced
+		tlsConfig, err := params.TLS.Config()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls config: %w", err)
+		}
+		httpClient = &http.Client{
+			Timeout:   httpClient.Timeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+		}
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(u.String(), "/"),
+		username:   params.AuthInfo.Username,
+		password:   params.AuthInfo.Password,
+		httpClient: httpClient,
+	}, nil
+}
+
+// ListNamespaces returns all namespaces of nacos server
+func (c *Client) ListNamespaces(ctx context.Context) ([]Namespace, error) {
+	body, err := c.do(ctx, http.MethodGet, "/v1/console/namespaces", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp := struct {
+		Code    int         `json:"code"`
+		Message string      `json:"message"`
+		Data    []Namespace `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response of listing namespaces: %w", err)
+	}
+	if resp.Code != 0 && resp.Code != http.StatusOK {
+		return nil, fmt.Errorf("list namespaces error, code: %d, message: %s", resp.Code, resp.Message)
+	}
+	return resp.Data, nil
+}
+
+// GetNamespace returns the namespace of id, nil if it doesn't exist
+func (c *Client) GetNamespace(ctx context.Context, id string) (*Namespace, error) {
+	namespaces, err := c.ListNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range namespaces {
+		if namespaces[i].Namespace == id {
+			return &namespaces[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateNamespace creates a namespace with id, show name and description
+func (c *Client) CreateNamespace(ctx context.Context, id, name, desc string) error {
+	return c.expectTrue(c.do(ctx, http.MethodPost, "/v1/console/namespaces", url.Values{
+		"customNamespaceId": {id},
+		"namespaceName":     {name},
+		"namespaceDesc":     {desc},
+	}))
+}
+
+// UpdateNamespace updates show name and description of the namespace
+func (c *Client) UpdateNamespace(ctx context.Context, id, name, desc string) error {
+	return c.expectTrue(c.do(ctx, http.MethodPut, "/v1/console/namespaces", url.Values{
+		"namespace":         {id},
+		"namespaceShowName": {name},
+		"namespaceDesc":     {desc},
+	}))
+}
+
+// DeleteNamespace deletes the namespace, configs in it are not deleted by nacos server
+func (c *Client) DeleteNamespace(ctx context.Context, id string) error {
+	return c.expectTrue(c.do(ctx, http.MethodDelete, "/v1/console/namespaces?namespaceId="+url.QueryEscape(id), nil))
+}
+
+// StopBeta stops beta config of dataId by open API, so that beta IPs get the same content as other clients
+func (c *Client) StopBeta(ctx context.Context, namespace, group, dataId string) error {
+	query := url.Values{
+		"beta":   {"true"},
+		"dataId": {dataId},
+		"group":  {group},
+		"tenant": {namespace},
+	}
+	body, err := c.do(ctx, http.MethodDelete, "/v1/cs/configs?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp := struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    bool   `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("invalid response of stopping beta: %w", err)
+	}
+	if resp.Code != http.StatusOK || !resp.Data {
+		return fmt.Errorf("stop beta error, code: %d, message: %s", resp.Code, resp.Message)
+	}
+	return nil
+}
+
+func (c *Client) expectTrue(body []byte, err error) error {
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(body)) != "true" {
+		return fmt.Errorf("unexpected response: %s", string(body))
+	}
+	return nil
+}
+
+// do sends a request to nacos server with access token, form is sent as body of POST and PUT
+func (c *Client) do(ctx context.Context, method, path string, form url.Values) ([]byte, error) {
+	token, err := c.getAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u := c.baseURL + path
+	if len(token) > 0 {
+		sep := "?"
+		if strings.Contains(u, "?") {
+			sep = "&"
+		}
+		u = u + sep + "accessToken=" + url.QueryEscape(token)
+	}
+	var reqBody io.Reader
+	if form != nil {
+		reqBody = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return c.send(req)
+}
+
+// getAccessToken logs in nacos server if username is set, the token is reused until it expires
+func (c *Client) getAccessToken(ctx context.Context) (string, error) {
+	if len(c.username) == 0 {
+		return "", nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.accessToken) > 0 && time.Now().Before(c.tokenExpire) {
+		return c.accessToken, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/auth/login", strings.NewReader(url.Values{
+		"username": {c.username},
+		"password": {c.password},
+	}.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := c.send(req)
+	if err != nil {
+		return "", fmt.Errorf("login error: %w", err)
+	}
+	resp := struct {
+		AccessToken string `json:"accessToken"`
+		TokenTtl    int64  `json:"tokenTtl"`
+	}{}
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.AccessToken) == 0 {
+		return "", fmt.Errorf("login error: invalid response: %s", string(body))
+	}
+	c.accessToken = resp.AccessToken
+	// refresh token a little earlier than it expires
+	c.tokenExpire = time.Now().Add(time.Duration(resp.TokenTtl)*time.Second - 10*time.Second)
+	return c.accessToken, nil
+}
+
+func (c *Client) send(req *http.Request) ([]byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return body, nil
+}
+
+// StatusError is returned when nacos server responds with a non 200 status code
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status code %d: %s", e.StatusCode, e.Body)
+}
+
+// IsForbidden returns true if err is caused by authentication or authorization failure
+func IsForbidden(err error) bool {
+	var e *StatusError
+	if errors.As(err, &e) {
+		return e.StatusCode == http.StatusForbidden || e.StatusCode == http.StatusUnauthorized
+	}
+	return false
+}
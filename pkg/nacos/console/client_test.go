@@ -0,0 +1,128 @@
+package console
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	var ctx context.Context
+	var configs *fake.ConfigServer
+	var namespaces *fake.NamespaceServer
+	var server *httptest.Server
+	newClient := func(username, password string) *Client {
+		c, err := NewClient(&auth.ConfigClientParam{
+			ServerAddr: strings.TrimPrefix(server.URL, "http://"),
+			AuthInfo:   auth.ConfigClientAuthInfo{Username: username, Password: password},
+		}, server.Client())
+		Expect(err).NotTo(HaveOccurred())
+		return c
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		configs = fake.NewConfigServer()
+		namespaces = fake.NewNamespaceServer(configs, "nacos", "nacos")
+		server = httptest.NewServer(namespaces)
+		DeferCleanup(server.Close)
+	})
+
+	It("creates, updates and deletes namespace with access token of login", func() {
+		c := newClient("nacos", "nacos")
+		Expect(c.CreateNamespace(ctx, "dev", "Dev", "dev env")).To(Succeed())
+		ns, err := c.GetNamespace(ctx, "dev")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ns).NotTo(BeNil())
+		Expect(ns.NamespaceShowName).To(Equal("Dev"))
+		Expect(ns.NamespaceDesc).To(Equal("dev env"))
+
+		configs.Publish("dev", "DEFAULT_GROUP", "app.yaml", "a: 1")
+		Expect(c.UpdateNamespace(ctx, "dev", "Development", "")).To(Succeed())
+		ns, err = c.GetNamespace(ctx, "dev")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ns.NamespaceShowName).To(Equal("Development"))
+		Expect(ns.ConfigCount).To(Equal(1))
+
+		Expect(c.DeleteNamespace(ctx, "dev")).To(Succeed())
+		Expect(c.GetNamespace(ctx, "dev")).To(BeNil())
+		// token is reused after the first login
+		login := 0
+		for _, req := range namespaces.Requests() {
+			if req == "POST /nacos/v1/auth/login" {
+				login++
+			}
+		}
+		Expect(login).To(Equal(1))
+	})
+
+	It("stops beta of config", func() {
+		_, err := configs.NewConfigClient("dev").PublishConfig(vo.ConfigParam{
+			DataId: "app.yaml", Group: "DEFAULT_GROUP", Content: "a: 2", BetaIps: "10.0.0.1",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		_, _, exist := configs.Beta("dev", "DEFAULT_GROUP", "app.yaml")
+		Expect(exist).To(BeTrue())
+
+		Expect(newClient("nacos", "nacos").StopBeta(ctx, "dev", "DEFAULT_GROUP", "app.yaml")).To(Succeed())
+		_, _, exist = configs.Beta("dev", "DEFAULT_GROUP", "app.yaml")
+		Expect(exist).To(BeFalse())
+	})
+
+	It("returns forbidden error if credentials are wrong", func() {
+		_, err := newClient("nacos", "wrong").ListNamespaces(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(IsForbidden(err)).To(BeTrue())
+		_, err = newClient("", "").ListNamespaces(ctx)
+		Expect(IsForbidden(err)).To(BeTrue())
+	})
+
+	It("connects by https with certificates of tls", func() {
+		certs, err := fake.NewCertificates("nacos-controller", "127.0.0.1")
+		Expect(err).NotTo(HaveOccurred())
+		serverCert, err := tls.X509KeyPair(certs.ServerCert, certs.ServerKey)
+		Expect(err).NotTo(HaveOccurred())
+		pool := x509.NewCertPool()
+		Expect(pool.AppendCertsFromPEM(certs.CA)).To(BeTrue())
+		tlsServer := httptest.NewUnstartedServer(namespaces)
+		tlsServer.TLS = &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientCAs:    pool,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+		}
+		tlsServer.StartTLS()
+		DeferCleanup(tlsServer.Close)
+
+		params := &auth.ConfigClientParam{
+			ServerAddr: strings.TrimPrefix(tlsServer.URL, "https://"),
+			AuthInfo:   auth.ConfigClientAuthInfo{Username: "nacos", Password: "nacos"},
+			TLS:        &auth.ConfigClientTLS{CACert: certs.CA, Cert: certs.ClientCert, Key: certs.ClientKey},
+		}
+		c, err := NewClient(params, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.CreateNamespace(ctx, "dev", "Dev", "")).To(Succeed())
+		ns, exist := namespaces.Namespace("dev")
+		Expect(exist).To(BeTrue())
+		Expect(ns.ShowName).To(Equal("Dev"))
+
+		// server rejects clients without certificate
+		params.TLS = &auth.ConfigClientTLS{CACert: certs.CA}
+		c, err = NewClient(params, nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.ListNamespaces(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("requires serverAddr", func() {
+		_, err := NewClient(&auth.ConfigClientParam{Endpoint: "endpoint:8080"}, nil)
+		Expect(err).To(MatchError(ContainSubstring("endpoint is not supported")))
+	})
+})
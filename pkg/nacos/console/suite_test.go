@@ -0,0 +1,14 @@
+package console
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestConsole(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Nacos Console Suite")
+}
@@ -0,0 +1,113 @@
+package nacos
+
+import (
+	"context"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("credentials", func() {
+	var ctx context.Context
+	var server *fake.ConfigServer
+	var k8sClient client.Client
+	var controller *SyncConfigurationController
+
+	newAuthDC := func(name string, authKeys *nacosiov1.NacosAuthKeys) *nacosiov1.DynamicConfiguration {
+		return &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, UID: types.UID(name)},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				DataIds:  []string{"app.yaml"},
+				Strategy: nacosiov1.SyncStrategy{SyncPolicy: nacosiov1.Always, SyncDirection: nacosiov1.Cluster2Server},
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String("127.0.0.1:8848"),
+					Namespace:  testNacosNamespace,
+					Group:      testGroup,
+					AuthRef:    &v1.ObjectReference{Name: name, APIVersion: "v1", Kind: "Secret"},
+					AuthKeys:   authKeys,
+				},
+				ObjectRef: &v1.ObjectReference{Name: "app", APIVersion: "v1", Kind: "ConfigMap"},
+			},
+		}
+	}
+	createSecret := func(name string, data map[string]string) {
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace}, Data: map[string][]byte{}}
+		for k, v := range data {
+			secret.Data[k] = []byte(v)
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+	}
+	authenticated := func(dc *nacosiov1.DynamicConfiguration) *metav1.Condition {
+		return meta.FindStatusCondition(dc.Status.Conditions, nacosiov1.ConditionAuthenticated)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = fake.NewConfigServer()
+		k8sClient = fakeclient.NewClientBuilder().
+			WithScheme(testScheme).
+			WithStatusSubresource(&nacosiov1.DynamicConfiguration{}).
+			WithObjects(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			}).
+			Build()
+		controller = NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+			ConfigClientFactory: server.ConfigClientFactory(),
+		})
+	})
+
+	It("logs in by username and password read from custom keys", func() {
+		server.SetCredentials(&auth.ConfigClientAuthInfo{Username: "nacos", Password: "s3cr3t"})
+		createSecret("login", map[string]string{"user": "nacos", "pass": "s3cr3t", "username": "ignored"})
+		dc := newAuthDC("login", &nacosiov1.NacosAuthKeys{Username: "user", Password: "pass"})
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		content, _ := server.Get(testNacosNamespace, testGroup, "app.yaml")
+		Expect(content).To(Equal("a: 1"))
+		Expect(authenticated(dc).Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("authenticates by access key and secret key read from custom keys", func() {
+		server.SetCredentials(&auth.ConfigClientAuthInfo{AccessKey: "ak", SecretKey: "sk"})
+		createSecret("aksk", map[string]string{"accessKeyId": "ak", "accessKeySecret": "sk"})
+		dc := newAuthDC("aksk", &nacosiov1.NacosAuthKeys{AccessKey: "accessKeyId", SecretKey: "accessKeySecret"})
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		content, _ := server.Get(testNacosNamespace, testGroup, "app.yaml")
+		Expect(content).To(Equal("a: 1"))
+	})
+
+	It("reports credentials rejected by nacos server", func() {
+		server.SetCredentials(&auth.ConfigClientAuthInfo{Username: "nacos", Password: "s3cr3t"})
+		createSecret("wrong", map[string]string{"username": "nacos", "password": "wrong"})
+		dc := newAuthDC("wrong", nil)
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+		_, exist := server.Get(testNacosNamespace, testGroup, "app.yaml")
+		Expect(exist).To(BeFalse())
+		Expect(authenticated(dc).Status).To(Equal(metav1.ConditionFalse))
+		Expect(authenticated(dc).Reason).To(Equal(nacosiov1.SyncReasonAuthFailed))
+	})
+
+	It("rejects incomplete credentials without connecting nacos server", func() {
+		createSecret("incomplete", map[string]string{"user": "nacos"})
+		dc := newAuthDC("incomplete", &nacosiov1.NacosAuthKeys{Username: "user", Password: "pass"})
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+		Expect(server.Clients()).To(BeEmpty())
+		Expect(authenticated(dc).Status).To(Equal(metav1.ConditionFalse))
+		Expect(authenticated(dc).Reason).To(Equal(ConditionReasonInvalidAuthConfig))
+		Expect(authenticated(dc).Message).To(ContainSubstring("empty field pass"))
+	})
+})
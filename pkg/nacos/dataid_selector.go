@@ -0,0 +1,93 @@
+package nacos
+
+import (
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDataIdSelectorRefreshInterval = time.Minute
+	searchPageSize                       = 100
+)
+
+// GetDataIdSelectorRefreshInterval returns interval to search nacos server for new dataIds, zero if no selector
+func GetDataIdSelectorRefreshInterval(dc *nacosiov1.DynamicConfiguration) time.Duration {
+	selector := dc.Spec.DataIdSelector
+	if selector == nil {
+		return 0
+	}
+	if selector.RefreshInterval != nil && selector.RefreshInterval.Duration > 0 {
+		return selector.RefreshInterval.Duration
+	}
+	return DefaultDataIdSelectorRefreshInterval
+}
+
+// selectDataIds searches dataIds in group of nacos server, which match dc.spec.dataIdSelector
+func selectDataIds(configClient config_client.IConfigClient, group string, selector *nacosiov1.DataIdSelector) ([]string, error) {
+	var re *regexp.Regexp
+	if len(selector.Regex) > 0 {
+		var err error
+		if re, err = regexp.Compile(selector.Regex); err != nil {
+			return nil, err
+		}
+	}
+	var dataIds []string
+	for pageNo := 1; ; pageNo++ {
+		// blur search of nacos server supports '*' only, result is filtered by glob pattern again
+		page, err := configClient.SearchConfig(vo.SearchConfigParam{
+			Search:   "blur",
+			DataId:   blurSearchDataId(selector.Pattern),
+			Group:    group,
+			Tag:      strings.Join(selector.Tags, ","),
+			PageNo:   pageNo,
+			PageSize: searchPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			break
+		}
+		for _, item := range page.PageItems {
+			if item.Group != group || StringSliceContains(dataIds, item.DataId) {
+				continue
+			}
+			if !matchDataId(selector.Pattern, re, item.DataId) {
+				continue
+			}
+			dataIds = append(dataIds, item.DataId)
+		}
+		if len(page.PageItems) == 0 || pageNo >= page.PagesAvailable {
+			break
+		}
+	}
+	sort.Strings(dataIds)
+	return dataIds, nil
+}
+
+func matchDataId(pattern string, re *regexp.Regexp, dataId string) bool {
+	if len(pattern) > 0 {
+		if matched, err := path.Match(pattern, dataId); err != nil || !matched {
+			return false
+		}
+	}
+	return re == nil || re.MatchString(dataId)
+}
+
+// blurSearchDataId converts glob pattern to dataId of blur search, characters not supported by nacos server
+// are replaced by '*'
+func blurSearchDataId(pattern string) string {
+	if len(pattern) == 0 {
+		return ""
+	}
+	if i := strings.IndexAny(pattern, "?[\\"); i >= 0 {
+		pattern = pattern[:i] + "*"
+	}
+	return pattern
+}
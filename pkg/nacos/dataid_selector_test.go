@@ -0,0 +1,135 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("DataIdSelector", func() {
+	var server *fake.ConfigServer
+
+	BeforeEach(func() {
+		server = fake.NewConfigServer()
+		server.Publish(testNacosNamespace, testGroup, "app-1.yaml", "a: 1", "web")
+		server.Publish(testNacosNamespace, testGroup, "app-2.yaml", "a: 2")
+		server.Publish(testNacosNamespace, testGroup, "app-10.yaml", "a: 10", "web", "canary")
+		server.Publish(testNacosNamespace, testGroup, "app-1.properties", "a=1")
+		server.Publish(testNacosNamespace, testGroup, "db-1.yaml", "db: 1", "db")
+		server.Publish(testNacosNamespace, "OTHER_GROUP", "app-3.yaml", "a: 3", "web")
+		server.Publish("other-ns", testGroup, "app-4.yaml", "a: 4")
+	})
+
+	DescribeTable("selects dataIds of the group by glob pattern, regex and tags",
+		func(selector nacosiov1.DataIdSelector, expected []string) {
+			dataIds, err := selectDataIds(server.NewConfigClient(testNacosNamespace), testGroup, &selector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dataIds).To(Equal(expected))
+		},
+		Entry("all dataIds", nacosiov1.DataIdSelector{},
+			[]string{"app-1.properties", "app-1.yaml", "app-10.yaml", "app-2.yaml", "db-1.yaml"}),
+		Entry("glob with *", nacosiov1.DataIdSelector{Pattern: "app-*.yaml"},
+			[]string{"app-1.yaml", "app-10.yaml", "app-2.yaml"}),
+		Entry("glob with ?, which is not supported by blur search", nacosiov1.DataIdSelector{Pattern: "app-?.yaml"},
+			[]string{"app-1.yaml", "app-2.yaml"}),
+		Entry("glob with character class", nacosiov1.DataIdSelector{Pattern: "[ad]*-1.*"},
+			[]string{"app-1.properties", "app-1.yaml", "db-1.yaml"}),
+		Entry("regex", nacosiov1.DataIdSelector{Regex: `^app-\d{2}\.yaml$`},
+			[]string{"app-10.yaml"}),
+		Entry("glob and regex", nacosiov1.DataIdSelector{Pattern: "app-*", Regex: `\.properties$`},
+			[]string{"app-1.properties"}),
+		Entry("any of tags", nacosiov1.DataIdSelector{Tags: []string{"canary", "db"}},
+			[]string{"app-10.yaml", "db-1.yaml"}),
+		Entry("tags and glob", nacosiov1.DataIdSelector{Pattern: "app-*", Tags: []string{"web"}},
+			[]string{"app-1.yaml", "app-10.yaml"}),
+		Entry("nothing matched", nacosiov1.DataIdSelector{Pattern: "none-*"}, nil),
+	)
+
+	It("rejects invalid regex", func() {
+		_, err := selectDataIds(server.NewConfigClient(testNacosNamespace), testGroup, &nacosiov1.DataIdSelector{Regex: "("})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reads every page of search results", func() {
+		var expected []string
+		for i := 0; i < 2*searchPageSize+10; i++ {
+			dataId := fmt.Sprintf("page-%03d.yaml", i)
+			server.Publish(testNacosNamespace, testGroup, dataId, "a: 1")
+			expected = append(expected, dataId)
+		}
+		c := &searchCountingClient{IConfigClient: server.NewConfigClient(testNacosNamespace)}
+		dataIds, err := selectDataIds(c, testGroup, &nacosiov1.DataIdSelector{Pattern: "page-*.yaml"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dataIds).To(Equal(expected))
+		Expect(c.searches).To(Equal(3))
+	})
+
+	It("syncs dataIds selected from nacos server to ConfigMap", func() {
+		ctx := context.Background()
+		k8sClient := fakeclient.NewClientBuilder().
+			WithScheme(testScheme).
+			WithStatusSubresource(&nacosiov1.DynamicConfiguration{}).
+			WithObjects(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "nacos-auth", Namespace: testNamespace},
+				Data:       map[string][]byte{"ak": []byte("ak"), "sk": []byte("sk")},
+			}).
+			Build()
+		controller := NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+			ConfigClientFactory: server.ConfigClientFactory(),
+		})
+		dc := &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "s2c-selector", Namespace: testNamespace, UID: "s2c-selector"},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				DataIds:        []string{"db-1.yaml"},
+				DataIdSelector: &nacosiov1.DataIdSelector{Pattern: "app-*.yaml", Tags: []string{"web"}},
+				Strategy:       nacosiov1.SyncStrategy{SyncPolicy: nacosiov1.Always, SyncDirection: nacosiov1.Server2Cluster},
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String("127.0.0.1:8848"),
+					Namespace:  testNacosNamespace,
+					Group:      testGroup,
+					AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+				},
+				ObjectRef: &v1.ObjectReference{Name: "s2c-selector", APIVersion: "v1", Kind: "ConfigMap"},
+			},
+		}
+		getData := func() map[string]string {
+			cm := &v1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "s2c-selector"}, cm)).To(Succeed())
+			return cm.Data
+		}
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		Expect(dc.Status.SelectedDataIds).To(Equal([]string{"app-1.yaml", "app-10.yaml"}))
+		Expect(getData()).To(Equal(map[string]string{"db-1.yaml": "db: 1", "app-1.yaml": "a: 1", "app-10.yaml": "a: 10"}))
+
+		// dataIds published later are selected by next sync
+		server.Publish(testNacosNamespace, testGroup, "app-20.yaml", "a: 20", "web")
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		Expect(dc.Status.SelectedDataIds).To(Equal([]string{"app-1.yaml", "app-10.yaml", "app-20.yaml"}))
+		Expect(getData()).To(HaveKeyWithValue("app-20.yaml", "a: 20"))
+		Expect(GetDataIdSelectorRefreshInterval(dc)).To(Equal(DefaultDataIdSelectorRefreshInterval))
+	})
+})
+
+// searchCountingClient counts search requests
+type searchCountingClient struct {
+	config_client.IConfigClient
+	searches int
+}
+
+func (c *searchCountingClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	c.searches++
+	return c.IConfigClient.SearchConfig(param)
+}
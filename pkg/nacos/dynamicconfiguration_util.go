@@ -5,23 +5,47 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func UpdateSyncStatus(dc *nacosiov1.DynamicConfiguration, dataId, md5, from string, t metav1.Time, ready bool, message string) {
+func UpdateSyncStatus(dc *nacosiov1.DynamicConfiguration, dataId, md5, from string, t metav1.Time, ready bool, reason, message string) {
 	if dc == nil {
 		return
 	}
-	syncStatuses := dc.Status.SyncStatuses
-	syncStatuses = replaceSyncStatus(syncStatuses, nacosiov1.SyncStatus{
+	updateSyncStatus(&dc.Status.SyncStatuses, dataId, md5, from, t, ready, reason, message)
+}
+
+// updateSyncStatus replaces sync status of dataId in statuses, e.g. statuses of dc or a target
+func updateSyncStatus(statuses *[]nacosiov1.SyncStatus, dataId, md5, from string, t metav1.Time, ready bool, reason, message string) {
+	*statuses = replaceSyncStatus(*statuses, nacosiov1.SyncStatus{
 		DataId:       dataId,
 		LastSyncFrom: from,
 		LastSyncTime: t,
 		Ready:        ready,
+		Reason:       reason,
 		Message:      message,
 		Md5:          md5,
 	})
-	dc.Status.SyncStatuses = syncStatuses
 }
 
-func UpdateSyncStatusIfAbsent(dc *nacosiov1.DynamicConfiguration, dataId, md5, from string, t metav1.Time, ready bool, message string) {
+// UpdateBidirectionalSyncStatus records md5 of both sides, which is the baseline to detect changes in next sync.
+// Md5 is always the md5 of cluster side, same as other sync directions.
+func UpdateBidirectionalSyncStatus(dc *nacosiov1.DynamicConfiguration, dataId, serverMd5, clusterMd5, from string, t metav1.Time, ready, conflict bool, reason, message string) {
+	if dc == nil {
+		return
+	}
+	dc.Status.SyncStatuses = replaceSyncStatus(dc.Status.SyncStatuses, nacosiov1.SyncStatus{
+		DataId:       dataId,
+		LastSyncFrom: from,
+		LastSyncTime: t,
+		Ready:        ready,
+		Reason:       reason,
+		Message:      message,
+		Md5:          clusterMd5,
+		ServerMd5:    serverMd5,
+		ClusterMd5:   clusterMd5,
+		Conflict:     conflict,
+	})
+}
+
+func UpdateSyncStatusIfAbsent(dc *nacosiov1.DynamicConfiguration, dataId, md5, from string, t metav1.Time, ready bool, reason, message string) {
 	if dc == nil {
 		return
 	}
@@ -34,12 +58,53 @@ func UpdateSyncStatusIfAbsent(dc *nacosiov1.DynamicConfiguration, dataId, md5, f
 		LastSyncFrom: from,
 		LastSyncTime: t,
 		Ready:        ready,
+		Reason:       reason,
 		Message:      message,
 		Md5:          md5,
 	})
 	dc.Status.SyncStatuses = syncStatuses
 }
 
+// MarkSyncStatusNotReady marks sync status of dataId as not ready, and keeps md5 recorded at last sync
+func MarkSyncStatusNotReady(dc *nacosiov1.DynamicConfiguration, dataId, reason, message string) {
+	if dc == nil {
+		return
+	}
+	markSyncStatusNotReady(&dc.Status.SyncStatuses, dataId, reason, message)
+}
+
+func markSyncStatusNotReady(statuses *[]nacosiov1.SyncStatus, dataId, reason, message string) {
+	for i := range *statuses {
+		if (*statuses)[i].DataId == dataId {
+			(*statuses)[i].Ready = false
+			(*statuses)[i].Reason = reason
+			(*statuses)[i].Message = message
+			return
+		}
+	}
+	*statuses = append(*statuses, nacosiov1.SyncStatus{
+		DataId:       dataId,
+		LastSyncTime: metav1.Now(),
+		LastSyncFrom: "controller",
+		Reason:       reason,
+		Message:      message,
+	})
+}
+
+// ClearListenFailedSyncStatus marks sync status of dataId as ready again, if it was not ready due to listening failure
+func ClearListenFailedSyncStatus(dc *nacosiov1.DynamicConfiguration, dataId string) {
+	if dc == nil {
+		return
+	}
+	for i := range dc.Status.SyncStatuses {
+		if dc.Status.SyncStatuses[i].DataId == dataId && dc.Status.SyncStatuses[i].Reason == nacosiov1.SyncReasonListenFailed {
+			dc.Status.SyncStatuses[i].Ready = true
+			dc.Status.SyncStatuses[i].Reason = nacosiov1.SyncReasonSynced
+			dc.Status.SyncStatuses[i].Message = ""
+		}
+	}
+}
+
 func RemoveSyncStatus(dc *nacosiov1.DynamicConfiguration, dataId string) {
 	if dc == nil {
 		return
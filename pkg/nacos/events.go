@@ -0,0 +1,60 @@
+package nacos
+
+import (
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of events recorded on DynamicConfiguration
+const (
+	ReasonPublished           = "Published"
+	ReasonPublishFailed       = "PublishFailed"
+	ReasonSkipped             = "Skipped"
+	ReasonDeleted             = "Deleted"
+	ReasonDeleteFailed        = "DeleteFailed"
+	ReasonReadFailed          = "ReadFailed"
+	ReasonStored              = "Stored"
+	ReasonStoreFailed         = "StoreFailed"
+	ReasonServerChangeApplied = "ServerChangeApplied"
+	ReasonServerChangeFailed  = "ServerChangeFailed"
+	ReasonListenFailed        = "ListenFailed"
+	ReasonAuthFailed          = "AuthFailed"
+	ReasonConflict            = "Conflict"
+	ReasonRenderFailed        = "RenderFailed"
+	ReasonInvalidContent      = "InvalidContent"
+	ReasonRolloutTriggered    = "RolloutTriggered"
+	ReasonRolloutFailed       = "RolloutFailed"
+	ReasonDriftDetected       = "DriftDetected"
+	ReasonDriftCorrected      = "DriftCorrected"
+	ReasonRolledBack          = "RolledBack"
+	ReasonRollbackFailed      = "RollbackFailed"
+	ReasonBetaPublished       = "BetaPublished"
+	ReasonBetaFailed          = "BetaFailed"
+	ReasonBetaPromoted        = "BetaPromoted"
+	ReasonBetaStopped         = "BetaStopped"
+	ReasonPlanned             = "Planned"
+	ReasonSuspended           = "Suspended"
+	ReasonResumed             = "Resumed"
+)
+
+// NewNopEventRecorder return an EventRecorder which drops all events
+func NewNopEventRecorder() record.EventRecorder {
+	return &record.FakeRecorder{}
+}
+
+// recordWarning records a warning event of dataId, reason is replaced by AuthFailed if err is caused by authorization
+func recordWarning(recorder record.EventRecorder, obj runtime.Object, reason, dataId string, err error) {
+	if isAuthError(err) {
+		reason = ReasonAuthFailed
+	}
+	recorder.Eventf(obj, v1.EventTypeWarning, reason, "dataId %s: %s", dataId, errorMessage(err))
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return fmt.Sprint(err)
+}
@@ -0,0 +1,140 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// ConfigClient is a config client of ConfigServer in a namespace
+type ConfigClient struct {
+	server    *ConfigServer
+	namespace string
+	// authInfo is the credentials of client, nil if it is created by NewConfigClient
+	authInfo *auth.ConfigClientAuthInfo
+	lock     sync.RWMutex
+	closed   bool
+}
+
+var _ config_client.IConfigClient = &ConfigClient{}
+
+// Namespace returns namespace of the client
+func (c *ConfigClient) Namespace() string {
+	return c.namespace
+}
+
+// Closed returns true if CloseClient is called
+func (c *ConfigClient) Closed() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.closed
+}
+
+// GetConfig returns empty content if config not exist, same as nacos sdk
+func (c *ConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	if err := c.check(OperationGet, param); err != nil {
+		return "", err
+	}
+	content, _ := c.server.get(c.key(param))
+	return content, nil
+}
+
+func (c *ConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	if err := c.check(OperationPublish, param); err != nil {
+		return false, err
+	}
+	if len(param.Content) == 0 {
+		return false, fmt.Errorf("content is required")
+	}
+	var tags []string
+	if len(param.Tag) > 0 {
+		tags = strings.Split(param.Tag, ",")
+	}
+	if len(param.BetaIps) > 0 {
+		c.server.publishBeta(c.key(param), param.Content, strings.Split(param.BetaIps, ","))
+		return true, nil
+	}
+	c.server.publish(c.key(param), param.Content, tags, param.Type)
+	return true, nil
+}
+
+func (c *ConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	if err := c.check(OperationDelete, param); err != nil {
+		return false, err
+	}
+	c.server.delete(c.key(param))
+	return true, nil
+}
+
+// StopBeta stops beta config, like stopping it by open API of nacos server, which nacos sdk doesn't support
+func (c *ConfigClient) StopBeta(group, dataId string) error {
+	param := vo.ConfigParam{Group: group, DataId: dataId}
+	if err := c.check(OperationStopBeta, param); err != nil {
+		return err
+	}
+	c.server.stopBeta(c.key(param))
+	return nil
+}
+
+// ListenConfig registers param.OnChange, which is called in a new goroutine when content changed.
+// Listening again on the same config replaces the listener of the client.
+func (c *ConfigClient) ListenConfig(param vo.ConfigParam) error {
+	if err := c.check(OperationListen, param); err != nil {
+		return err
+	}
+	if param.OnChange == nil {
+		return fmt.Errorf("OnChange is required")
+	}
+	c.server.listen(c, c.key(param), param.OnChange)
+	return nil
+}
+
+// IsListening returns true if the client is listening the config
+func (c *ConfigClient) IsListening(group, dataId string) bool {
+	return c.server.isListening(c, configKey{namespace: c.namespace, group: group, dataId: dataId})
+}
+
+func (c *ConfigClient) CancelListenConfig(param vo.ConfigParam) error {
+	if err := c.check(OperationCancelListen, param); err != nil {
+		return err
+	}
+	c.server.cancelListen(c, c.key(param))
+	return nil
+}
+
+func (c *ConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	if c.Closed() {
+		return nil, fmt.Errorf("client closed")
+	}
+	if err := c.server.getError(c, OperationSearch); err != nil {
+		return nil, err
+	}
+	return c.server.search(c.namespace, param), nil
+}
+
+// CloseClient cancels all listeners of the client, and requests fail after it is closed
+func (c *ConfigClient) CloseClient() {
+	c.lock.Lock()
+	c.closed = true
+	c.lock.Unlock()
+	c.server.cancelAllListen(c)
+}
+
+func (c *ConfigClient) check(op Operation, param vo.ConfigParam) error {
+	if c.Closed() {
+		return fmt.Errorf("client closed")
+	}
+	if len(param.DataId) == 0 || len(param.Group) == 0 {
+		return fmt.Errorf("dataId and group are required")
+	}
+	return c.server.getError(c, op)
+}
+
+func (c *ConfigClient) key(param vo.ConfigParam) configKey {
+	return configKey{namespace: c.namespace, group: param.Group, dataId: param.DataId}
+}
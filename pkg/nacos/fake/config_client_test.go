@@ -0,0 +1,84 @@
+package fake
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConfigClient", func() {
+	var server *ConfigServer
+	var client *ConfigClient
+	BeforeEach(func() {
+		server = NewConfigServer()
+		client = server.NewConfigClient("ns")
+	})
+
+	It("publishes, gets and deletes config in its namespace", func() {
+		Expect(client.PublishConfig(vo.ConfigParam{Group: "g", DataId: "a.yaml", Content: "a: 1", Type: "yaml"})).To(BeTrue())
+		Expect(client.GetConfig(vo.ConfigParam{Group: "g", DataId: "a.yaml"})).To(Equal("a: 1"))
+		Expect(server.Type("ns", "g", "a.yaml")).To(Equal("yaml"))
+		_, exist := server.Get("other", "g", "a.yaml")
+		Expect(exist).To(BeFalse())
+
+		Expect(client.DeleteConfig(vo.ConfigParam{Group: "g", DataId: "a.yaml"})).To(BeTrue())
+		Expect(client.GetConfig(vo.ConfigParam{Group: "g", DataId: "a.yaml"})).To(BeEmpty())
+	})
+
+	It("calls listeners when content changed", func() {
+		changes := make(chan string, 10)
+		Expect(client.ListenConfig(vo.ConfigParam{Group: "g", DataId: "a", OnChange: func(namespace, group, dataId, data string) {
+			changes <- fmt.Sprintf("%s/%s/%s=%s", namespace, group, dataId, data)
+		}})).To(Succeed())
+		Expect(server.ListenerCount("ns", "g", "a")).To(Equal(1))
+
+		server.Publish("ns", "g", "a", "v1")
+		Eventually(changes).Should(Receive(Equal("ns/g/a=v1")))
+		server.Publish("ns", "g", "a", "v1")
+		Consistently(changes, 100*time.Millisecond).ShouldNot(Receive())
+		server.Delete("ns", "g", "a")
+		Eventually(changes).Should(Receive(Equal("ns/g/a=")))
+
+		Expect(client.CancelListenConfig(vo.ConfigParam{Group: "g", DataId: "a"})).To(Succeed())
+		Expect(server.ListenerCount("ns", "g", "a")).To(Equal(0))
+		server.Publish("ns", "g", "a", "v2")
+		Consistently(changes, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("searches configs by blur dataId, group and tags with pagination", func() {
+		server.Publish("ns", "g", "app-order.yaml", "1", "prod")
+		server.Publish("ns", "g", "app-pay.yaml", "2", "dev")
+		server.Publish("ns", "g", "db.yaml", "3", "prod")
+		server.Publish("ns", "other", "app-user.yaml", "4", "prod")
+
+		page, err := client.SearchConfig(vo.SearchConfigParam{Search: "blur", DataId: "app-*", Group: "g"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.TotalCount).To(Equal(2))
+		Expect(page.PageItems[0].DataId).To(Equal("app-order.yaml"))
+
+		page, err = client.SearchConfig(vo.SearchConfigParam{Search: "blur", Tag: "prod", PageNo: 2, PageSize: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.TotalCount).To(Equal(3))
+		Expect(page.PagesAvailable).To(Equal(2))
+		Expect(page.PageItems).To(HaveLen(1))
+		Expect(page.PageItems[0].Group).To(Equal("other"))
+	})
+
+	It("fails requests with injected errors and after closed", func() {
+		server.SetError(OperationPublish, fmt.Errorf("403 forbidden"))
+		_, err := client.PublishConfig(vo.ConfigParam{Group: "g", DataId: "a", Content: "v"})
+		Expect(err).To(MatchError("403 forbidden"))
+		server.SetError(OperationPublish, nil)
+		Expect(client.PublishConfig(vo.ConfigParam{Group: "g", DataId: "a", Content: "v"})).To(BeTrue())
+
+		Expect(client.ListenConfig(vo.ConfigParam{Group: "g", DataId: "a", OnChange: func(_, _, _, _ string) {}})).To(Succeed())
+		client.CloseClient()
+		Expect(client.Closed()).To(BeTrue())
+		Expect(server.ListenerCount("ns", "g", "a")).To(Equal(0))
+		_, err = client.GetConfig(vo.ConfigParam{Group: "g", DataId: "a"})
+		Expect(err).To(HaveOccurred())
+	})
+})
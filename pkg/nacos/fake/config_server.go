@@ -0,0 +1,400 @@
+package fake
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// Operation is a request to nacos server, used to inject errors
+type Operation string
+
+const (
+	OperationGet          Operation = "get"
+	OperationPublish      Operation = "publish"
+	OperationDelete       Operation = "delete"
+	OperationListen       Operation = "listen"
+	OperationCancelListen Operation = "cancelListen"
+	OperationSearch       Operation = "search"
+	OperationStopBeta     Operation = "stopBeta"
+)
+
+type configKey struct {
+	namespace string
+	group     string
+	dataId    string
+}
+
+type config struct {
+	id      int64
+	content string
+	md5     string
+	tags    []string
+	typ     string
+}
+
+type betaConfig struct {
+	content string
+	ips     []string
+}
+
+// ConfigServer is an in-memory nacos config server. Config clients created by it share configs,
+// and listeners are called when content of a listened config is changed or deleted.
+type ConfigServer struct {
+	lock      sync.RWMutex
+	nextId    int64
+	configs   map[configKey]*config
+	betas     map[configKey]*betaConfig
+	listeners map[configKey]map[*ConfigClient]vo.Listener
+	errors    map[Operation]error
+	// credentials are required for clients created by ConfigClientFactory, nil allows any credentials
+	credentials *auth.ConfigClientAuthInfo
+	// clients are all clients created by the server, including closed ones
+	clients []*ConfigClient
+}
+
+func NewConfigServer() *ConfigServer {
+	return &ConfigServer{
+		configs:   map[configKey]*config{},
+		betas:     map[configKey]*betaConfig{},
+		listeners: map[configKey]map[*ConfigClient]vo.Listener{},
+		errors:    map[Operation]error{},
+	}
+}
+
+// ConfigClientFactory returns a factory which creates clients of the server, to be used by auth.NacosAuthManager
+func (s *ConfigServer) ConfigClientFactory() auth.ConfigClientFactory {
+	return func(clientParams *auth.ConfigClientParam) (config_client.IConfigClient, error) {
+		c := s.NewConfigClient(clientParams.Namespace)
+		authInfo := clientParams.AuthInfo
+		c.authInfo = &authInfo
+		return c, nil
+	}
+}
+
+// NewConfigClient returns a client which reads and writes configs of namespace
+func (s *ConfigServer) NewConfigClient(namespace string) *ConfigClient {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	c := &ConfigClient{server: s, namespace: namespace}
+	s.clients = append(s.clients, c)
+	return c
+}
+
+// Clients returns all clients created by the server
+func (s *ConfigServer) Clients() []*ConfigClient {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]*ConfigClient{}, s.clients...)
+}
+
+// SetCredentials makes requests of clients created by ConfigClientFactory fail with 403, unless they are created with
+// the same credentials. Clients created by NewConfigClient are always allowed, nil credentials allow any client.
+func (s *ConfigServer) SetCredentials(credentials *auth.ConfigClientAuthInfo) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.credentials = credentials
+}
+
+// SetError makes all requests of op fail with err, nil err clears it
+func (s *ConfigServer) SetError(op Operation, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err == nil {
+		delete(s.errors, op)
+		return
+	}
+	s.errors[op] = err
+}
+
+// Get returns content of config, like reading it in nacos console
+func (s *ConfigServer) Get(namespace, group, dataId string) (string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	c, ok := s.configs[configKey{namespace: namespace, group: group, dataId: dataId}]
+	if !ok {
+		return "", false
+	}
+	return c.content, true
+}
+
+// Publish creates or updates config, like editing it in nacos console. Listeners are called if content changed.
+func (s *ConfigServer) Publish(namespace, group, dataId, content string, tags ...string) {
+	s.publish(configKey{namespace: namespace, group: group, dataId: dataId}, content, tags, "")
+}
+
+// Beta returns content and IPs of beta config, which is published with betaIps.
+// Like nacos server, beta config is kept after publishing config to all clients, until it is stopped or the config
+// is deleted.
+func (s *ConfigServer) Beta(namespace, group, dataId string) (string, []string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	b, ok := s.betas[configKey{namespace: namespace, group: group, dataId: dataId}]
+	if !ok {
+		return "", nil, false
+	}
+	return b.content, append([]string{}, b.ips...), true
+}
+
+// ContentOf returns content which a client of ip gets, beta content if ip is one of beta IPs
+func (s *ConfigServer) ContentOf(ip, namespace, group, dataId string) (string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	key := configKey{namespace: namespace, group: group, dataId: dataId}
+	if b, ok := s.betas[key]; ok {
+		for _, betaIp := range b.ips {
+			if betaIp == ip {
+				return b.content, true
+			}
+		}
+	}
+	return s.get(key)
+}
+
+// Delete deletes config, like deleting it in nacos console. Listeners are called with empty content.
+func (s *ConfigServer) Delete(namespace, group, dataId string) {
+	s.delete(configKey{namespace: namespace, group: group, dataId: dataId})
+}
+
+// Type returns config type of config, which is set by publishing with type
+func (s *ConfigServer) Type(namespace, group, dataId string) string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if c, ok := s.configs[configKey{namespace: namespace, group: group, dataId: dataId}]; ok {
+		return c.typ
+	}
+	return ""
+}
+
+// Count returns number of configs in namespace
+func (s *ConfigServer) Count(namespace string) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	count := 0
+	for key := range s.configs {
+		if key.namespace == namespace {
+			count++
+		}
+	}
+	return count
+}
+
+// ListenerCount returns number of clients listening the config
+func (s *ConfigServer) ListenerCount(namespace, group, dataId string) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return len(s.listeners[configKey{namespace: namespace, group: group, dataId: dataId}])
+}
+
+// DropListeners forgets all listeners, like subscriptions lost after nacos server restarted
+func (s *ConfigServer) DropListeners() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.listeners = map[configKey]map[*ConfigClient]vo.Listener{}
+}
+
+func (s *ConfigServer) isListening(c *ConfigClient, key configKey) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	_, ok := s.listeners[key][c]
+	return ok
+}
+
+func (s *ConfigServer) getError(c *ConfigClient, op Operation) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.credentials != nil && c.authInfo != nil && *s.credentials != *c.authInfo {
+		return nacos_error.NewNacosError("403", "user not found!", nil)
+	}
+	return s.errors[op]
+}
+
+func (s *ConfigServer) get(key configKey) (string, bool) {
+	return s.Get(key.namespace, key.group, key.dataId)
+}
+
+func (s *ConfigServer) publish(key configKey, content string, tags []string, typ string) {
+	s.lock.Lock()
+	sum := md5.Sum([]byte(content))
+	newMd5 := hex.EncodeToString(sum[:])
+	c, ok := s.configs[key]
+	if !ok {
+		s.nextId++
+		c = &config{id: s.nextId}
+		s.configs[key] = c
+	}
+	changed := !ok || c.md5 != newMd5
+	c.content, c.md5 = content, newMd5
+	if tags != nil {
+		c.tags = tags
+	}
+	if len(typ) > 0 {
+		c.typ = typ
+	}
+	listeners := s.listenersOf(key)
+	s.lock.Unlock()
+	if changed {
+		notify(key, content, listeners)
+	}
+}
+
+// publishBeta publishes config to beta IPs only, listeners are not called since clients are not identified by IP
+func (s *ConfigServer) publishBeta(key configKey, content string, ips []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.betas[key] = &betaConfig{content: content, ips: ips}
+}
+
+// stopBeta removes beta config, so that beta IPs get the same content as other clients
+func (s *ConfigServer) stopBeta(key configKey) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.betas, key)
+}
+
+func (s *ConfigServer) delete(key configKey) {
+	s.lock.Lock()
+	_, ok := s.configs[key]
+	delete(s.configs, key)
+	delete(s.betas, key)
+	listeners := s.listenersOf(key)
+	s.lock.Unlock()
+	if ok {
+		notify(key, "", listeners)
+	}
+}
+
+func (s *ConfigServer) listen(c *ConfigClient, key configKey, listener vo.Listener) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.listeners[key] == nil {
+		s.listeners[key] = map[*ConfigClient]vo.Listener{}
+	}
+	s.listeners[key][c] = listener
+}
+
+func (s *ConfigServer) cancelListen(c *ConfigClient, key configKey) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.listeners[key], c)
+	if len(s.listeners[key]) == 0 {
+		delete(s.listeners, key)
+	}
+}
+
+func (s *ConfigServer) cancelAllListen(c *ConfigClient) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key, listeners := range s.listeners {
+		delete(listeners, c)
+		if len(listeners) == 0 {
+			delete(s.listeners, key)
+		}
+	}
+}
+
+// listenersOf should be called with lock held
+func (s *ConfigServer) listenersOf(key configKey) []vo.Listener {
+	var listeners []vo.Listener
+	for _, l := range s.listeners[key] {
+		listeners = append(listeners, l)
+	}
+	return listeners
+}
+
+func (s *ConfigServer) search(namespace string, param vo.SearchConfigParam) *model.ConfigPage {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	blur := param.Search == "blur"
+	var tags []string
+	if len(param.Tag) > 0 {
+		tags = strings.Split(param.Tag, ",")
+	}
+	var items []model.ConfigItem
+	for key, c := range s.configs {
+		if key.namespace != namespace || !matchSearch(param.DataId, key.dataId, blur) || !matchSearch(param.Group, key.group, blur) {
+			continue
+		}
+		if len(tags) > 0 && !containsAny(c.tags, tags) {
+			continue
+		}
+		items = append(items, model.ConfigItem{
+			Id:      json.Number(strconv.FormatInt(c.id, 10)),
+			DataId:  key.dataId,
+			Group:   key.group,
+			Content: c.content,
+			Md5:     c.md5,
+			Tenant:  key.namespace,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Group != items[j].Group {
+			return items[i].Group < items[j].Group
+		}
+		return items[i].DataId < items[j].DataId
+	})
+	pageNo, pageSize := param.PageNo, param.PageSize
+	if pageNo <= 0 {
+		pageNo = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	page := &model.ConfigPage{
+		TotalCount:     len(items),
+		PageNumber:     pageNo,
+		PagesAvailable: (len(items) + pageSize - 1) / pageSize,
+	}
+	start := (pageNo - 1) * pageSize
+	if start < len(items) {
+		end := start + pageSize
+		if end > len(items) {
+			end = len(items)
+		}
+		page.PageItems = items[start:end]
+	}
+	return page
+}
+
+func notify(key configKey, content string, listeners []vo.Listener) {
+	for _, l := range listeners {
+		go l(key.namespace, key.group, key.dataId, content)
+	}
+}
+
+// matchSearch matches value by pattern like nacos server, '*' matches any characters in blur search
+func matchSearch(pattern, value string, blur bool) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if !blur {
+		return pattern == value
+	}
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(value)
+}
+
+func containsAny(values, items []string) bool {
+	for _, v := range values {
+		for _, item := range items {
+			if v == item {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const fakeAccessToken = "fake-access-token"
+
+// Namespace is a namespace of NamespaceServer
+type Namespace struct {
+	Id          string
+	ShowName    string
+	Description string
+}
+
+// NamespaceServer is an in-memory stand-in of nacos console API for namespaces, and open API to stop beta configs,
+// served by net/http. Configs of a namespace are counted from the ConfigServer, and beta configs are stopped in it. Requests need an access token of login if username is set.
+type NamespaceServer struct {
+	lock       sync.RWMutex
+	configs    *ConfigServer
+	username   string
+	password   string
+	namespaces []Namespace
+	// requests are methods and paths of all received requests, e.g. "DELETE /nacos/v1/console/namespaces"
+	requests []string
+}
+
+// NewNamespaceServer returns a NamespaceServer with the public namespace, configs is optional
+func NewNamespaceServer(configs *ConfigServer, username, password string) *NamespaceServer {
+	return &NamespaceServer{
+		configs:    configs,
+		username:   username,
+		password:   password,
+		namespaces: []Namespace{{Id: "", ShowName: "public"}},
+	}
+}
+
+// Namespace returns the namespace of id, like reading it in nacos console
+func (s *NamespaceServer) Namespace(id string) (Namespace, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, ns := range s.namespaces {
+		if ns.Id == id {
+			return ns, true
+		}
+	}
+	return Namespace{}, false
+}
+
+// Requests returns methods and paths of all received requests
+func (s *NamespaceServer) Requests() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *NamespaceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.lock.Unlock()
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/nacos")
+	if path == "/v1/auth/login" && r.Method == http.MethodPost {
+		s.login(w, r)
+		return
+	}
+	if len(s.username) > 0 && r.Form.Get("accessToken") != fakeAccessToken {
+		http.Error(w, "user not found!", http.StatusForbidden)
+		return
+	}
+	if path == "/v1/cs/configs" && r.Method == http.MethodDelete && r.Form.Get("beta") == "true" {
+		s.stopBeta(w, r.Form.Get("tenant"), r.Form.Get("group"), r.Form.Get("dataId"))
+		return
+	}
+	if path != "/v1/console/namespaces" {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		s.list(w)
+	case http.MethodPost:
+		s.create(w, r.Form.Get("customNamespaceId"), r.Form.Get("namespaceName"), r.Form.Get("namespaceDesc"))
+	case http.MethodPut:
+		s.update(w, r.Form.Get("namespace"), r.Form.Get("namespaceShowName"), r.Form.Get("namespaceDesc"))
+	case http.MethodDelete:
+		s.delete(w, r.Form.Get("namespaceId"))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *NamespaceServer) login(w http.ResponseWriter, r *http.Request) {
+	if r.Form.Get("username") != s.username || r.Form.Get("password") != s.password {
+		http.Error(w, "unknown user!", http.StatusForbidden)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"accessToken": fakeAccessToken, "tokenTtl": 18000, "globalAdmin": true})
+}
+
+func (s *NamespaceServer) list(w http.ResponseWriter) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var data []map[string]interface{}
+	for _, ns := range s.namespaces {
+		typ := 2
+		if len(ns.Id) == 0 {
+			typ = 0
+		}
+		data = append(data, map[string]interface{}{
+			"namespace":         ns.Id,
+			"namespaceShowName": ns.ShowName,
+			"namespaceDesc":     ns.Description,
+			"quota":             200,
+			"configCount":       s.configCount(ns.Id),
+			"type":              typ,
+		})
+	}
+	writeJSON(w, map[string]interface{}{"code": 200, "message": nil, "data": data})
+}
+
+func (s *NamespaceServer) create(w http.ResponseWriter, id, name, desc string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(id) == 0 || len(name) == 0 {
+		http.Error(w, "namespace id and name are required", http.StatusBadRequest)
+		return
+	}
+	for _, ns := range s.namespaces {
+		if ns.Id == id {
+			http.Error(w, "namespaceId ["+id+"] already exist", http.StatusInternalServerError)
+			return
+		}
+	}
+	s.namespaces = append(s.namespaces, Namespace{Id: id, ShowName: name, Description: desc})
+	_, _ = w.Write([]byte("true"))
+}
+
+func (s *NamespaceServer) update(w http.ResponseWriter, id, name, desc string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i := range s.namespaces {
+		if s.namespaces[i].Id == id {
+			s.namespaces[i].ShowName = name
+			s.namespaces[i].Description = desc
+			_, _ = w.Write([]byte("true"))
+			return
+		}
+	}
+	_, _ = w.Write([]byte("false"))
+}
+
+func (s *NamespaceServer) delete(w http.ResponseWriter, id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var namespaces []Namespace
+	for _, ns := range s.namespaces {
+		if len(id) == 0 || ns.Id != id {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	s.namespaces = namespaces
+	_, _ = w.Write([]byte("true"))
+}
+
+func (s *NamespaceServer) stopBeta(w http.ResponseWriter, namespace, group, dataId string) {
+	if s.configs == nil || len(group) == 0 || len(dataId) == 0 {
+		http.Error(w, "invalid param", http.StatusBadRequest)
+		return
+	}
+	s.configs.stopBeta(configKey{namespace: namespace, group: group, dataId: dataId})
+	writeJSON(w, map[string]interface{}{"code": http.StatusOK, "message": "stop beta ok", "data": true})
+}
+
+// configCount should be called with lock held
+func (s *NamespaceServer) configCount(id string) int {
+	if s.configs == nil {
+		return 0
+	}
+	return s.configs.Count(id)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,14 @@
+package fake
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFake(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Fake Nacos Suite")
+}
@@ -0,0 +1,225 @@
+package fake
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// grpcPortOffset is the offset of grpc port to http port of nacos server
+const grpcPortOffset = 1000
+
+// Certificates are PEM encoded certificates and keys of a self-signed CA, a server and a client
+type Certificates struct {
+	CA         []byte
+	ServerCert []byte
+	ServerKey  []byte
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+// NewCertificates issues server and client certificates by a new CA, the server certificate is valid for hosts,
+// and common name of the client certificate is clientName
+func NewCertificates(clientName string, hosts ...string) (*Certificates, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake-nacos-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDer)
+	if err != nil {
+		return nil, err
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "fake-nacos-server"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, h)
+		}
+	}
+	serverCert, serverKey, err := issueCertificate(serverTemplate, ca, caKey)
+	if err != nil {
+		return nil, err
+	}
+	clientCert, clientKey, err := issueCertificate(&x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: clientName},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Certificates{
+		CA:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer}),
+		ServerCert: serverCert,
+		ServerKey:  serverKey,
+		ClientCert: clientCert,
+		ClientKey:  clientKey,
+	}, nil
+}
+
+func issueCertificate(template, ca *x509.Certificate, caKey *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.NotBefore = ca.NotBefore
+	template.NotAfter = ca.NotAfter
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), nil
+}
+
+// TLSServer is a stand-in of nacos server behind TLS, which requires client certificates issued by the CA.
+// It answers login of nacos sdk by https, and accepts TLS handshakes of grpc on the http port plus 1000 without
+// serving grpc, so that tests can check how clients connect it.
+type TLSServer struct {
+	lock sync.RWMutex
+	http *httptest.Server
+	grpc net.Listener
+	// requests are methods and paths of https requests, e.g. "POST /nacos/v1/auth/users/login"
+	requests []string
+	// handshakes are common names of client certificates of grpc connections
+	handshakes []string
+}
+
+// NewTLSServer starts a TLSServer on 127.0.0.1 with certificates issued by NewCertificates
+func NewTLSServer(certs *Certificates) (*TLSServer, error) {
+	serverCert, err := tls.X509KeyPair(certs.ServerCert, certs.ServerKey)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certs.CA) {
+		return nil, fmt.Errorf("invalid CA")
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	httpListener, grpcListener, err := listenPortPair()
+	if err != nil {
+		return nil, err
+	}
+	s := &TLSServer{grpc: grpcListener}
+	s.http = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	s.http.Listener = httpListener
+	s.http.TLS = tlsConfig
+	s.http.StartTLS()
+	grpcConfig := tlsConfig.Clone()
+	grpcConfig.NextProtos = []string{"h2"}
+	go s.acceptGrpc(grpcConfig)
+	return s, nil
+}
+
+// listenPortPair listens a random http port and the grpc port of it
+func listenPortPair() (net.Listener, net.Listener, error) {
+	var lastErr error
+	for i := 0; i < 20; i++ {
+		httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, nil, err
+		}
+		port := httpListener.Addr().(*net.TCPAddr).Port
+		grpcListener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port+grpcPortOffset))
+		if err == nil {
+			return httpListener, grpcListener, nil
+		}
+		httpListener.Close()
+		lastErr = err
+	}
+	return nil, nil, fmt.Errorf("no free port pair for http and grpc: %w", lastErr)
+}
+
+// Addr returns host and http port of the server
+func (s *TLSServer) Addr() string {
+	return s.http.Listener.Addr().String()
+}
+
+// Requests returns methods and paths of all received https requests
+func (s *TLSServer) Requests() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]string{}, s.requests...)
+}
+
+// GrpcHandshakes returns common names of client certificates of all grpc connections
+func (s *TLSServer) GrpcHandshakes() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]string{}, s.handshakes...)
+}
+
+func (s *TLSServer) Close() {
+	s.grpc.Close()
+	s.http.Close()
+}
+
+func (s *TLSServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.lock.Unlock()
+	if r.Method == http.MethodPost && r.URL.Path == "/nacos/v1/auth/users/login" {
+		writeJSON(w, map[string]interface{}{"accessToken": fakeAccessToken, "tokenTtl": 18000, "globalAdmin": false})
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (s *TLSServer) acceptGrpc(config *tls.Config) {
+	for {
+		c, err := s.grpc.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer c.Close()
+			conn := tls.Server(c, config)
+			if err := conn.Handshake(); err != nil {
+				return
+			}
+			if certs := conn.ConnectionState().PeerCertificates; len(certs) > 0 {
+				s.lock.Lock()
+				s.handshakes = append(s.handshakes, certs[0].Subject.CommonName)
+				s.lock.Unlock()
+			}
+		}()
+	}
+}
@@ -0,0 +1,180 @@
+package nacos
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"gopkg.in/yaml.v3"
+)
+
+var yamlLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// ContentFormatError is returned when content can't be parsed in its format
+type ContentFormatError struct {
+	Format nacosiov1.ConfigFormat
+	// Line is the line number where parser failed, 0 if it is unknown
+	Line int
+	Err  error
+}
+
+func (e *ContentFormatError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("invalid %s content at line %d: %s", e.Format, e.Line, e.Err.Error())
+	}
+	return fmt.Sprintf("invalid %s content: %s", e.Format, e.Err.Error())
+}
+
+func (e *ContentFormatError) Unwrap() error {
+	return e.Err
+}
+
+// DetectContentFormat returns explicitType if it is set, or detects format by extension of dataId.
+// Text is returned if the extension is unknown.
+func DetectContentFormat(dataId string, explicitType nacosiov1.ConfigFormat) nacosiov1.ConfigFormat {
+	if len(explicitType) > 0 {
+		return explicitType
+	}
+	switch strings.ToLower(filepath.Ext(dataId)) {
+	case ".yaml", ".yml":
+		return nacosiov1.ConfigFormatYaml
+	case ".json":
+		return nacosiov1.ConfigFormatJson
+	case ".properties":
+		return nacosiov1.ConfigFormatProperties
+	case ".xml":
+		return nacosiov1.ConfigFormatXml
+	case ".html", ".htm":
+		return nacosiov1.ConfigFormatHtml
+	default:
+		return nacosiov1.ConfigFormatText
+	}
+}
+
+// ValidateContent parses content in format, and returns *ContentFormatError if it is invalid.
+// Empty content, text and html are always valid.
+func ValidateContent(format nacosiov1.ConfigFormat, content string) error {
+	if len(strings.TrimSpace(content)) == 0 {
+		return nil
+	}
+	var line int
+	var err error
+	switch format {
+	case nacosiov1.ConfigFormatYaml:
+		line, err = validateYaml(content)
+	case nacosiov1.ConfigFormatJson:
+		line, err = validateJson(content)
+	case nacosiov1.ConfigFormatProperties:
+		line, err = validateProperties(content)
+	case nacosiov1.ConfigFormatXml:
+		line, err = validateXml(content)
+	default:
+		return nil
+	}
+	if err != nil {
+		return &ContentFormatError{Format: format, Line: line, Err: err}
+	}
+	return nil
+}
+
+func validateYaml(content string) (int, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			msg := strings.TrimPrefix(err.Error(), "yaml: ")
+			if m := yamlLineRegexp.FindStringSubmatch(msg); m != nil {
+				line, _ := strconv.Atoi(m[1])
+				msg = strings.TrimPrefix(strings.TrimPrefix(msg, m[0]), ": ")
+				return line, errors.New(msg)
+			}
+			return 0, errors.New(msg)
+		}
+	}
+}
+
+func validateJson(content string) (int, error) {
+	var v interface{}
+	err := json.Unmarshal([]byte(content), &v)
+	if err == nil {
+		return 0, nil
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return lineOfOffset(content, syntaxErr.Offset), err
+	}
+	return 0, err
+}
+
+func validateXml(content string) (int, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return syntaxErr.Line, errors.New(syntaxErr.Msg)
+			}
+			return 0, err
+		}
+	}
+}
+
+// validateProperties checks content in java properties format. Almost any line is a valid property,
+// so only malformed \uxxxx escapes are reported.
+func validateProperties(content string) (int, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	continued := false
+	for i, l := range lines {
+		trimmed := strings.TrimLeft(l, " \t\f")
+		if !continued && (len(trimmed) == 0 || trimmed[0] == '#' || trimmed[0] == '!') {
+			continue
+		}
+		backslashes := 0
+		for j := 0; j < len(trimmed); j++ {
+			if trimmed[j] != '\\' {
+				backslashes = 0
+				continue
+			}
+			backslashes++
+			if backslashes%2 == 1 && j+1 < len(trimmed) && trimmed[j+1] == 'u' {
+				if j+6 > len(trimmed) || !isHex(trimmed[j+2:j+6]) {
+					return i + 1, errors.New("malformed \\uxxxx encoding")
+				}
+			}
+		}
+		continued = backslashes%2 == 1
+	}
+	return 0, nil
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func lineOfOffset(content string, offset int64) int {
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	return bytes.Count([]byte(content[:offset]), []byte("\n")) + 1
+}
@@ -0,0 +1,286 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/console"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// IsGrayEnabled returns true if changed content of entry should be published as beta first
+func IsGrayEnabled(dc *nacosiov1.DynamicConfiguration, entry ConfigEntry) bool {
+	return dc.Spec.Strategy.Gray != nil &&
+		dc.Spec.Strategy.SyncDirection == nacosiov1.Cluster2Server &&
+		entry.SyncPolicy != nacosiov1.IfAbsent
+}
+
+// GetGrayStatus returns gray status of dataId, nil if dataId is never published as beta
+func GetGrayStatus(dc *nacosiov1.DynamicConfiguration, dataId string) *nacosiov1.GrayStatus {
+	return getGrayStatus(dc.Status.GrayStatuses, dataId)
+}
+
+func getGrayStatus(statuses []nacosiov1.GrayStatus, dataId string) *nacosiov1.GrayStatus {
+	for i := range statuses {
+		if statuses[i].DataId == dataId {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// allGrayStatuses returns gray statuses of spec.nacosServer and all targets
+func allGrayStatuses(dc *nacosiov1.DynamicConfiguration) []nacosiov1.GrayStatus {
+	statuses := dc.Status.GrayStatuses
+	for _, target := range dc.Status.TargetStatuses {
+		statuses = append(statuses[:len(statuses):len(statuses)], target.GrayStatuses...)
+	}
+	return statuses
+}
+
+// ResolveBetaIps returns sorted IPs of spec.strategy.gray.betaIps and running pods selected by podSelector
+func ResolveBetaIps(ctx context.Context, c client.Client, dc *nacosiov1.DynamicConfiguration) ([]string, error) {
+	gray := dc.Spec.Strategy.Gray
+	if gray == nil {
+		return nil, nil
+	}
+	ips := map[string]bool{}
+	for _, ip := range gray.BetaIps {
+		ips[ip] = true
+	}
+	if gray.PodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(gray.PodSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid podSelector: %w", err)
+		}
+		podList := v1.PodList{}
+		if err := c.List(ctx, &podList, client.InNamespace(dc.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		for _, pod := range podList.Items {
+			if pod.Status.Phase == v1.PodRunning && len(pod.Status.PodIP) > 0 && pod.DeletionTimestamp == nil {
+				ips[pod.Status.PodIP] = true
+			}
+		}
+	}
+	var result []string
+	for ip := range ips {
+		result = append(result, ip)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// NextPromotion returns duration until the earliest beta config of dc should be promoted, zero means no need to wait
+func NextPromotion(dc *nacosiov1.DynamicConfiguration) time.Duration {
+	gray := dc.Spec.Strategy.Gray
+	if gray == nil || gray.PromoteAfter == nil {
+		return 0
+	}
+	var next time.Duration
+	for _, status := range allGrayStatuses(dc) {
+		if status.Phase != nacosiov1.GrayPhaseBeta {
+			continue
+		}
+		d := gray.PromoteAfter.Duration - time.Since(status.BetaTime.Time)
+		if d < time.Second {
+			d = time.Second
+		}
+		if next == 0 || d < next {
+			next = d
+		}
+	}
+	return next
+}
+
+// isPromotionDue returns true if beta config should be promoted, by annotation or spec.strategy.gray.promoteAfter
+func isPromotionDue(dc *nacosiov1.DynamicConfiguration, status *nacosiov1.GrayStatus) bool {
+	if dc.Annotations[pkg.GrayPromoteAnnotation] == "true" {
+		return true
+	}
+	promoteAfter := dc.Spec.Strategy.Gray.PromoteAfter
+	return promoteAfter != nil && time.Since(status.BetaTime.Time) >= promoteAfter.Duration
+}
+
+// publishBeta publishes content to beta IPs of the destination if it is not published yet, or beta IPs changed.
+// It returns true if the beta content should be promoted to all clients.
+func (scc *SyncConfigurationController) publishBeta(ctx context.Context, dc *nacosiov1.DynamicConfiguration, dest syncDestination, entry ConfigEntry, content string) (bool, error) {
+	l := log.FromContext(ctx)
+	dataId := entry.DataId
+	contentMd5 := CalcMd5(content)
+	betaIps, err := ResolveBetaIps(ctx, scc.Client, dc)
+	if err == nil && len(betaIps) == 0 {
+		err = fmt.Errorf("no beta IPs resolved")
+	}
+	if err != nil {
+		l.Error(err, "resolve beta IPs error")
+		markSyncStatusNotReady(dest.syncStatuses, dataId, nacosiov1.SyncReasonPublishFailed, "resolve beta IPs error: "+err.Error())
+		recordWarning(scc.recorder, dc, ReasonBetaFailed, dataId, dest.wrapError(err))
+		return false, err
+	}
+	status := getGrayStatus(*dest.grayStatuses, dataId)
+	if status != nil && status.Phase == nacosiov1.GrayPhaseBeta && status.Md5 == contentMd5 {
+		if isPromotionDue(dc, status) {
+			return true, nil
+		}
+		if StringSliceEqual(status.BetaIps, betaIps) {
+			return false, nil
+		}
+	}
+	if _, err := dest.configClient.PublishConfig(vo.ConfigParam{
+		DataId:  dataId,
+		Group:   entry.Group,
+		Content: content,
+		Type:    string(entry.Type),
+		BetaIps: strings.Join(betaIps, ","),
+	}); err != nil {
+		l.Error(err, "publish beta config error")
+		markSyncStatusNotReady(dest.syncStatuses, dataId, syncReasonForError(err, nacosiov1.SyncReasonPublishFailed), "publish beta config error: "+err.Error())
+		recordWarning(scc.recorder, dc, ReasonBetaFailed, dataId, dest.wrapError(err))
+		return false, err
+	}
+	l.Info("beta config published", "betaIps", betaIps)
+	betaTime := metav1.Now()
+	if status != nil && status.Phase == nacosiov1.GrayPhaseBeta && status.Md5 == contentMd5 {
+		// only beta IPs changed, promotion is not delayed
+		betaTime = status.BetaTime
+	} else {
+		scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonBetaPublished, "dataId %s published to beta IPs %s of %s", dataId, strings.Join(betaIps, ","), dest)
+	}
+	setGrayStatus(dest.grayStatuses, nacosiov1.GrayStatus{
+		DataId:   dataId,
+		Phase:    nacosiov1.GrayPhaseBeta,
+		Md5:      contentMd5,
+		BetaIps:  betaIps,
+		BetaTime: betaTime,
+	})
+	return false, nil
+}
+
+// promoteBeta stops the beta config of dataId after content is published to all clients of the destination, so that
+// beta IPs get the published content as well. If stopping failed, the dataId is marked not ready and published again
+// in next sync, until the beta config is stopped.
+func (scc *SyncConfigurationController) promoteBeta(ctx context.Context, dc *nacosiov1.DynamicConfiguration, dest syncDestination, entry ConfigEntry) error {
+	status := getGrayStatus(*dest.grayStatuses, entry.DataId)
+	if status == nil || status.Phase != nacosiov1.GrayPhaseBeta {
+		return nil
+	}
+	if err := scc.stopBeta(ctx, dest, entry.Group, entry.DataId); err != nil {
+		log.FromContext(ctx).Error(err, "stop beta config error")
+		status.Message = "stop beta config error: " + err.Error()
+		markSyncStatusNotReady(dest.syncStatuses, entry.DataId, syncReasonForError(err, nacosiov1.SyncReasonPublishFailed), status.Message)
+		recordWarning(scc.recorder, dc, ReasonBetaFailed, entry.DataId, dest.wrapError(err))
+		return err
+	}
+	now := metav1.Now()
+	status.Phase = nacosiov1.GrayPhasePromoted
+	status.PromoteTime = &now
+	status.Message = ""
+	scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonBetaPromoted, "dataId %s promoted to all clients of %s", entry.DataId, dest)
+	return nil
+}
+
+// betaStopper is implemented by config clients which can stop beta configs
+type betaStopper interface {
+	StopBeta(group, dataId string) error
+}
+
+// stopBeta stops beta config of dataId in the destination. Nacos sdk can't stop beta configs, so they are stopped by
+// open API of nacos server, which requires serverAddr of the destination.
+func (scc *SyncConfigurationController) stopBeta(ctx context.Context, dest syncDestination, group, dataId string) error {
+	if stopper, ok := unwrapConfigClient(dest.configClient).(betaStopper); ok {
+		return stopper.StopBeta(group, dataId)
+	}
+	clientParams, err := scc.authProvider.GetNacosClientParams(dest.view)
+	if err != nil {
+		return err
+	}
+	consoleClient, err := console.NewClient(clientParams, nil)
+	if err != nil {
+		return err
+	}
+	return consoleClient.StopBeta(ctx, clientParams.Namespace, group, dataId)
+}
+
+// retainGrayStatuses removes gray statuses of dataIds not synced any more, or all of them if gray is removed.
+// Beta configs not promoted yet are stopped before their statuses are removed, statuses are kept if stopping failed.
+// It returns dataIds failed to stop beta configs.
+func (scc *SyncConfigurationController) retainGrayStatuses(ctx context.Context, dc *nacosiov1.DynamicConfiguration, dest syncDestination) []string {
+	entries := GetConfigEntries(dest.view)
+	var retained []nacosiov1.GrayStatus
+	var errDataIdList []string
+	for _, status := range *dest.grayStatuses {
+		entry := findEntry(entries, status.DataId)
+		if dc.Spec.Strategy.Gray != nil && entry != nil {
+			retained = append(retained, status)
+			continue
+		}
+		if status.Phase != nacosiov1.GrayPhaseBeta {
+			continue
+		}
+		group := dest.view.Spec.NacosServer.Group
+		if entry != nil {
+			group = entry.Group
+		}
+		if err := scc.stopBeta(ctx, dest, group, status.DataId); err != nil {
+			log.FromContext(ctx).Error(err, "stop beta config error", "dataId", status.DataId)
+			recordWarning(scc.recorder, dc, ReasonBetaFailed, status.DataId, dest.wrapError(err))
+			status.Message = "stop beta config error: " + err.Error()
+			retained = append(retained, status)
+			errDataIdList = append(errDataIdList, status.DataId)
+			continue
+		}
+		scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonBetaStopped, "beta config of dataId %s stopped in %s", status.DataId, dest)
+	}
+	*dest.grayStatuses = retained
+	return errDataIdList
+}
+
+func findEntry(entries []ConfigEntry, dataId string) *ConfigEntry {
+	for i := range entries {
+		if entries[i].DataId == dataId {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// removePromotionAnnotation removes the promotion annotation after all beta configs of spec.nacosServer and
+// targets are promoted
+func (scc *SyncConfigurationController) removePromotionAnnotation(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
+	if _, ok := dc.Annotations[pkg.GrayPromoteAnnotation]; !ok {
+		return nil
+	}
+	for _, status := range allGrayStatuses(dc) {
+		if status.Phase == nacosiov1.GrayPhaseBeta {
+			return nil
+		}
+	}
+	// patch a copy, so that status of dc is not overwritten by the response
+	patched := dc.DeepCopy()
+	delete(patched.Annotations, pkg.GrayPromoteAnnotation)
+	if err := scc.Patch(ctx, patched, client.MergeFrom(dc)); err != nil {
+		log.FromContext(ctx).Error(err, "remove promotion annotation error")
+		return err
+	}
+	dc.Annotations = patched.Annotations
+	dc.ResourceVersion = patched.ResourceVersion
+	return nil
+}
+
+func setGrayStatus(statuses *[]nacosiov1.GrayStatus, status nacosiov1.GrayStatus) {
+	if s := getGrayStatus(*statuses, status.DataId); s != nil {
+		*s = status
+		return
+	}
+	*statuses = append(*statuses, status)
+}
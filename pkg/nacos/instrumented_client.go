@@ -0,0 +1,65 @@
+package nacos
+
+import (
+	"github.com/nacos-group/nacos-controller/pkg/metrics"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"time"
+)
+
+const (
+	operationGet     = "get"
+	operationPublish = "publish"
+	operationDelete  = "delete"
+	operationSearch  = "search"
+)
+
+// instrumentedConfigClient records metrics of requests to nacos server
+type instrumentedConfigClient struct {
+	config_client.IConfigClient
+	direction string
+}
+
+func newInstrumentedConfigClient(c config_client.IConfigClient, direction string) config_client.IConfigClient {
+	return &instrumentedConfigClient{
+		IConfigClient: c,
+		direction:     direction,
+	}
+}
+
+func (c *instrumentedConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	start := time.Now()
+	content, err := c.IConfigClient.GetConfig(param)
+	metrics.ObserveNacosRequest(operationGet, c.direction, start, err)
+	return content, err
+}
+
+func (c *instrumentedConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	start := time.Now()
+	ok, err := c.IConfigClient.PublishConfig(param)
+	metrics.ObserveNacosRequest(operationPublish, c.direction, start, err)
+	return ok, err
+}
+
+func (c *instrumentedConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	start := time.Now()
+	ok, err := c.IConfigClient.DeleteConfig(param)
+	metrics.ObserveNacosRequest(operationDelete, c.direction, start, err)
+	return ok, err
+}
+
+func (c *instrumentedConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	start := time.Now()
+	page, err := c.IConfigClient.SearchConfig(param)
+	metrics.ObserveNacosRequest(operationSearch, c.direction, start, err)
+	return page, err
+}
+
+// unwrapConfigClient returns the client wrapped by instrumentedConfigClient, which is shared by DynamicConfigurations
+func unwrapConfigClient(c config_client.IConfigClient) config_client.IConfigClient {
+	if ic, ok := c.(*instrumentedConfigClient); ok {
+		return ic.IConfigClient
+	}
+	return c
+}
@@ -0,0 +1,339 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sync"
+	"sync/atomic"
+)
+
+// listenerChecker is implemented by config clients which can tell whether a config is still listened.
+// Nacos sdk can't tell it, and it listens its cached configs again by itself after reconnecting, so subscriptions on
+// nacos sdk clients are not verified.
+type listenerChecker interface {
+	IsListening(group, dataId string) bool
+}
+
+// clientConfigKey identifies a config listened on a client, which may be shared by subscriptions of several
+// DynamicConfigurations
+type clientConfigKey struct {
+	client config_client.IConfigClient
+	config NacosConfigKey
+}
+
+// ListenerRegistry owns all subscriptions of nacos configs listened by DynamicConfigurations.
+// Every subscription records the client it is listened on, so that it can be verified, moved to a new client
+// and released. DataId2DCMappings is kept in sync with subscriptions, to dispatch change events to DynamicConfigurations.
+type ListenerRegistry struct {
+	mappings      *DataId2DCMappings
+	onChange      func(namespace, group, dataId, content string)
+	subscriptions map[subscriptionKey]*subscription
+	lock          sync.Mutex
+	rehydrated    atomic.Bool
+}
+
+type subscriptionKey struct {
+	dc     types.NamespacedName
+	config NacosConfigKey
+}
+
+// verifyResult is the result of verifying a config listened on a client
+type verifyResult struct {
+	relistened bool
+	err        error
+}
+
+type subscription struct {
+	client config_client.IConfigClient
+	// err is the error of listening again at last verification
+	err error
+}
+
+func NewListenerRegistry(mappings *DataId2DCMappings, onChange func(namespace, group, dataId, content string)) *ListenerRegistry {
+	return &ListenerRegistry{
+		mappings:      mappings,
+		onChange:      onChange,
+		subscriptions: map[subscriptionKey]*subscription{},
+	}
+}
+
+// Listen subscribes the config for dc on client, nothing is done if dc is listening to it on the same client already
+func (r *ListenerRegistry) Listen(dc types.NamespacedName, key NacosConfigKey, c config_client.IConfigClient) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	k := subscriptionKey{dc: dc, config: key}
+	s, ok := r.subscriptions[k]
+	if ok && s.client == c && s.err == nil && r.mappings.HasMapping(key.Namespace, key.Group, key.DataId, dc) {
+		return nil
+	}
+	if err := r.listen(c, key); err != nil {
+		if ok && s.client == c {
+			s.err = err
+		}
+		return err
+	}
+	if ok && s.client != c {
+		r.cancelIfUnused(k, s.client)
+	}
+	r.subscriptions[k] = &subscription{client: c}
+	r.mappings.AddMapping(key.Namespace, key.Group, key.DataId, dc)
+	return nil
+}
+
+// Cancel unsubscribes the config for dc, listening on the client is canceled if no other DynamicConfiguration uses it
+func (r *ListenerRegistry) Cancel(dc types.NamespacedName, key NacosConfigKey) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	k := subscriptionKey{dc: dc, config: key}
+	if s, ok := r.subscriptions[k]; ok {
+		if err := r.cancelIfUnused(k, s.client); err != nil {
+			return err
+		}
+		delete(r.subscriptions, k)
+	}
+	r.mappings.RemoveMapping(key.Namespace, key.Group, key.DataId, dc)
+	return nil
+}
+
+// Forget unsubscribes all configs of dc, errors of canceling are ignored since dc is gone
+func (r *ListenerRegistry) Forget(ctx context.Context, dc types.NamespacedName) {
+	l := log.FromContext(ctx)
+	for _, key := range r.mappings.GetConfigKeys(dc) {
+		if err := r.Cancel(dc, key); err != nil {
+			l.Error(err, "cancel listening dataId error", "key", key.String())
+			r.lock.Lock()
+			delete(r.subscriptions, subscriptionKey{dc: dc, config: key})
+			r.lock.Unlock()
+			r.mappings.RemoveMapping(key.Namespace, key.Group, key.DataId, dc)
+		}
+	}
+}
+
+// Move listens configs of dc on the new client before canceling them on the old one, so no change event is dropped.
+// Duplicated events are ignored by callback due to same md5.
+func (r *ListenerRegistry) Move(ctx context.Context, dc types.NamespacedName, oldClient, newClient config_client.IConfigClient) {
+	l := log.FromContext(ctx)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for k, s := range r.subscriptions {
+		if k.dc != dc || s.client != oldClient {
+			continue
+		}
+		if err := r.listen(newClient, k.config); err != nil {
+			// remove subscription, so that it will be listened again in syncing
+			l.Error(err, "listen dataId on new client error", "key", k.config.String())
+			r.cancelIfUnused(k, oldClient)
+			delete(r.subscriptions, k)
+			r.mappings.RemoveMapping(k.config.Namespace, k.config.Group, k.config.DataId, dc)
+			continue
+		}
+		if err := r.cancelIfUnused(k, oldClient); err != nil {
+			l.Error(err, "cancel listening dataId on old client error", "key", k.config.String())
+		}
+		s.client = newClient
+		s.err = nil
+		l.Info("listener moved to new client", "key", k.config.String())
+	}
+}
+
+// Verify checks every subscription, and listens again if it is missing in its client. Subscriptions on clients which
+// can't be checked are skipped. Subscriptions whose mapping was removed by callback are dropped. Clients are checked
+// and listened outside of lock, so that Listen is not blocked by verification. It returns DynamicConfigurations whose listening
+// state changed.
+func (r *ListenerRegistry) Verify(ctx context.Context) []types.NamespacedName {
+	l := log.FromContext(ctx)
+	checks := map[subscriptionKey]clientConfigKey{}
+	r.lock.Lock()
+	for k, s := range r.subscriptions {
+		if !r.mappings.HasMapping(k.config.Namespace, k.config.Group, k.config.DataId, k.dc) {
+			r.cancelIfUnused(k, s.client)
+			delete(r.subscriptions, k)
+			continue
+		}
+		if _, checkable := s.client.(listenerChecker); checkable {
+			checks[k] = clientConfigKey{client: s.client, config: k.config}
+		}
+	}
+	r.lock.Unlock()
+
+	results := map[clientConfigKey]*verifyResult{}
+	for _, ck := range checks {
+		if _, done := results[ck]; done {
+			continue
+		}
+		result := &verifyResult{}
+		if !ck.client.(listenerChecker).IsListening(ck.config.Group, ck.config.DataId) {
+			if result.err = r.listen(ck.client, ck.config); result.err == nil {
+				result.relistened = true
+				l.Info("missing listener subscribed again", "key", ck.config.String())
+			}
+		}
+		results[ck] = result
+	}
+
+	var changed []types.NamespacedName
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for k, ck := range checks {
+		result := results[ck]
+		// subscription may be moved or canceled meanwhile, the config listened again is canceled if it is unused
+		s, ok := r.subscriptions[k]
+		if !ok || s.client != ck.client {
+			if result.relistened && !r.isUsed(ck) {
+				ck.client.CancelListenConfig(vo.ConfigParam{Group: ck.config.Group, DataId: ck.config.DataId})
+				result.relistened = false
+			}
+			continue
+		}
+		if result.err != nil {
+			l.Error(result.err, "listen dataId again error", "dc", k.dc, "key", k.config.String())
+		}
+		if result.relistened || (result.err == nil) != (s.err == nil) {
+			changed = appendDC(changed, k.dc)
+		}
+		s.err = result.err
+	}
+	return changed
+}
+
+// Release cancels all subscriptions and forgets them, change events are not received until listened again
+func (r *ListenerRegistry) Release(ctx context.Context) {
+	l := log.FromContext(ctx)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for k, s := range r.subscriptions {
+		delete(r.subscriptions, k)
+		if err := r.cancelIfUnused(k, s.client); err != nil {
+			l.Error(err, "cancel listening dataId error", "dc", k.dc, "key", k.config.String())
+		}
+		r.mappings.RemoveMapping(k.config.Namespace, k.config.Group, k.config.DataId, k.dc)
+	}
+	r.rehydrated.Store(false)
+	l.Info("all listeners released")
+}
+
+// Rehydrated returns true if subscriptions are restored from DynamicConfigurations since last release
+func (r *ListenerRegistry) Rehydrated() bool {
+	return r.rehydrated.Load()
+}
+
+func (r *ListenerRegistry) listen(c config_client.IConfigClient, key NacosConfigKey) error {
+	return c.ListenConfig(vo.ConfigParam{
+		Group:    key.Group,
+		DataId:   key.DataId,
+		OnChange: r.onChange,
+	})
+}
+
+// cancelIfUnused cancels listening the config on client, unless another subscription listens to it on the same client.
+// It should be called with lock held.
+func (r *ListenerRegistry) cancelIfUnused(k subscriptionKey, c config_client.IConfigClient) error {
+	for other, s := range r.subscriptions {
+		if other != k && other.config == k.config && s.client == c {
+			return nil
+		}
+	}
+	return c.CancelListenConfig(vo.ConfigParam{
+		Group:  k.config.Group,
+		DataId: k.config.DataId,
+	})
+}
+
+// isUsed returns true if any subscription listens to the config on the client. It should be called with lock held.
+func (r *ListenerRegistry) isUsed(ck clientConfigKey) bool {
+	for k, s := range r.subscriptions {
+		if k.config == ck.config && s.client == ck.client {
+			return true
+		}
+	}
+	return false
+}
+
+func appendDC(dcList []types.NamespacedName, dc types.NamespacedName) []types.NamespacedName {
+	for _, v := range dcList {
+		if v == dc {
+			return dcList
+		}
+	}
+	return append(dcList, dc)
+}
+
+// RunListeners restores subscriptions of all DynamicConfigurations, verifies them periodically, and releases them
+// when ctx is done, e.g. leadership is lost or manager is stopped.
+func (scc *SyncConfigurationController) RunListeners(ctx context.Context) error {
+	if err := scc.RehydrateListeners(ctx); err != nil {
+		return err
+	}
+	if scc.listenerVerifyInterval > 0 {
+		wait.UntilWithContext(ctx, scc.VerifyListeners, scc.listenerVerifyInterval)
+	} else {
+		<-ctx.Done()
+	}
+	scc.listeners.Release(context.Background())
+	return nil
+}
+
+// ListenersRehydrated returns true if subscriptions are restored after started
+func (scc *SyncConfigurationController) ListenersRehydrated() bool {
+	return scc.listeners.Rehydrated()
+}
+
+// RehydrateListeners listens configs of all DynamicConfigurations which need listening, without syncing contents.
+// DynamicConfigurations failed to listen are reported by reconciling, so they don't fail rehydration.
+func (scc *SyncConfigurationController) RehydrateListeners(ctx context.Context) error {
+	l := log.FromContext(ctx)
+	dcList := nacosiov1.DynamicConfigurationList{}
+	if err := scc.List(ctx, &dcList); err != nil {
+		return fmt.Errorf("list DynamicConfiguration error: %w", err)
+	}
+	listened := 0
+	for i := range dcList.Items {
+		dc := &dcList.Items[i]
+		if dc.DeletionTimestamp != nil || dc.Spec.Suspend || !needListening(dc) {
+			continue
+		}
+		nn := types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}
+		configClient, err := scc.getNacosConfigClient(ctx, dc)
+		if err != nil {
+			l.Error(err, "create nacos config client error", "dc", nn)
+			continue
+		}
+		namespace := GetNacosNamespace(dc)
+		for _, entry := range GetConfigEntries(dc) {
+			if entry.SyncPolicy == nacosiov1.IfAbsent {
+				continue
+			}
+			key := NacosConfigKey{Namespace: namespace, Group: entry.Group, DataId: entry.DataId}
+			if err := scc.listeners.Listen(nn, key, unwrapConfigClient(configClient)); err != nil {
+				l.Error(err, "listen dataId error", "dc", nn, "key", key.String())
+				continue
+			}
+			listened++
+		}
+	}
+	scc.listeners.rehydrated.Store(true)
+	l.Info("listeners rehydrated", "dcCount", len(dcList.Items), "listened", listened)
+	return nil
+}
+
+// VerifyListeners listens missing subscriptions again, and reconciles DynamicConfigurations whose listening state
+// changed, so that their Listening condition is updated
+func (scc *SyncConfigurationController) VerifyListeners(ctx context.Context) {
+	for _, nn := range scc.listeners.Verify(ctx) {
+		select {
+		case scc.events <- event.GenericEvent{Object: &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: nn.Namespace, Name: nn.Name},
+		}}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -5,38 +5,66 @@ import (
 	"fmt"
 	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
 	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"strings"
+	"time"
 )
 
 type SyncConfigurationController struct {
 	client.Client
-	mappings                 *DataId2DCMappings
-	locks                    *LockManager
-	authManager              *auth.NacosAuthManager
-	authProvider             auth.NacosAuthProvider
-	server2ClusterCallbackFn func(namespace, group, dataId, content string)
+	mappings               *DataId2DCMappings
+	locks                  *LockManager
+	clients                *ClientTracker
+	listeners              *ListenerRegistry
+	recorder               record.EventRecorder
+	authManager            *auth.NacosAuthManager
+	authProvider           auth.NacosAuthProvider
+	events                 chan event.GenericEvent
+	resyncInterval         time.Duration
+	listenerVerifyInterval time.Duration
 }
 
 type SyncConfigOptions struct {
-	AuthManger   *auth.NacosAuthManager
-	AuthProvider auth.NacosAuthProvider
-	Callback     Server2ClusterCallback
-	Mappings     *DataId2DCMappings
-	Locks        *LockManager
+	AuthManger *auth.NacosAuthManager
+	// ConfigClientFactory creates config clients of a new NacosAuthManager, used if AuthManger is empty
+	ConfigClientFactory auth.ConfigClientFactory
+	AuthProvider        auth.NacosAuthProvider
+	Callback            Server2ClusterCallback
+	Mappings            *DataId2DCMappings
+	Locks               *LockManager
+	// Events receives DynamicConfigurations which should be reconciled again, e.g. server changed in bidirectional mode
+	Events chan event.GenericEvent
+	// EventRecorder records outcomes of syncing as Kubernetes Events of DynamicConfiguration
+	EventRecorder record.EventRecorder
+	// ResyncInterval is the default interval of periodic resync, used if dc.spec.strategy.resyncInterval is empty
+	ResyncInterval time.Duration
+	// ListenerVerifyInterval is the interval of verifying listeners and listening missing ones again,
+	// zero means verification is disabled
+	ListenerVerifyInterval time.Duration
+	// ClientOptions are default options of nacos config clients, used if AuthProvider is empty
+	ClientOptions *nacosiov1.NacosClientOptions
+	// APIReader is the uncached reader of auth and tls Secrets, used if AuthProvider is empty
+	APIReader client.Reader
 }
 
 func NewSyncConfigurationController(c client.Client, opt SyncConfigOptions) *SyncConfigurationController {
 	if opt.AuthProvider == nil {
-		opt.AuthProvider = &auth.DefaultNaocsAuthProvider{Client: c}
+		opt.AuthProvider = &auth.DefaultNaocsAuthProvider{Client: c, APIReader: opt.APIReader, DefaultClientOptions: opt.ClientOptions}
 	}
 	if opt.AuthManger == nil {
-		opt.AuthManger = auth.GetNacosAuthManger()
+		if opt.ConfigClientFactory != nil {
+			opt.AuthManger = auth.NewNacosAuthManager(opt.ConfigClientFactory)
+		} else {
+			opt.AuthManger = auth.GetNacosAuthManger()
+		}
 	}
 	if opt.Mappings == nil {
 		opt.Mappings = NewDataId2DCMappings()
@@ -44,149 +72,366 @@ func NewSyncConfigurationController(c client.Client, opt SyncConfigOptions) *Syn
 	if opt.Locks == nil {
 		opt.Locks = NewLockManager()
 	}
+	if opt.Events == nil {
+		opt.Events = make(chan event.GenericEvent, 1024)
+	}
+	if opt.EventRecorder == nil {
+		opt.EventRecorder = NewNopEventRecorder()
+	}
 	if opt.Callback == nil {
-		opt.Callback = NewDefaultServer2ClusterCallback(c, opt.Mappings, opt.Locks)
+		opt.Callback = NewDefaultServer2ClusterCallback(c, opt.Mappings, opt.Locks, opt.Events, opt.EventRecorder)
 	}
 	return &SyncConfigurationController{
-		Client:                   c,
-		mappings:                 opt.Mappings,
-		locks:                    opt.Locks,
-		authManager:              opt.AuthManger,
-		authProvider:             opt.AuthProvider,
-		server2ClusterCallbackFn: opt.Callback.Callback,
+		Client:                 c,
+		mappings:               opt.Mappings,
+		locks:                  opt.Locks,
+		clients:                NewClientTracker(),
+		listeners:              NewListenerRegistry(opt.Mappings, opt.Callback.Callback),
+		recorder:               opt.EventRecorder,
+		authManager:            opt.AuthManger,
+		authProvider:           opt.AuthProvider,
+		events:                 opt.Events,
+		resyncInterval:         opt.ResyncInterval,
+		listenerVerifyInterval: opt.ListenerVerifyInterval,
 	}
 }
 
+// Events returns the channel of DynamicConfigurations which should be reconciled again
+func (scc *SyncConfigurationController) Events() <-chan event.GenericEvent {
+	return scc.events
+}
+
 func (scc *SyncConfigurationController) SyncDynamicConfiguration(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
 	if dc == nil {
 		return fmt.Errorf("empty DynamicConfiguration")
 	}
+	var err error
 	strategy := dc.Spec.Strategy
+	if WasSuspended(dc) {
+		log.FromContext(ctx).Info("resumed, sync all dataIds again")
+		scc.recorder.Event(dc, v1.EventTypeNormal, ReasonResumed, "syncing resumed")
+	}
+	if strategy.DryRun {
+		err = scc.planSync(ctx, dc)
+		UpdateConditions(dc, err)
+		return err
+	}
+	dc.Status.Plan = nil
+	// LastResyncTime is updated only if resync is due, otherwise status changes and triggers reconciling on every sync
+	resync := scc.isResyncDue(dc)
+	rollbackErr := scc.syncRollback(ctx, dc)
 	switch strategy.SyncDirection {
 	case nacosiov1.Server2Cluster:
-		return scc.syncServer2Cluster(ctx, dc)
+		err = scc.syncServer2Cluster(ctx, dc)
 	case nacosiov1.Cluster2Server:
-		return scc.syncCluster2Server(ctx, dc)
+		err = scc.syncCluster2Server(ctx, dc)
+		// targets are synced even if spec.nacosServer failed
+		if targetErr := scc.syncTargets(ctx, dc); err == nil {
+			err = targetErr
+		}
+		if annotationErr := scc.removePromotionAnnotation(ctx, dc); err == nil {
+			err = annotationErr
+		}
+	case nacosiov1.Bidirectional:
+		err = scc.syncBidirectional(ctx, dc)
 	default:
-		return fmt.Errorf("unsupport sync direction: %s", string(strategy.SyncDirection))
+		err = fmt.Errorf("unsupport sync direction: %s", string(strategy.SyncDirection))
+	}
+	if err == nil {
+		err = rollbackErr
+	}
+	if err == nil && resync && scc.ResyncInterval(dc) > 0 {
+		now := metav1.Now()
+		dc.Status.LastResyncTime = &now
+	}
+	UpdateConditions(dc, err)
+	return err
+}
+
+// ResyncInterval returns interval of periodic resync of dc, zero means resync is disabled
+func (scc *SyncConfigurationController) ResyncInterval(dc *nacosiov1.DynamicConfiguration) time.Duration {
+	if dc.Spec.Strategy.ResyncInterval != nil {
+		return dc.Spec.Strategy.ResyncInterval.Duration
+	}
+	return scc.resyncInterval
+}
+
+// RequeueAfter returns duration until dc should be synced again, for periodic resync, selecting new dataIds,
+// promoting beta configs or retrying failed rollout. Zero means no need to requeue.
+func (scc *SyncConfigurationController) RequeueAfter(dc *nacosiov1.DynamicConfiguration) time.Duration {
+	next := scc.NextResync(dc)
+	if refresh := GetDataIdSelectorRefreshInterval(dc); refresh > 0 && (next == 0 || refresh < next) {
+		next = refresh
+	}
+	if promotion := NextPromotion(dc); promotion > 0 && (next == 0 || promotion < next) {
+		next = promotion
+	}
+	if retry := NextRolloutRetry(dc); retry > 0 && (next == 0 || retry < next) {
+		next = retry
+	}
+	return next
+}
+
+// NextResync returns duration until next periodic resync of dc, zero means resync is disabled
+func (scc *SyncConfigurationController) NextResync(dc *nacosiov1.DynamicConfiguration) time.Duration {
+	interval := scc.ResyncInterval(dc)
+	if interval <= 0 {
+		return 0
+	}
+	if dc.Status.LastResyncTime == nil {
+		return interval
+	}
+	next := interval - time.Since(dc.Status.LastResyncTime.Time)
+	if next < time.Second {
+		return time.Second
 	}
+	return next
+}
+
+func (scc *SyncConfigurationController) isResyncDue(dc *nacosiov1.DynamicConfiguration) bool {
+	// all dataIds are compared with nacos server again after resumed, changes during suspension may be missed
+	if WasSuspended(dc) {
+		return true
+	}
+	interval := scc.ResyncInterval(dc)
+	if interval <= 0 {
+		return false
+	}
+	return dc.Status.LastResyncTime == nil || time.Since(dc.Status.LastResyncTime.Time) >= interval
 }
 
 func (scc *SyncConfigurationController) Finalize(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
 	if dc == nil {
 		return nil
 	}
+	var err error
 	switch dc.Spec.Strategy.SyncDirection {
-	case nacosiov1.Server2Cluster:
-		return scc.finalizeServer2Cluster(ctx, dc)
+	case nacosiov1.Server2Cluster, nacosiov1.Bidirectional:
+		err = scc.finalizeServer2Cluster(ctx, dc)
 	case nacosiov1.Cluster2Server:
-		return scc.finalizeCluster2Server(ctx, dc)
+		err = scc.finalizeCluster2Server(ctx, dc)
 	default:
-		return fmt.Errorf("not support sync direction: " + string(dc.Spec.Strategy.SyncDirection))
+		err = fmt.Errorf("not support sync direction: " + string(dc.Spec.Strategy.SyncDirection))
+	}
+	if err != nil {
+		return err
 	}
+	for _, c := range scc.clients.Untrack(types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}) {
+		scc.closeClientIfUnused(c)
+	}
+	return nil
 }
 
 func (scc *SyncConfigurationController) finalizeServer2Cluster(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
 	nn := types.NamespacedName{Name: dc.Name, Namespace: dc.Namespace}
 	scc.locks.DelLock(nn.String())
-	namespace := dc.Spec.NacosServer.Namespace
-	group := dc.Spec.NacosServer.Group
-	for _, dataId := range dc.Spec.DataIds {
-		scc.mappings.RemoveMapping(namespace, group, dataId, nn)
-	}
+	scc.listeners.Forget(ctx, nn)
 	return nil
 }
 
 func (scc *SyncConfigurationController) finalizeCluster2Server(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
-	if !dc.Spec.Strategy.SyncDeletion {
+	errTargets := scc.finalizeTargets(ctx, dc)
+	var entries []ConfigEntry
+	for _, entry := range GetConfigEntries(dc) {
+		if entry.SyncDeletion {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		if len(errTargets) > 0 {
+			return fmt.Errorf("err targets: %s", strings.Join(errTargets, ","))
+		}
 		return nil
 	}
 	l := log.FromContext(ctx)
-	configClient, err := scc.authManager.GetNacosConfigClient(scc.authProvider, dc)
+	configClient, err := scc.getNacosConfigClient(ctx, dc)
 	if err != nil {
 		return err
 	}
-	group := dc.Spec.NacosServer.Group
 	var errDataIdList []string
-	for _, dataId := range dc.Spec.DataIds {
+	for _, entry := range entries {
+		dataId := entry.DataId
 		_, err := configClient.DeleteConfig(vo.ConfigParam{
-			Group:  group,
+			Group:  entry.Group,
 			DataId: dataId,
 		})
 		if err != nil {
 			l.Error(err, "delete dataId error", "dataId", dataId)
 			errDataIdList = append(errDataIdList, dataId)
-			UpdateSyncStatus(dc, dataId, "", "cluster-finalizer", metav1.Now(), false, err.Error())
+			UpdateSyncStatus(dc, dataId, "", "cluster-finalizer", metav1.Now(), false, syncReasonForError(err, nacosiov1.SyncReasonDeleteFailed), err.Error())
+			recordWarning(scc.recorder, dc, ReasonDeleteFailed, dataId, err)
+			continue
 		}
+		scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonDeleted, "dataId %s deleted in nacos server by finalizer", dataId)
 	}
 	if len(errDataIdList) > 0 {
 		return fmt.Errorf("err dataIds: %s", strings.Join(errDataIdList, ","))
 	}
+	if len(errTargets) > 0 {
+		return fmt.Errorf("err targets: %s", strings.Join(errTargets, ","))
+	}
 	return nil
 }
 
+// syncDestination is a nacos server which dataIds are published to in cluster2server, spec.nacosServer or a target
+type syncDestination struct {
+	// target is the name of target, empty for spec.nacosServer
+	target       string
+	configClient config_client.IConfigClient
+	// view is dc whose nacos server is the destination, groups of dataIds are resolved by it
+	view         *nacosiov1.DynamicConfiguration
+	syncStatuses *[]nacosiov1.SyncStatus
+	grayStatuses *[]nacosiov1.GrayStatus
+}
+
+func (d syncDestination) String() string {
+	if len(d.target) == 0 {
+		return "nacos server"
+	}
+	return "target " + d.target
+}
+
+// wrapError adds the target to err, so that events of targets can be told from spec.nacosServer
+func (d syncDestination) wrapError(err error) error {
+	if len(d.target) == 0 {
+		return err
+	}
+	return fmt.Errorf("target %s: %w", d.target, err)
+}
+
 // syncCluster2Server read DataIds from dc.spec.dataIds， and then read content from dc.spec.objectRef.
 // Compare content from objectRef with nacos server, and update nacos server side depend on dc.spec.strategy.syncPolicy
 func (scc *SyncConfigurationController) syncCluster2Server(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
 	l := log.FromContext(ctx)
-	configClient, err := scc.authManager.GetNacosConfigClient(scc.authProvider, dc)
+	configClient, err := scc.getNacosConfigClient(ctx, dc)
 	if err != nil {
 		l.Error(err, "create nacos config client error")
 		return err
 	}
-
-	objRef := v1.ObjectReference{
-		Namespace:  dc.Namespace,
-		Name:       dc.Spec.ObjectRef.Name,
-		APIVersion: dc.Spec.ObjectRef.APIVersion,
-		Kind:       dc.Spec.ObjectRef.Kind,
-	}
+	objRef := getObjectReference(dc)
 	objWrapper, err := NewObjectReferenceWrapper(scc.Client, dc, &objRef)
 	if err != nil {
 		l.Error(err, "create object wrapper error", "obj", objRef)
 		return err
 	}
-	group := dc.Spec.NacosServer.Group
-	syncFrom := "cluster"
-	l = l.WithValues("group", group, "namespace", dc.Spec.NacosServer.Namespace)
+	ctx = log.IntoContext(ctx, l.WithValues("namespace", GetNacosNamespace(dc)))
+	if err := scc.syncToDestination(ctx, dc, syncDestination{
+		configClient: configClient,
+		view:         dc,
+		syncStatuses: &dc.Status.SyncStatuses,
+		grayStatuses: &dc.Status.GrayStatuses,
+	}, objWrapper); err != nil {
+		return err
+	}
+	dc.Status.ObjectRef = &objRef
+	return nil
+}
+
+// syncToDestination publishes content of dataIds from objWrapper to the destination, depend on dc.spec.strategy.
+// Changed content is published to beta IPs first if gray is enabled, and a rolled back dataId is pinned to the
+// revision of spec.rollback. Results are recorded in sync statuses and gray statuses of the destination.
+func (scc *SyncConfigurationController) syncToDestination(ctx context.Context, dc *nacosiov1.DynamicConfiguration, dest syncDestination, objWrapper ObjectReferenceWrapper) error {
+	l := log.FromContext(ctx)
+	configClient := dest.configClient
+	resync := scc.isResyncDue(dc)
+	renderer := NewContentRenderer(ctx, scc.Client, dc)
 	var errDataIdList []string
-	for _, dataId := range dc.Spec.DataIds {
-		logWithId := l.WithValues("dataId", dataId)
-		content, exist, err := objWrapper.GetContent(dataId)
-		if err != nil {
-			logWithId.Error(err, "read content from object reference error", "objRef", objRef.String())
-			errDataIdList = append(errDataIdList, dataId)
-			continue
+	for _, entry := range GetConfigEntries(dest.view) {
+		dataId, group := entry.DataId, entry.Group
+		logWithId := l.WithValues("dataId", dataId, "group", group)
+		syncFrom := "cluster"
+		var content string
+		var exist bool
+		var err error
+		if IsRolledBack(dc, dataId) {
+			// the destination is pinned to the revision of spec.rollback
+			var rev *nacosiov1.DynamicConfigurationRevision
+			if rev, err = GetRevision(ctx, scc.Client, dc, dataId, dc.Spec.Rollback.Revision); err == nil {
+				content, err = GetRevisionContent(ctx, scc.Client, rev)
+			}
+			if err != nil {
+				logWithId.Error(err, "read revision error")
+				errDataIdList = append(errDataIdList, dataId)
+				markSyncStatusNotReady(dest.syncStatuses, dataId, nacosiov1.SyncReasonRollbackFailed, "read revision error: "+err.Error())
+				continue
+			}
+			exist, syncFrom = true, "rollback"
+		} else {
+			content, exist, err = objWrapper.GetContent(entry.Key)
+			if err != nil {
+				logWithId.Error(err, "read content from object reference error")
+				errDataIdList = append(errDataIdList, dataId)
+				continue
+			}
+			if exist {
+				if content, err = renderer.Render(dataId, content); err != nil {
+					logWithId.Error(err, "render content error")
+					errDataIdList = append(errDataIdList, dataId)
+					updateSyncStatus(dest.syncStatuses, dataId, "", syncFrom, metav1.Now(), false, nacosiov1.SyncReasonRenderFailed, "render content error: "+err.Error())
+					recordWarning(scc.recorder, dc, ReasonRenderFailed, dataId, dest.wrapError(err))
+					continue
+				}
+				if err = ValidateContent(entry.Format, content); err != nil {
+					logWithId.Error(err, "invalid content")
+					errDataIdList = append(errDataIdList, dataId)
+					updateSyncStatus(dest.syncStatuses, dataId, "", syncFrom, metav1.Now(), false, nacosiov1.SyncReasonInvalidContent, err.Error())
+					recordWarning(scc.recorder, dc, ReasonInvalidContent, dataId, dest.wrapError(err))
+					continue
+				}
+			}
 		}
 		contentMd5 := CalcMd5(content)
 		// compare content md5 if it is changed
-		lastSyncStatus := GetSyncStatusByDataId(dc.Status.SyncStatuses, dataId)
-		if lastSyncStatus != nil && lastSyncStatus.Ready {
-			if contentMd5 == lastSyncStatus.Md5 {
-				logWithId.Info("skip syncing, due to same md5 of content", "md5", contentMd5)
+		lastSyncStatus := GetSyncStatusByDataId(*dest.syncStatuses, dataId)
+		// changed content is published to beta IPs first, and published to all clients when promotion is due
+		if exist && syncFrom == "cluster" && IsGrayEnabled(dc, entry) && (lastSyncStatus == nil || lastSyncStatus.Md5 != contentMd5) {
+			promote, err := scc.publishBeta(log.IntoContext(ctx, logWithId), dc, dest, entry, content)
+			if err != nil {
+				errDataIdList = append(errDataIdList, dataId)
 				continue
 			}
+			if !promote {
+				continue
+			}
+		}
+		if lastSyncStatus != nil && (lastSyncStatus.Ready || lastSyncStatus.Reason == nacosiov1.SyncReasonDrifted) {
+			if contentMd5 == lastSyncStatus.Md5 {
+				if !resync || !exist || entry.SyncPolicy == nacosiov1.IfAbsent {
+					logWithId.Info("skip syncing, due to same md5 of content", "md5", contentMd5)
+					continue
+				}
+				drifted, err := scc.detectDrift(log.IntoContext(ctx, logWithId), dc, dest, group, dataId, contentMd5, lastSyncStatus)
+				if err != nil {
+					errDataIdList = append(errDataIdList, dataId)
+					continue
+				}
+				if !drifted || dc.Spec.Strategy.DriftPolicy == nacosiov1.DriftReport {
+					continue
+				}
+			}
 		}
 		if !exist {
 			// If dataId is not exist in cluster, and syncDeletion is true. Then we delete dataId in nacos server.
-			if dc.Spec.Strategy.SyncDeletion {
+			if entry.SyncDeletion {
 				_, err := configClient.DeleteConfig(vo.ConfigParam{
 					Group:  group,
 					DataId: dataId,
 				})
-				logWithId.Info("dataId deleted in nacos server")
 				if err != nil {
 					logWithId.Error(err, "delete dataId error")
 					errDataIdList = append(errDataIdList, dataId)
-					UpdateSyncStatus(dc, dataId, contentMd5, syncFrom, metav1.Now(), false, err.Error())
+					updateSyncStatus(dest.syncStatuses, dataId, contentMd5, syncFrom, metav1.Now(), false, syncReasonForError(err, nacosiov1.SyncReasonDeleteFailed), err.Error())
+					recordWarning(scc.recorder, dc, ReasonDeleteFailed, dataId, dest.wrapError(err))
 					continue
 				}
+				logWithId.Info("dataId deleted in " + dest.String())
+				scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonDeleted, "dataId %s deleted in %s", dataId, dest)
 			}
-			UpdateSyncStatus(dc, dataId, "", syncFrom, metav1.Now(), true, "dataId deleted in cluster")
+			updateSyncStatus(dest.syncStatuses, dataId, "", syncFrom, metav1.Now(), true, nacosiov1.SyncReasonDeleted, "dataId deleted in cluster")
 			continue
 		}
 		// If syncPolicy is IfAbsent, then we check the dataId in nacos server first
-		if dc.Spec.Strategy.SyncPolicy == nacosiov1.IfAbsent {
+		if entry.SyncPolicy == nacosiov1.IfAbsent {
 			conf, err := configClient.GetConfig(vo.ConfigParam{
 				Group:  group,
 				DataId: dataId,
@@ -194,16 +439,19 @@ func (scc *SyncConfigurationController) syncCluster2Server(ctx context.Context,
 			if err != nil {
 				logWithId.Error(err, "get dataId error")
 				errDataIdList = append(errDataIdList, dataId)
-				UpdateSyncStatus(dc, dataId, contentMd5, syncFrom, metav1.Now(), false, err.Error())
+				updateSyncStatus(dest.syncStatuses, dataId, contentMd5, syncFrom, metav1.Now(), false, syncReasonForError(err, nacosiov1.SyncReasonReadFailed), err.Error())
+				recordWarning(scc.recorder, dc, ReasonReadFailed, dataId, dest.wrapError(err))
 				continue
 			}
 			if len(conf) > 0 {
 				logWithId.Info("skip syncing, due to SyncPolicy IfAbsent and server has config already.")
+				scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonSkipped, "dataId %s skipped, due to SyncPolicy IfAbsent and %s has config already", dataId, dest)
+				// md5 of cluster content is recorded, so that the dataId is not checked again until it changes
+				syncTime := metav1.Now()
 				if lastSyncStatus != nil {
-					UpdateSyncStatus(dc, dataId, lastSyncStatus.Md5, lastSyncStatus.LastSyncFrom, lastSyncStatus.LastSyncTime, true, "skipped, due to SyncPolicy IfAbsent")
-				} else {
-					UpdateSyncStatus(dc, dataId, CalcMd5(conf), "cluster", metav1.Now(), true, "skipped, due to SyncPolicy IfAbsent")
+					syncFrom, syncTime = lastSyncStatus.LastSyncFrom, lastSyncStatus.LastSyncTime
 				}
+				updateSyncStatus(dest.syncStatuses, dataId, contentMd5, syncFrom, syncTime, true, nacosiov1.SyncReasonSkipped, "skipped, due to SyncPolicy IfAbsent")
 				continue
 			}
 		}
@@ -211,55 +459,51 @@ func (scc *SyncConfigurationController) syncCluster2Server(ctx context.Context,
 			DataId:  dataId,
 			Group:   group,
 			Content: content,
+			Type:    string(entry.Type),
 		})
 		if err != nil {
 			logWithId.Error(err, "publish config error")
 			errDataIdList = append(errDataIdList, dataId)
-			UpdateSyncStatus(dc, dataId, contentMd5, syncFrom, metav1.Now(), false, err.Error())
+			updateSyncStatus(dest.syncStatuses, dataId, contentMd5, syncFrom, metav1.Now(), false, syncReasonForError(err, nacosiov1.SyncReasonPublishFailed), err.Error())
+			recordWarning(scc.recorder, dc, ReasonPublishFailed, dataId, dest.wrapError(err))
 			continue
 		}
-		logWithId.Info("config published to nacos server")
-		UpdateSyncStatus(dc, dataId, contentMd5, syncFrom, metav1.Now(), true, "")
+		logWithId.Info("config published to " + dest.String())
+		scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonPublished, "dataId %s published to %s", dataId, dest)
+		updateSyncStatus(dest.syncStatuses, dataId, contentMd5, syncFrom, metav1.Now(), true, nacosiov1.SyncReasonSynced, "")
+		if err := scc.promoteBeta(log.IntoContext(ctx, logWithId), dc, dest, entry); err != nil {
+			errDataIdList = append(errDataIdList, dataId)
+		}
+		if syncFrom != "rollback" {
+			// revisions are not recorded again for rollback, otherwise the revision of spec.rollback is renumbered
+			scc.recordRevision(ctx, dc, entry, content, syncFrom)
+		}
 	}
 
-	var removedDataIds []string
-	for _, status := range dc.Status.SyncStatuses {
-		if !StringSliceContains(dc.Spec.DataIds, status.DataId) {
-			removedDataIds = append(removedDataIds, status.DataId)
+	dataIds := GetDataIds(dest.view)
+	var syncStatuses []nacosiov1.SyncStatus
+	for _, status := range *dest.syncStatuses {
+		if StringSliceContains(dataIds, status.DataId) {
+			syncStatuses = append(syncStatuses, status)
 		}
 	}
-	for _, dataId := range removedDataIds {
-		RemoveSyncStatus(dc, dataId)
-	}
+	*dest.syncStatuses = syncStatuses
+	errDataIdList = append(errDataIdList, scc.retainGrayStatuses(ctx, dc, dest)...)
 	if len(errDataIdList) > 0 {
 		return fmt.Errorf("err dataIds: %s", strings.Join(errDataIdList, ","))
 	}
-	dc.Status.ObjectRef = &objRef
 	return nil
 }
 
 func (scc *SyncConfigurationController) syncServer2Cluster(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
 	l := log.FromContext(ctx)
-	configClient, err := scc.authManager.GetNacosConfigClient(scc.authProvider, dc)
+	configClient, err := scc.getNacosConfigClient(ctx, dc)
 	if err != nil {
 		l.Error(err, "create nacos config client error")
 		return err
 	}
 
-	var objectRef v1.ObjectReference
-	if dc.Spec.ObjectRef == nil {
-		// if user doesn't specify objectRef in spec, then generate a configmap with same name
-		apiVersion, kind := ConfigMapGVK.ToAPIVersionAndKind()
-		objectRef = v1.ObjectReference{
-			Namespace:  dc.Namespace,
-			Name:       dc.Name,
-			Kind:       kind,
-			APIVersion: apiVersion,
-		}
-	} else {
-		objectRef = *(dc.Spec.ObjectRef.DeepCopy())
-		objectRef.Namespace = dc.Namespace
-	}
+	objectRef := getObjectReference(dc)
 	dc.Status.ObjectRef = &objectRef
 
 	objWrapper, err := NewObjectReferenceWrapper(scc.Client, dc, &objectRef)
@@ -268,16 +512,31 @@ func (scc *SyncConfigurationController) syncServer2Cluster(ctx context.Context,
 		return err
 	}
 
-	group := dc.Spec.NacosServer.Group
-	namespace := dc.Spec.NacosServer.Namespace
+	namespace := GetNacosNamespace(dc)
+	nn := types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}
 	var errDataIdList []string
-	dataMap := map[string]string{}
 
-	l = l.WithValues("group", group, "namespace", namespace)
+	l = l.WithValues("namespace", namespace)
+	if dc.Spec.DataIdSelector != nil {
+		selected, err := selectDataIds(configClient, dc.Spec.NacosServer.Group, dc.Spec.DataIdSelector)
+		if err != nil {
+			l.Error(err, "select dataIds from server error")
+			return fmt.Errorf("select dataIds from server error: %w", err)
+		}
+		dc.Status.SelectedDataIds = selected
+	} else {
+		dc.Status.SelectedDataIds = nil
+	}
 	anyContentChanged := false
-	syncIfAbsent := dc.Spec.Strategy.SyncPolicy == nacosiov1.IfAbsent
-	for _, dataId := range dc.Spec.DataIds {
-		logWithId := l.WithValues("dataId", dataId)
+	renderer := NewContentRenderer(ctx, scc.Client, dc)
+	for _, entry := range GetConfigEntries(dc) {
+		dataId, group := entry.DataId, entry.Group
+		syncIfAbsent := entry.SyncPolicy == nacosiov1.IfAbsent
+		logWithId := l.WithValues("dataId", dataId, "group", group)
+		if IsRolledBack(dc, dataId) {
+			logWithId.Info("skip syncing, due to rollback")
+			continue
+		}
 		content, err := configClient.GetConfig(vo.ConfigParam{
 			Group:  group,
 			DataId: dataId,
@@ -285,53 +544,59 @@ func (scc *SyncConfigurationController) syncServer2Cluster(ctx context.Context,
 		if err != nil {
 			logWithId.Error(err, "read content from server error")
 			errDataIdList = append(errDataIdList, dataId)
-			UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, "read content from server error: "+err.Error())
+			UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, syncReasonForError(err, nacosiov1.SyncReasonReadFailed), "read content from server error: "+err.Error())
+			recordWarning(scc.recorder, dc, ReasonReadFailed, dataId, err)
+			continue
+		}
+		if content, err = renderer.Render(dataId, content); err != nil {
+			logWithId.Error(err, "render content error")
+			errDataIdList = append(errDataIdList, dataId)
+			UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, nacosiov1.SyncReasonRenderFailed, "render content error: "+err.Error())
+			recordWarning(scc.recorder, dc, ReasonRenderFailed, dataId, err)
 			continue
 		}
-		dataMap[dataId] = content
-		nn := types.NamespacedName{
-			Namespace: dc.Namespace,
-			Name:      dc.Name,
+		if err = ValidateContent(entry.Format, content); err != nil {
+			logWithId.Error(err, "invalid content")
+			errDataIdList = append(errDataIdList, dataId)
+			UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, nacosiov1.SyncReasonInvalidContent, err.Error())
+			recordWarning(scc.recorder, dc, ReasonInvalidContent, dataId, err)
+			continue
 		}
-		oldContent, exist, err := objWrapper.GetContent(dataId)
+		oldContent, exist, err := objWrapper.GetContent(entry.Key)
 		if err != nil {
 			logWithId.Error(err, "read object reference content error")
 			errDataIdList = append(errDataIdList, dataId)
-			UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, "read object reference content error: "+err.Error())
+			UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, nacosiov1.SyncReasonReadFailed, "read object reference content error: "+err.Error())
 			continue
 		}
 		if exist && syncIfAbsent {
 			logWithId.Info("skipped due to sync policy IfAbsent", "dataId", dataId)
+			scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonSkipped, "dataId %s skipped, due to SyncPolicy IfAbsent and cluster has config already", dataId)
 			continue
 		} else if !exist || CalcMd5(oldContent) != CalcMd5(content) {
 			anyContentChanged = true
-			if err := objWrapper.StoreContent(dataId, content); err != nil {
+			if err := objWrapper.StoreContent(entry.Key, content); err != nil {
 				logWithId.Error(err, "store content to object reference error", "content", content, "obj", objectRef)
 				errDataIdList = append(errDataIdList, dataId)
-				UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, "store content to object reference error: "+err.Error())
+				UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, nacosiov1.SyncReasonStoreFailed, "store content to object reference error: "+err.Error())
+				recordWarning(scc.recorder, dc, ReasonStoreFailed, dataId, err)
 				continue
 			}
-			UpdateSyncStatus(dc, dataId, CalcMd5(content), "server", metav1.Now(), true, "")
+			UpdateSyncStatus(dc, dataId, CalcMd5(content), "server", metav1.Now(), true, nacosiov1.SyncReasonSynced, "")
+			scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonStored, "dataId %s stored to %s %s", dataId, objectRef.Kind, objectRef.Name)
+			scc.recordRevision(ctx, dc, entry, content, "server")
+		} else if lastSyncStatus := GetSyncStatusByDataId(dc.Status.SyncStatuses, dataId); lastSyncStatus != nil && !lastSyncStatus.Ready {
+			UpdateSyncStatus(dc, dataId, CalcMd5(content), "server", metav1.Now(), true, nacosiov1.SyncReasonSynced, "")
 		} else {
-			UpdateSyncStatusIfAbsent(dc, dataId, CalcMd5(content), "server", metav1.Now(), true, "skipped due to same md5")
+			UpdateSyncStatusIfAbsent(dc, dataId, CalcMd5(content), "server", metav1.Now(), true, nacosiov1.SyncReasonSynced, "skipped due to same md5")
 		}
 		if syncIfAbsent {
 			scc.mappings.RemoveMapping(namespace, group, dataId, nn)
 			continue
 		}
-		if !scc.mappings.HasMapping(namespace, group, dataId, nn) {
-			err = configClient.ListenConfig(vo.ConfigParam{
-				Group:    group,
-				DataId:   dataId,
-				OnChange: scc.server2ClusterCallbackFn,
-			})
-			if err != nil {
-				logWithId.Error(err, "listen dataId error")
-				errDataIdList = append(errDataIdList, dataId)
-				continue
-			}
-			logWithId.Info("start listening from nacos server")
-			scc.mappings.AddMapping(namespace, group, dataId, nn)
+		if err := scc.listenDataId(ctx, configClient, dc, namespace, group, dataId, nn); err != nil {
+			errDataIdList = append(errDataIdList, dataId)
+			continue
 		}
 	}
 	if anyContentChanged {
@@ -341,31 +606,339 @@ func (scc *SyncConfigurationController) syncServer2Cluster(ctx context.Context,
 		}
 	}
 
+	errDataIdList = append(errDataIdList, scc.cancelListenRemovedDataIds(ctx, dc)...)
+	rolloutErr := RolloutIfNeeded(ctx, scc.Client, dc, anyContentChanged, scc.recorder)
+	if len(errDataIdList) > 0 {
+		return fmt.Errorf("error dataIds: " + strings.Join(errDataIdList, ","))
+	}
+	return rolloutErr
+}
+
+// syncBidirectional compares both sides with the md5 recorded at last sync. The side which changed since last sync
+// is copied to the other side, and dc.spec.strategy.conflictPolicy decides the winner when both sides changed.
+func (scc *SyncConfigurationController) syncBidirectional(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
+	l := log.FromContext(ctx)
+	configClient, err := scc.getNacosConfigClient(ctx, dc)
+	if err != nil {
+		l.Error(err, "create nacos config client error")
+		return err
+	}
+
+	objectRef := getObjectReference(dc)
+	dc.Status.ObjectRef = &objectRef
+	objWrapper, err := NewObjectReferenceWrapper(scc.Client, dc, &objectRef)
+	if err != nil {
+		l.Error(err, "create object wrapper error")
+		return err
+	}
+
+	namespace := GetNacosNamespace(dc)
+	nn := types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}
+	l = l.WithValues("namespace", namespace)
+	var errDataIdList []string
+	anyContentChanged := false
+	for _, entry := range GetConfigEntries(dc) {
+		dataId, group := entry.DataId, entry.Group
+		logWithId := l.WithValues("dataId", dataId, "group", group)
+		if IsRolledBack(dc, dataId) {
+			logWithId.Info("skip syncing, due to rollback")
+			continue
+		}
+		serverContent, err := configClient.GetConfig(vo.ConfigParam{
+			Group:  group,
+			DataId: dataId,
+		})
+		if err != nil {
+			logWithId.Error(err, "read content from server error")
+			errDataIdList = append(errDataIdList, dataId)
+			UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, syncReasonForError(err, nacosiov1.SyncReasonReadFailed), "read content from server error: "+err.Error())
+			recordWarning(scc.recorder, dc, ReasonReadFailed, dataId, err)
+			continue
+		}
+		clusterContent, clusterExist, err := objWrapper.GetContent(entry.Key)
+		if err != nil {
+			logWithId.Error(err, "read object reference content error")
+			errDataIdList = append(errDataIdList, dataId)
+			UpdateSyncStatus(dc, dataId, "", "cluster", metav1.Now(), false, nacosiov1.SyncReasonReadFailed, "read object reference content error: "+err.Error())
+			continue
+		}
+		changed, err := scc.syncBidirectionalDataId(log.IntoContext(ctx, logWithId), configClient, objWrapper, dc, entry, serverContent, clusterContent, clusterExist)
+		if err != nil {
+			errDataIdList = append(errDataIdList, dataId)
+			continue
+		}
+		anyContentChanged = anyContentChanged || changed
+		if err := scc.listenDataId(ctx, configClient, dc, namespace, group, dataId, nn); err != nil {
+			errDataIdList = append(errDataIdList, dataId)
+			continue
+		}
+	}
+	if anyContentChanged {
+		if err := objWrapper.Flush(); err != nil {
+			l.Error(err, "flush object reference error")
+			return err
+		}
+	}
+
+	errDataIdList = append(errDataIdList, scc.cancelListenRemovedDataIds(ctx, dc)...)
+	rolloutErr := RolloutIfNeeded(ctx, scc.Client, dc, anyContentChanged, scc.recorder)
+	if len(errDataIdList) > 0 {
+		return fmt.Errorf("error dataIds: " + strings.Join(errDataIdList, ","))
+	}
+	return rolloutErr
+}
+
+// syncBidirectionalDataId syncs one dataId between server and cluster, return true if content of cluster side changed
+func (scc *SyncConfigurationController) syncBidirectionalDataId(ctx context.Context, configClient config_client.IConfigClient, objWrapper ObjectReferenceWrapper, dc *nacosiov1.DynamicConfiguration, entry ConfigEntry, serverContent, clusterContent string, clusterExist bool) (bool, error) {
+	l := log.FromContext(ctx)
+	dataId, group := entry.DataId, entry.Group
+	serverMd5 := CalcMd5(serverContent)
+	clusterMd5 := CalcMd5(clusterContent)
+	lastSyncStatus := GetSyncStatusByDataId(dc.Status.SyncStatuses, dataId)
+	if serverMd5 == clusterMd5 {
+		if lastSyncStatus == nil || lastSyncStatus.ServerMd5 != serverMd5 || lastSyncStatus.ClusterMd5 != clusterMd5 || !lastSyncStatus.Ready {
+			UpdateBidirectionalSyncStatus(dc, dataId, serverMd5, clusterMd5, "controller", metav1.Now(), true, false, nacosiov1.SyncReasonSynced, "")
+		}
+		return false, nil
+	}
+
+	var serverChanged, clusterChanged bool
+	if lastSyncStatus == nil || (len(lastSyncStatus.ServerMd5) == 0 && len(lastSyncStatus.ClusterMd5) == 0) {
+		// first sync, the side which has no content is treated as unchanged
+		serverChanged = len(serverContent) > 0
+		clusterChanged = clusterExist
+	} else {
+		serverChanged = serverMd5 != lastSyncStatus.ServerMd5
+		clusterChanged = clusterMd5 != lastSyncStatus.ClusterMd5
+	}
+	if !serverChanged && !clusterChanged {
+		return false, nil
+	}
+
+	serverWins := serverChanged
+	if serverChanged && clusterChanged {
+		switch dc.Spec.Strategy.ConflictPolicy {
+		case nacosiov1.ServerWins:
+			l.Info("conflict resolved by policy serverWins")
+		case nacosiov1.ClusterWins:
+			l.Info("conflict resolved by policy clusterWins")
+			serverWins = false
+		default:
+			l.Info("conflict detected, stop syncing until it is resolved manually", "serverMd5", serverMd5, "clusterMd5", clusterMd5)
+			scc.recorder.Eventf(dc, v1.EventTypeWarning, ReasonConflict, "dataId %s conflicted, both server and cluster changed since last sync", dataId)
+			lastServerMd5, lastClusterMd5 := "", ""
+			if lastSyncStatus != nil {
+				lastServerMd5, lastClusterMd5 = lastSyncStatus.ServerMd5, lastSyncStatus.ClusterMd5
+			}
+			UpdateBidirectionalSyncStatus(dc, dataId, lastServerMd5, lastClusterMd5, "controller", metav1.Now(), false, true, nacosiov1.SyncReasonConflict,
+				"conflict: both server and cluster changed since last sync")
+			return false, nil
+		}
+	}
+
+	if serverWins {
+		if len(serverContent) == 0 {
+			if !entry.SyncDeletion {
+				UpdateBidirectionalSyncStatus(dc, dataId, serverMd5, clusterMd5, "server", metav1.Now(), true, false, nacosiov1.SyncReasonSkipped, "dataId deleted in server, skipped due to syncDeletion false")
+				return false, nil
+			}
+			if err := objWrapper.DeleteContent(entry.Key); err != nil {
+				l.Error(err, "delete content of object reference error")
+				UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, nacosiov1.SyncReasonDeleteFailed, "delete content of object reference error: "+err.Error())
+				recordWarning(scc.recorder, dc, ReasonDeleteFailed, dataId, err)
+				return false, err
+			}
+			l.Info("dataId deleted in cluster")
+			scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonDeleted, "dataId %s deleted in cluster", dataId)
+			UpdateBidirectionalSyncStatus(dc, dataId, "", "", "server", metav1.Now(), true, false, nacosiov1.SyncReasonDeleted, "dataId deleted in server")
+			return true, nil
+		}
+		if err := ValidateContent(entry.Format, serverContent); err != nil {
+			l.Error(err, "invalid content in server")
+			MarkSyncStatusNotReady(dc, dataId, nacosiov1.SyncReasonInvalidContent, "content in server: "+err.Error())
+			recordWarning(scc.recorder, dc, ReasonInvalidContent, dataId, err)
+			return false, err
+		}
+		if err := objWrapper.StoreContent(entry.Key, serverContent); err != nil {
+			l.Error(err, "store content to object reference error")
+			UpdateSyncStatus(dc, dataId, "", "server", metav1.Now(), false, nacosiov1.SyncReasonStoreFailed, "store content to object reference error: "+err.Error())
+			recordWarning(scc.recorder, dc, ReasonStoreFailed, dataId, err)
+			return false, err
+		}
+		l.Info("config stored to cluster")
+		scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonStored, "dataId %s stored to cluster", dataId)
+		UpdateBidirectionalSyncStatus(dc, dataId, serverMd5, serverMd5, "server", metav1.Now(), true, false, nacosiov1.SyncReasonSynced, "")
+		scc.recordRevision(ctx, dc, entry, serverContent, "server")
+		return true, nil
+	}
+
+	if !clusterExist {
+		if !entry.SyncDeletion {
+			UpdateBidirectionalSyncStatus(dc, dataId, serverMd5, clusterMd5, "cluster", metav1.Now(), true, false, nacosiov1.SyncReasonSkipped, "dataId deleted in cluster, skipped due to syncDeletion false")
+			return false, nil
+		}
+		if _, err := configClient.DeleteConfig(vo.ConfigParam{
+			Group:  group,
+			DataId: dataId,
+		}); err != nil {
+			l.Error(err, "delete dataId error")
+			UpdateSyncStatus(dc, dataId, clusterMd5, "cluster", metav1.Now(), false, syncReasonForError(err, nacosiov1.SyncReasonDeleteFailed), err.Error())
+			recordWarning(scc.recorder, dc, ReasonDeleteFailed, dataId, err)
+			return false, err
+		}
+		l.Info("dataId deleted in nacos server")
+		scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonDeleted, "dataId %s deleted in nacos server", dataId)
+		UpdateBidirectionalSyncStatus(dc, dataId, "", "", "cluster", metav1.Now(), true, false, nacosiov1.SyncReasonDeleted, "dataId deleted in cluster")
+		return false, nil
+	}
+	if err := ValidateContent(entry.Format, clusterContent); err != nil {
+		l.Error(err, "invalid content in cluster")
+		MarkSyncStatusNotReady(dc, dataId, nacosiov1.SyncReasonInvalidContent, "content in cluster: "+err.Error())
+		recordWarning(scc.recorder, dc, ReasonInvalidContent, dataId, err)
+		return false, err
+	}
+	if _, err := configClient.PublishConfig(vo.ConfigParam{
+		DataId:  dataId,
+		Group:   group,
+		Content: clusterContent,
+		Type:    string(entry.Type),
+	}); err != nil {
+		l.Error(err, "publish config error")
+		UpdateSyncStatus(dc, dataId, clusterMd5, "cluster", metav1.Now(), false, syncReasonForError(err, nacosiov1.SyncReasonPublishFailed), err.Error())
+		recordWarning(scc.recorder, dc, ReasonPublishFailed, dataId, err)
+		return false, err
+	}
+	l.Info("config published to nacos server")
+	scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonPublished, "dataId %s published to nacos server", dataId)
+	UpdateBidirectionalSyncStatus(dc, dataId, clusterMd5, clusterMd5, "cluster", metav1.Now(), true, false, nacosiov1.SyncReasonSynced, "")
+	scc.recordRevision(ctx, dc, entry, clusterContent, "cluster")
+	return false, nil
+}
+
+// detectDrift compares content in the destination with the content synced from cluster at last time.
+// Drift is reported in sync status if driftPolicy is report, otherwise caller should publish the content again.
+func (scc *SyncConfigurationController) detectDrift(ctx context.Context, dc *nacosiov1.DynamicConfiguration, dest syncDestination, group, dataId, contentMd5 string, lastSyncStatus *nacosiov1.SyncStatus) (bool, error) {
+	l := log.FromContext(ctx)
+	serverContent, err := dest.configClient.GetConfig(vo.ConfigParam{
+		Group:  group,
+		DataId: dataId,
+	})
+	if err != nil {
+		l.Error(err, "read content from server error")
+		updateSyncStatus(dest.syncStatuses, dataId, contentMd5, lastSyncStatus.LastSyncFrom, lastSyncStatus.LastSyncTime, false, syncReasonForError(err, nacosiov1.SyncReasonReadFailed), "read content from server error: "+err.Error())
+		recordWarning(scc.recorder, dc, ReasonReadFailed, dataId, dest.wrapError(err))
+		return false, err
+	}
+	serverMd5 := CalcMd5(serverContent)
+	if serverMd5 == contentMd5 {
+		if lastSyncStatus.Reason == nacosiov1.SyncReasonDrifted {
+			updateSyncStatus(dest.syncStatuses, dataId, contentMd5, lastSyncStatus.LastSyncFrom, lastSyncStatus.LastSyncTime, true, nacosiov1.SyncReasonSynced, "")
+		}
+		return false, nil
+	}
+	if dc.Spec.Strategy.DriftPolicy == nacosiov1.DriftReport {
+		l.Info("content in nacos server drifted", "serverMd5", serverMd5, "md5", contentMd5)
+		updateSyncStatus(dest.syncStatuses, dataId, contentMd5, lastSyncStatus.LastSyncFrom, lastSyncStatus.LastSyncTime, false, nacosiov1.SyncReasonDrifted,
+			fmt.Sprintf("content in nacos server drifted, server md5: %s", serverMd5))
+		scc.recorder.Eventf(dc, v1.EventTypeWarning, ReasonDriftDetected, "dataId %s drifted in %s", dataId, dest)
+		return true, nil
+	}
+	l.Info("content in nacos server drifted, publish again", "serverMd5", serverMd5, "md5", contentMd5)
+	scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonDriftCorrected, "dataId %s drifted in %s, publish again", dataId, dest)
+	return true, nil
+}
+
+// listenDataId starts listening dataId from nacos server, if DynamicConfiguration is not listening to it yet
+func (scc *SyncConfigurationController) listenDataId(ctx context.Context, configClient config_client.IConfigClient, dc *nacosiov1.DynamicConfiguration, namespace, group, dataId string, nn types.NamespacedName) error {
+	l := log.FromContext(ctx).WithValues("dataId", dataId)
+	key := NacosConfigKey{Namespace: namespace, Group: group, DataId: dataId}
+	if err := scc.listeners.Listen(nn, key, unwrapConfigClient(configClient)); err != nil {
+		l.Error(err, "listen dataId error")
+		MarkSyncStatusNotReady(dc, dataId, nacosiov1.SyncReasonListenFailed, "listen dataId error: "+err.Error())
+		recordWarning(scc.recorder, dc, ReasonListenFailed, dataId, err)
+		return err
+	}
+	ClearListenFailedSyncStatus(dc, dataId)
+	return nil
+}
+
+// cancelListenRemovedDataIds removes sync status of dataIds which are removed from dc, and stop listening
+// if no DynamicConfiguration listen to it. Return dataIds failed to cancel listening.
+func (scc *SyncConfigurationController) cancelListenRemovedDataIds(ctx context.Context, dc *nacosiov1.DynamicConfiguration) []string {
+	l := log.FromContext(ctx)
+	namespace := GetNacosNamespace(dc)
+	nn := types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}
+	var errDataIdList []string
+	for _, key := range scc.mappings.GetConfigKeys(nn) {
+		if key.Namespace != namespace || FindConfigEntry(dc, key.Group, key.DataId) != nil {
+			continue
+		}
+		if err := scc.listeners.Cancel(nn, key); err != nil {
+			l.Error(err, "cancel listening dataId error", "dataId", key.DataId, "group", key.Group)
+			UpdateSyncStatus(dc, key.DataId, "", "controller", metav1.Now(), false, syncReasonForError(err, nacosiov1.SyncReasonListenFailed), "cancel listening error: "+err.Error())
+			errDataIdList = append(errDataIdList, key.DataId)
+			continue
+		}
+		l.Info("stop listening removed dataId", "dataId", key.DataId, "group", key.Group)
+	}
+	dataIds := GetDataIds(dc)
 	var removedDataIds []string
 	for _, status := range dc.Status.SyncStatuses {
-		if !StringSliceContains(dc.Spec.DataIds, status.DataId) {
+		if !StringSliceContains(dataIds, status.DataId) && !StringSliceContains(errDataIdList, status.DataId) {
 			removedDataIds = append(removedDataIds, status.DataId)
 		}
 	}
 	for _, dataId := range removedDataIds {
-		dcNNList := scc.mappings.GetDCList(namespace, group, dataId)
-		if len(dcNNList) == 0 {
-			l.Info("no DynamicConfiguration listen to this dataId, stop listening from nacos server", "dataId", dataId)
-			if err := configClient.CancelListenConfig(vo.ConfigParam{
-				Group:  group,
-				DataId: dataId,
-			}); err != nil {
-				l.Error(err, "cancel listening dataId error", "dataId", dataId)
-				UpdateSyncStatus(dc, dataId, "", "controller", metav1.Now(), false, "cancel listening error: "+err.Error())
-				errDataIdList = append(errDataIdList, dataId)
-				continue
-			}
-		}
 		RemoveSyncStatus(dc, dataId)
 	}
+	return errDataIdList
+}
 
-	if len(errDataIdList) > 0 {
-		return fmt.Errorf("error dataIds: " + strings.Join(errDataIdList, ","))
+// GetDCListByAuthSecret return DynamicConfigurations which read credentials or tls certificates from the Secret
+func (scc *SyncConfigurationController) GetDCListByAuthSecret(secret types.NamespacedName) []types.NamespacedName {
+	return scc.clients.GetDCListBySecret(secret)
+}
+
+// getNacosConfigClient resolves the nacos server of dc, and records its namespace in status.
+// If the client of dc changed, e.g. credentials or certificates rotated, listeners of dc are moved to the new client.
+func (scc *SyncConfigurationController) getNacosConfigClient(ctx context.Context, dc *nacosiov1.DynamicConfiguration) (config_client.IConfigClient, error) {
+	clientParams, err := scc.authProvider.GetNacosClientParams(dc)
+	if err != nil {
+		scc.recorder.Eventf(dc, v1.EventTypeWarning, ReasonAuthFailed, "resolve nacos server and credentials error: %s", err.Error())
+		return nil, &clientSetupError{reason: ConditionReasonInvalidAuthConfig, err: err}
 	}
-	return nil
+	dc.Status.NacosNamespace = clientParams.Namespace
+	configClient, err := scc.authManager.GetNacosConfigClientByParams(clientParams)
+	if err != nil {
+		return nil, &clientSetupError{reason: ConditionReasonClientCreateFailed, err: err}
+	}
+	nn := types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}
+	if oldClient := scc.clients.Track(nn, configClient, clientParams.SecretRefs()); oldClient != nil {
+		scc.listeners.Move(ctx, nn, oldClient, configClient)
+		scc.closeClientIfUnused(oldClient)
+	}
+	return newInstrumentedConfigClient(configClient, string(dc.Spec.Strategy.SyncDirection)), nil
+}
+
+func (scc *SyncConfigurationController) closeClientIfUnused(configClient config_client.IConfigClient) {
+	if configClient == nil || scc.clients.InUse(configClient) {
+		return
+	}
+	scc.authManager.CloseClient(configClient)
+}
+
+// getObjectReference return dc.spec.objectRef, or a ConfigMap with same name as dc if objectRef is not specified
+func getObjectReference(dc *nacosiov1.DynamicConfiguration) v1.ObjectReference {
+	if dc.Spec.ObjectRef == nil {
+		apiVersion, kind := ConfigMapGVK.ToAPIVersionAndKind()
+		return v1.ObjectReference{
+			Namespace:  dc.Namespace,
+			Name:       dc.Name,
+			Kind:       kind,
+			APIVersion: apiVersion,
+		}
+	}
+	objectRef := *(dc.Spec.ObjectRef.DeepCopy())
+	objectRef.Namespace = dc.Namespace
+	return objectRef
 }
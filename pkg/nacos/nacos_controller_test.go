@@ -0,0 +1,920 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/console"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	testNamespace      = "default"
+	testNacosNamespace = "nacos-ns"
+	testGroup          = "DEFAULT_GROUP"
+)
+
+var _ = Describe("SyncConfigurationController", func() {
+	var ctx context.Context
+	var server *fake.ConfigServer
+	var k8sClient client.Client
+	var controller *SyncConfigurationController
+
+	newDC := func(name string, direction nacosiov1.DynamicConfigurationSyncDirection, dataIds ...string) *nacosiov1.DynamicConfiguration {
+		return &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, UID: types.UID(name)},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				DataIds: dataIds,
+				Strategy: nacosiov1.SyncStrategy{
+					SyncPolicy:    nacosiov1.Always,
+					SyncDirection: direction,
+				},
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String("127.0.0.1:8848"),
+					Namespace:  testNacosNamespace,
+					Group:      testGroup,
+					AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+				},
+				ObjectRef: &v1.ObjectReference{Name: name, APIVersion: "v1", Kind: "ConfigMap"},
+			},
+		}
+	}
+	getServerContent := func(dataId string) string {
+		content, _ := server.Get(testNacosNamespace, testGroup, dataId)
+		return content
+	}
+	getConfigMap := func(name string) *v1.ConfigMap {
+		cm := &v1.ConfigMap{}
+		if err := k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: name}, cm); err != nil {
+			return nil
+		}
+		return cm
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = fake.NewConfigServer()
+		k8sClient = fakeclient.NewClientBuilder().
+			WithScheme(testScheme).
+			WithStatusSubresource(&nacosiov1.DynamicConfiguration{}).
+			WithObjects(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "nacos-auth", Namespace: testNamespace},
+				Data:       map[string][]byte{"ak": []byte("ak"), "sk": []byte("sk")},
+			}).
+			Build()
+		controller = NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+			ConfigClientFactory: server.ConfigClientFactory(),
+		})
+	})
+
+	Describe("cluster2server", func() {
+		It("publishes content of ConfigMap with config type", func() {
+			dc := newDC("c2s", nacosiov1.Cluster2Server)
+			dc.Spec.Configs = []nacosiov1.DataIdConfig{{DataId: "app.yaml", Type: nacosiov1.ConfigFormatYaml}}
+			Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			})).To(Succeed())
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1"))
+			Expect(server.Type(testNacosNamespace, testGroup, "app.yaml")).To(Equal("yaml"))
+			status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.yaml")
+			Expect(status).NotTo(BeNil())
+			Expect(status.Ready).To(BeTrue())
+			Expect(status.Md5).To(Equal(CalcMd5("a: 1")))
+		})
+
+		It("marks dataId with invalid content not ready without publishing it", func() {
+			dc := newDC("c2s-invalid", nacosiov1.Cluster2Server, "app.json", "app.yaml")
+			Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s-invalid", Namespace: testNamespace},
+				Data:       map[string]string{"app.json": "{\n\"a\": 1,\n}", "app.yaml": "a: 1"},
+			})).To(Succeed())
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+			_, exist := server.Get(testNacosNamespace, testGroup, "app.json")
+			Expect(exist).To(BeFalse())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1"))
+			status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.json")
+			Expect(status).NotTo(BeNil())
+			Expect(status.Ready).To(BeFalse())
+			Expect(status.Reason).To(Equal(nacosiov1.SyncReasonInvalidContent))
+			Expect(status.Message).To(ContainSubstring("line 3"))
+		})
+	})
+
+	Describe("server2cluster", func() {
+		It("stores content to ConfigMap and applies changes of nacos server", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.properties", "a=1")
+			dc := newDC("s2c", nacosiov1.Server2Cluster, "app.properties")
+			Expect(k8sClient.Create(ctx, dc)).To(Succeed())
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(k8sClient.Status().Update(ctx, dc)).To(Succeed())
+			Expect(getConfigMap("s2c").Data).To(HaveKeyWithValue("app.properties", "a=1"))
+			Expect(server.ListenerCount(testNacosNamespace, testGroup, "app.properties")).To(Equal(1))
+
+			server.Publish(testNacosNamespace, testGroup, "app.properties", "a=2")
+			Eventually(func() map[string]string {
+				return getConfigMap("s2c").Data
+			}, 5*time.Second, 100*time.Millisecond).Should(HaveKeyWithValue("app.properties", "a=2"))
+
+			Expect(controller.Finalize(ctx, dc)).To(Succeed())
+			Expect(server.ListenerCount(testNacosNamespace, testGroup, "app.properties")).To(Equal(0))
+		})
+
+		It("rolls out workloads when content changed", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: 1")
+			deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: testNamespace, Labels: map[string]string{"app": "demo"}}}
+			Expect(k8sClient.Create(ctx, deploy)).To(Succeed())
+			dc := newDC("s2c-rollout", nacosiov1.Server2Cluster, "app.yaml")
+			dc.Spec.RolloutTargets = []nacosiov1.RolloutTarget{{
+				Kind:     nacosiov1.DeploymentKind,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+			}}
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deploy), deploy)).To(Succeed())
+			Expect(deploy.Spec.Template.Annotations).To(HaveKeyWithValue(ConfigHashAnnotationPrefix+dc.Name, ConfigHash(dc)))
+			Expect(dc.Status.Rollouts).To(HaveLen(1))
+			Expect(dc.Status.Rollouts[0].Name).To(Equal("app"))
+			Expect(dc.Status.Rollouts[0].Ready).To(BeTrue())
+		})
+
+		It("keeps failed rollout status and retries it with backoff", func() {
+			recorder := record.NewFakeRecorder(100)
+			controller = NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+				ConfigClientFactory: server.ConfigClientFactory(),
+				EventRecorder:       recorder,
+			})
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: 1")
+			dc := newDC("s2c-rollout-failed", nacosiov1.Server2Cluster, "app.yaml")
+			dc.Spec.RolloutTargets = []nacosiov1.RolloutTarget{{Kind: nacosiov1.DeploymentKind, Name: "app"}}
+			rolloutFailedEvents := func() int {
+				n := 0
+				for {
+					select {
+					case e := <-recorder.Events:
+						if strings.Contains(e, ReasonRolloutFailed) {
+							n++
+						}
+					default:
+						return n
+					}
+				}
+			}
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+			Expect(dc.Status.Rollouts).To(HaveLen(1))
+			failed := dc.Status.Rollouts[0]
+			Expect(failed.Ready).To(BeFalse())
+			Expect(failed.Failures).To(Equal(int32(1)))
+			Expect(rolloutFailedEvents()).To(Equal(1))
+			Expect(controller.RequeueAfter(dc)).To(BeNumerically("~", 10*time.Second, time.Second))
+
+			// not retried until backoff elapsed
+			err := controller.SyncDynamicConfiguration(ctx, dc)
+			Expect(err).To(MatchError(ContainSubstring("retry in")))
+			Expect(dc.Status.Rollouts[0]).To(Equal(failed))
+
+			// retried by the same error, time of failure is kept without new events
+			dc.Status.Rollouts[0].LastRolloutTime = metav1.NewTime(failed.LastRolloutTime.Add(-10 * time.Second))
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+			Expect(dc.Status.Rollouts[0].Failures).To(Equal(int32(2)))
+			Expect(dc.Status.Rollouts[0].LastRolloutTime.Time).To(Equal(failed.LastRolloutTime.Add(-10 * time.Second)))
+			Expect(rolloutFailedEvents()).To(Equal(0))
+			Expect(controller.RequeueAfter(dc)).To(BeNumerically("~", 20*time.Second, time.Second))
+
+			Expect(k8sClient.Create(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: testNamespace}})).To(Succeed())
+			dc.Status.Rollouts[0].LastRolloutTime = metav1.NewTime(time.Now().Add(-time.Minute))
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(dc.Status.Rollouts[0].Ready).To(BeTrue())
+			Expect(dc.Status.Rollouts[0].Failures).To(BeZero())
+		})
+
+		It("retries only status update when status conflicted after content stored", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.properties", "a=1")
+			dc := newDC("s2c-conflict", nacosiov1.Server2Cluster, "app.properties")
+			Expect(k8sClient.Create(ctx, dc)).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(k8sClient.Status().Update(ctx, dc)).To(Succeed())
+
+			conflicts := 1
+			conflictClient := interceptor.NewClient(k8sClient.(client.WithWatch), interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					if conflicts > 0 {
+						conflicts--
+						return apierrors.NewConflict(nacosiov1.GroupVersion.WithResource("dynamicconfigurations").GroupResource(), obj.GetName(), fmt.Errorf("modified"))
+					}
+					return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+				},
+			})
+			mappings := NewDataId2DCMappings()
+			mappings.AddMapping(testNacosNamespace, testGroup, "app.properties", client.ObjectKeyFromObject(dc))
+			cb := NewDefaultServer2ClusterCallback(conflictClient, mappings, NewLockManager(), nil, nil)
+			cb.Callback(testNacosNamespace, testGroup, "app.properties", "a=2")
+
+			Expect(conflicts).To(BeZero())
+			Expect(getConfigMap("s2c-conflict").Data).To(HaveKeyWithValue("app.properties", "a=2"))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(dc), dc)).To(Succeed())
+			status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.properties")
+			Expect(status).NotTo(BeNil())
+			Expect(status.Md5).To(Equal(CalcMd5("a=2")))
+		})
+	})
+
+	Describe("bidirectional", func() {
+		// syncBoth syncs app.yaml once, then changes it on both sides and syncs again
+		syncBoth := func(name string, policy nacosiov1.DynamicConfigurationConflictPolicy) *nacosiov1.DynamicConfiguration {
+			dc := newDC(name, nacosiov1.Bidirectional, "app.yaml")
+			dc.Spec.Strategy.ConflictPolicy = policy
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1"))
+
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: 2")
+			cm = getConfigMap(name)
+			cm.Data["app.yaml"] = "a: 3"
+			Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			return dc
+		}
+
+		It("detects conflict and stops syncing with manual policy", func() {
+			for _, policy := range []nacosiov1.DynamicConfigurationConflictPolicy{"", nacosiov1.Manual} {
+				dc := syncBoth("bi-manual"+string(policy), policy)
+				status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.yaml")
+				Expect(status).NotTo(BeNil())
+				Expect(status.Conflict).To(BeTrue())
+				Expect(status.Ready).To(BeFalse())
+				Expect(status.Reason).To(Equal(nacosiov1.SyncReasonConflict))
+				Expect(status.ServerMd5).To(Equal(CalcMd5("a: 1")))
+				Expect(getServerContent("app.yaml")).To(Equal("a: 2"))
+				Expect(getConfigMap(dc.Name).Data).To(HaveKeyWithValue("app.yaml", "a: 3"))
+				server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: 1")
+			}
+		})
+
+		It("resolves conflict by serverWins policy", func() {
+			dc := syncBoth("bi-server-wins", nacosiov1.ServerWins)
+			Expect(getServerContent("app.yaml")).To(Equal("a: 2"))
+			Expect(getConfigMap(dc.Name).Data).To(HaveKeyWithValue("app.yaml", "a: 2"))
+			status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.yaml")
+			Expect(status.Conflict).To(BeFalse())
+			Expect(status.Ready).To(BeTrue())
+			Expect(status.LastSyncFrom).To(Equal("server"))
+		})
+
+		It("resolves conflict by clusterWins policy", func() {
+			dc := syncBoth("bi-cluster-wins", nacosiov1.ClusterWins)
+			Expect(getServerContent("app.yaml")).To(Equal("a: 3"))
+			Expect(getConfigMap(dc.Name).Data).To(HaveKeyWithValue("app.yaml", "a: 3"))
+			status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.yaml")
+			Expect(status.Conflict).To(BeFalse())
+			Expect(status.Ready).To(BeTrue())
+			Expect(status.LastSyncFrom).To(Equal("cluster"))
+		})
+
+		It("triggers reconciling when server changed", func() {
+			dc := newDC("bi-listen", nacosiov1.Bidirectional, "app.yaml")
+			Expect(k8sClient.Create(ctx, dc)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "bi-listen", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			})).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(k8sClient.Status().Update(ctx, dc)).To(Succeed())
+
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: 2")
+			Eventually(controller.Events(), 5*time.Second).Should(Receive(WithTransform(func(e event.GenericEvent) string {
+				return e.Object.GetName()
+			}, Equal("bi-listen"))))
+			// the ConfigMap is left to reconciling
+			Expect(getConfigMap("bi-listen").Data).To(HaveKeyWithValue("app.yaml", "a: 1"))
+		})
+	})
+
+	Describe("errors", func() {
+		It("classifies auth errors by status code instead of digits in message", func() {
+			Expect(isAuthError(nacos_error.NewNacosError("403", "access denied", nil))).To(BeTrue())
+			Expect(isAuthError(fmt.Errorf("publish: %w", nacos_error.NewNacosError("401", "", nil)))).To(BeTrue())
+			Expect(isAuthError(nacos_error.NewNacosError("500", "dataId app-403.yaml", nil))).To(BeFalse())
+			Expect(isAuthError(&console.StatusError{StatusCode: 403})).To(BeTrue())
+			Expect(isAuthError(&console.StatusError{StatusCode: 404, Body: "401"})).To(BeFalse())
+			Expect(isAuthError(fmt.Errorf("user not found!"))).To(BeTrue())
+			Expect(isAuthError(fmt.Errorf("invalid content of app-401.yaml, md5 4033"))).To(BeFalse())
+		})
+	})
+
+	Describe("revision history", func() {
+		It("records synced contents and rolls back to a revision", func() {
+			dc := newDC("c2s-rollback", nacosiov1.Cluster2Server, "app.yaml")
+			dc.Spec.RevisionHistoryLimit = pointer.Int32(2)
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s-rollback", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+			for _, content := range []string{"a: 1", "a: 2", "a: 3"} {
+				cm = getConfigMap(cm.Name)
+				cm.Data["app.yaml"] = content
+				Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+				Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			}
+			revisions, err := ListRevisions(ctx, k8sClient, dc, "app.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revisions).To(HaveLen(2))
+			Expect(revisions[0].Revision).To(Equal(int64(2)))
+			Expect(revisions[0].Content).To(Equal("a: 2"))
+			Expect(revisions[1].Revision).To(Equal(int64(3)))
+
+			dc.Spec.Rollback = &nacosiov1.RollbackSpec{DataId: "app.yaml", Revision: 2}
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 2"))
+			Expect(dc.Status.LastRollback).NotTo(BeNil())
+			Expect(dc.Status.LastRollback.Ready).To(BeTrue())
+			Expect(dc.Status.LastRollback.Md5).To(Equal(CalcMd5("a: 2")))
+			status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.yaml")
+			Expect(status.Reason).To(Equal(nacosiov1.SyncReasonRolledBack))
+
+			cm = getConfigMap(cm.Name)
+			cm.Data["app.yaml"] = "a: 4"
+			Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 2"))
+
+			dc.Spec.Rollback = nil
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 4"))
+		})
+
+		It("stores sensitive contents of revisions in Secrets", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "password: 1")
+			dc := newDC("s2c-secret-rollback", nacosiov1.Server2Cluster, "app.yaml")
+			dc.Spec.ObjectRef.Kind = "Secret"
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "password: 2")
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+
+			revisions, err := ListRevisions(ctx, k8sClient, dc, "app.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revisions).To(HaveLen(2))
+			for _, rev := range revisions {
+				Expect(rev.Content).To(BeEmpty())
+				Expect(rev.ContentSecretRef).NotTo(BeNil())
+				secret := &v1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: rev.ContentSecretRef.Name}, secret)).To(Succeed())
+				Expect(secret.OwnerReferences).To(HaveLen(1))
+				Expect(secret.OwnerReferences[0].Name).To(Equal(rev.Name))
+			}
+			content, err := GetRevisionContent(ctx, k8sClient, &revisions[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content).To(Equal("password: 1"))
+
+			dc.Spec.Rollback = &nacosiov1.RollbackSpec{DataId: "app.yaml", Revision: 1}
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			secret := &v1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: dc.Name}, secret)).To(Succeed())
+			Expect(string(secret.Data["app.yaml"])).To(Equal("password: 1"))
+
+			templated := newDC("c2s-secret-template", nacosiov1.Cluster2Server, "app.yaml")
+			templated.Spec.Template = &nacosiov1.ContentTemplate{
+				ValuesFrom: []nacosiov1.TemplateValuesSource{{SecretRef: &v1.LocalObjectReference{Name: "nacos-auth"}}},
+			}
+			Expect(IsSensitiveContent(templated, "app.yaml")).To(BeTrue())
+			templated.Spec.Template.ValuesFrom = nil
+			Expect(IsSensitiveContent(templated, "app.yaml")).To(BeFalse())
+		})
+
+		It("reports rollback to a missing revision", func() {
+			dc := newDC("c2s-rollback-missing", nacosiov1.Cluster2Server, "app.yaml")
+			dc.Spec.Rollback = &nacosiov1.RollbackSpec{DataId: "app.yaml", Revision: 1}
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+			Expect(dc.Status.LastRollback).NotTo(BeNil())
+			Expect(dc.Status.LastRollback.Ready).To(BeFalse())
+			Expect(dc.Status.LastRollback.Message).To(ContainSubstring("not found"))
+		})
+	})
+
+	Describe("gray", func() {
+		It("publishes beta configs and promotes them by annotation or after delay", func() {
+			Expect(k8sClient.Create(ctx, &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: testNamespace, Labels: map[string]string{"app": "demo"}},
+				Status:     v1.PodStatus{Phase: v1.PodRunning, PodIP: "10.0.0.2"},
+			})).To(Succeed())
+			dc := newDC("c2s-gray", nacosiov1.Cluster2Server, "app.yaml")
+			dc.Spec.Strategy.Gray = &nacosiov1.GrayStrategy{
+				BetaIps:     []string{"10.0.0.1"},
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+			}
+			Expect(k8sClient.Create(ctx, dc)).To(Succeed())
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s-gray", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			_, exist := server.Get(testNacosNamespace, testGroup, "app.yaml")
+			Expect(exist).To(BeFalse())
+			beta, ips, _ := server.Beta(testNacosNamespace, testGroup, "app.yaml")
+			Expect(beta).To(Equal("a: 1"))
+			Expect(ips).To(Equal([]string{"10.0.0.1", "10.0.0.2"}))
+			status := GetGrayStatus(dc, "app.yaml")
+			Expect(status).NotTo(BeNil())
+			Expect(status.Phase).To(Equal(nacosiov1.GrayPhaseBeta))
+
+			dc.Annotations = map[string]string{pkg.GrayPromoteAnnotation: "true"}
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1"))
+			Expect(GetGrayStatus(dc, "app.yaml").Phase).To(Equal(nacosiov1.GrayPhasePromoted))
+			Expect(dc.Annotations).NotTo(HaveKey(pkg.GrayPromoteAnnotation))
+
+			dc.Spec.Strategy.Gray.PromoteAfter = &metav1.Duration{Duration: 50 * time.Millisecond}
+			cm = getConfigMap(cm.Name)
+			cm.Data["app.yaml"] = "a: 2"
+			Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1"))
+			Expect(controller.RequeueAfter(dc)).To(BeNumerically(">", 0))
+			time.Sleep(100 * time.Millisecond)
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 2"))
+			Expect(GetGrayStatus(dc, "app.yaml").Phase).To(Equal(nacosiov1.GrayPhasePromoted))
+		})
+
+		It("stops beta configs when promoted or gray is removed", func() {
+			dc := newDC("c2s-gray-stop", nacosiov1.Cluster2Server, "app.yaml")
+			dc.Spec.Strategy.Gray = &nacosiov1.GrayStrategy{BetaIps: []string{"10.0.0.1"}}
+			Expect(k8sClient.Create(ctx, dc)).To(Succeed())
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s-gray-stop", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+			contentOf := func(ip string) string {
+				content, _ := server.ContentOf(ip, testNacosNamespace, testGroup, "app.yaml")
+				return content
+			}
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(contentOf("10.0.0.1")).To(Equal("a: 1"))
+			Expect(contentOf("10.0.0.2")).To(BeEmpty())
+
+			server.SetError(fake.OperationStopBeta, fmt.Errorf("stop beta failed"))
+			dc.Annotations = map[string]string{pkg.GrayPromoteAnnotation: "true"}
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+			Expect(contentOf("10.0.0.2")).To(Equal("a: 1"))
+			Expect(GetGrayStatus(dc, "app.yaml").Phase).To(Equal(nacosiov1.GrayPhaseBeta))
+			Expect(GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.yaml").Ready).To(BeFalse())
+
+			server.SetError(fake.OperationStopBeta, nil)
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(GetGrayStatus(dc, "app.yaml").Phase).To(Equal(nacosiov1.GrayPhasePromoted))
+			_, _, exist := server.Beta(testNacosNamespace, testGroup, "app.yaml")
+			Expect(exist).To(BeFalse())
+
+			cm = getConfigMap(cm.Name)
+			cm.Data["app.yaml"] = "a: 2"
+			Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(contentOf("10.0.0.1")).To(Equal("a: 2"))
+			Expect(contentOf("10.0.0.2")).To(Equal("a: 1"))
+
+			// content is reverted before promotion, beta IPs get the published content after gray is removed
+			cm = getConfigMap(cm.Name)
+			cm.Data["app.yaml"] = "a: 1"
+			Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+			dc.Spec.Strategy.Gray = nil
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(contentOf("10.0.0.1")).To(Equal("a: 1"))
+			Expect(contentOf("10.0.0.2")).To(Equal("a: 1"))
+			Expect(dc.Status.GrayStatuses).To(BeEmpty())
+		})
+	})
+
+	Describe("dry run", func() {
+		planned := func(dc *nacosiov1.DynamicConfiguration) map[string]nacosiov1.PlannedChange {
+			changes := map[string]nacosiov1.PlannedChange{}
+			for _, change := range dc.Status.Plan.Changes {
+				if len(change.Target) == 0 {
+					changes[change.DataId] = change
+				}
+			}
+			return changes
+		}
+
+		It("plans cluster2server changes without writing nacos server", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: 1\nb: 2")
+			server.Publish(testNacosNamespace, testGroup, "same.yaml", "a: 1")
+			server.Publish(testNacosNamespace, testGroup, "old.yaml", "a: 1")
+			dc := newDC("c2s-dry-run", nacosiov1.Cluster2Server, "app.yaml", "same.yaml", "old.yaml", "new.yaml")
+			dc.Spec.Strategy.SyncDeletion = true
+			dc.Spec.Strategy.DryRun = true
+			Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s-dry-run", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1\nb: 3\nc: 4", "same.yaml": "a: 1", "new.yaml": "x: 1"},
+			})).To(Succeed())
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			changes := planned(dc)
+			Expect(changes["app.yaml"].Action).To(Equal(nacosiov1.PlanActionUpdate))
+			Expect(changes["app.yaml"].Diff).To(Equal("+2 -1 lines"))
+			Expect(changes["same.yaml"].Action).To(Equal(nacosiov1.PlanActionSkip))
+			Expect(changes["old.yaml"].Action).To(Equal(nacosiov1.PlanActionDelete))
+			Expect(changes["new.yaml"].Action).To(Equal(nacosiov1.PlanActionCreate))
+			Expect(dc.Status.Plan.Summary).To(Equal("1 to create, 1 to update, 1 to delete, 1 to skip"))
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1\nb: 2"))
+			Expect(getServerContent("old.yaml")).To(Equal("a: 1"))
+			_, exist := server.Get(testNacosNamespace, testGroup, "new.yaml")
+			Expect(exist).To(BeFalse())
+			Expect(dc.Status.SyncStatuses).To(BeEmpty())
+		})
+
+		It("keeps the plan and records events only when changes are different", func() {
+			recorder := record.NewFakeRecorder(100)
+			controller = NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+				ConfigClientFactory: server.ConfigClientFactory(),
+				EventRecorder:       recorder,
+			})
+			dc := newDC("c2s-dry-run-stable", nacosiov1.Cluster2Server, "app.yaml")
+			dc.Spec.Strategy.DryRun = true
+			dc.Spec.Targets = []nacosiov1.SyncTarget{{
+				Name: "staging",
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String("127.0.0.1:8848"),
+					Namespace:  "staging",
+					AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+				},
+			}}
+			server.Publish("staging", testGroup, "app.yaml", "a: 1")
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s-dry-run-stable", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(dc.Status.Plan.Changes).To(ConsistOf(
+				nacosiov1.PlannedChange{DataId: "app.yaml", Action: nacosiov1.PlanActionCreate, Diff: "+1 -0 lines"},
+				nacosiov1.PlannedChange{DataId: "app.yaml", Target: "staging", Action: nacosiov1.PlanActionSkip, Message: "same content"},
+			))
+			Expect(recorder.Events).To(HaveLen(1))
+			<-recorder.Events
+			plan := dc.Status.Plan.DeepCopy()
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(dc.Status.Plan).To(Equal(plan))
+			Expect(recorder.Events).To(BeEmpty())
+
+			cm = getConfigMap(cm.Name)
+			cm.Data["app.yaml"] = "a: 2"
+			Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(dc.Status.Plan.Changes).To(ContainElement(
+				nacosiov1.PlannedChange{DataId: "app.yaml", Target: "staging", Action: nacosiov1.PlanActionUpdate, Diff: "+1 -1 lines"}))
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).NotTo(ContainSubstring("target"))
+			Expect(<-recorder.Events).To(ContainSubstring("of target staging"))
+			content, _ := server.Get("staging", testGroup, "app.yaml")
+			Expect(content).To(Equal("a: 1"))
+		})
+
+		It("plans server2cluster changes without creating ConfigMap or listening", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.properties", "a=1")
+			dc := newDC("s2c-dry-run", nacosiov1.Server2Cluster, "app.properties")
+			dc.Spec.Strategy.DryRun = true
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(planned(dc)["app.properties"].Action).To(Equal(nacosiov1.PlanActionCreate))
+			Expect(getConfigMap("s2c-dry-run")).To(BeNil())
+			Expect(server.ListenerCount(testNacosNamespace, testGroup, "app.properties")).To(Equal(0))
+
+			dc.Spec.Strategy.DryRun = false
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(dc.Status.Plan).To(BeNil())
+			Expect(getConfigMap("s2c-dry-run").Data).To(HaveKeyWithValue("app.properties", "a=1"))
+		})
+	})
+
+	Describe("resync", func() {
+		// syncDrifted syncs app.yaml, changes it in nacos server behind the controller and resyncs after interval
+		syncDrifted := func(name string, policy nacosiov1.DynamicConfigurationDriftPolicy) *nacosiov1.DynamicConfiguration {
+			dc := newDC(name, nacosiov1.Cluster2Server, "app.yaml")
+			dc.Spec.Strategy.DriftPolicy = policy
+			dc.Spec.Strategy.ResyncInterval = &metav1.Duration{Duration: time.Minute}
+			Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			})).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1"))
+			Expect(dc.Status.LastResyncTime).NotTo(BeNil())
+
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: 2")
+			lastResyncTime := *dc.Status.LastResyncTime
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 2"))
+			Expect(*dc.Status.LastResyncTime).To(Equal(lastResyncTime))
+
+			dc.Status.LastResyncTime = &metav1.Time{Time: lastResyncTime.Add(-time.Minute)}
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(dc.Status.LastResyncTime.After(lastResyncTime.Time)).To(BeTrue())
+			return dc
+		}
+
+		It("publishes drifted content again with correct policy", func() {
+			dc := syncDrifted("c2s-drift-correct", nacosiov1.DriftCorrect)
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1"))
+			status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.yaml")
+			Expect(status.Ready).To(BeTrue())
+		})
+
+		It("reports drifted content with report policy", func() {
+			dc := syncDrifted("c2s-drift-report", nacosiov1.DriftReport)
+			Expect(getServerContent("app.yaml")).To(Equal("a: 2"))
+			status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.yaml")
+			Expect(status.Ready).To(BeFalse())
+			Expect(status.Reason).To(Equal(nacosiov1.SyncReasonDrifted))
+			Expect(meta.IsStatusConditionFalse(dc.Status.Conditions, nacosiov1.ConditionSynced)).To(BeTrue())
+		})
+
+		It("keeps LastResyncTime of server2cluster until resync is due", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: 1")
+			dc := newDC("s2c-resync", nacosiov1.Server2Cluster, "app.yaml")
+			dc.Spec.Strategy.ResyncInterval = &metav1.Duration{Duration: time.Minute}
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(dc.Status.LastResyncTime).NotTo(BeNil())
+			lastResyncTime := *dc.Status.LastResyncTime
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(*dc.Status.LastResyncTime).To(Equal(lastResyncTime))
+			Expect(controller.RequeueAfter(dc)).To(BeNumerically(">", 50*time.Second))
+		})
+	})
+
+	Describe("suspend", func() {
+		It("ignores changes of nacos server while suspended", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.properties", "a=1")
+			dc := newDC("s2c-suspend", nacosiov1.Server2Cluster, "app.properties")
+			Expect(k8sClient.Create(ctx, dc)).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(k8sClient.Status().Update(ctx, dc)).To(Succeed())
+
+			dc.Spec.Suspend = true
+			Expect(k8sClient.Update(ctx, dc)).To(Succeed())
+			server.Publish(testNacosNamespace, testGroup, "app.properties", "a=2")
+			Consistently(func() map[string]string {
+				return getConfigMap("s2c-suspend").Data
+			}, time.Second, 100*time.Millisecond).Should(HaveKeyWithValue("app.properties", "a=1"))
+		})
+
+		It("compares all dataIds with nacos server again after resumed", func() {
+			dc := newDC("c2s-resume", nacosiov1.Cluster2Server, "app.yaml")
+			dc.Spec.Strategy.DriftPolicy = nacosiov1.DriftCorrect
+			Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s-resume", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			})).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+
+			SetSuspendedCondition(dc)
+			server.Publish(testNacosNamespace, testGroup, "app.yaml", "a: 2")
+			Expect(WasSuspended(dc)).To(BeTrue())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1"))
+			Expect(WasSuspended(dc)).To(BeFalse())
+			Expect(meta.IsStatusConditionTrue(dc.Status.Conditions, nacosiov1.ConditionReady)).To(BeTrue())
+		})
+	})
+
+	Describe("listeners", func() {
+		It("rehydrates, verifies and releases listeners", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.properties", "a=1")
+			dc := newDC("s2c-listeners", nacosiov1.Server2Cluster, "app.properties")
+			Expect(k8sClient.Create(ctx, dc)).To(Succeed())
+			controller = NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+				ConfigClientFactory:    server.ConfigClientFactory(),
+				ListenerVerifyInterval: 100 * time.Millisecond,
+			})
+			runCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				Expect(controller.RunListeners(runCtx)).To(Succeed())
+			}()
+			Eventually(controller.ListenersRehydrated, 5*time.Second, 100*time.Millisecond).Should(BeTrue())
+			Expect(server.ListenerCount(testNacosNamespace, testGroup, "app.properties")).To(Equal(1))
+
+			server.DropListeners()
+			Eventually(func() int {
+				return server.ListenerCount(testNacosNamespace, testGroup, "app.properties")
+			}, 5*time.Second, 100*time.Millisecond).Should(Equal(1))
+			Eventually(controller.Events(), 5*time.Second).Should(Receive())
+
+			cancel()
+			Eventually(done, 5*time.Second).Should(BeClosed())
+			Expect(server.ListenerCount(testNacosNamespace, testGroup, "app.properties")).To(Equal(0))
+			Expect(controller.ListenersRehydrated()).To(BeFalse())
+		})
+
+		It("skips verifying clients which can't tell listening state", func() {
+			c := &uncheckedConfigClient{IConfigClient: server.NewConfigClient(testNacosNamespace)}
+			registry := NewListenerRegistry(NewDataId2DCMappings(), func(namespace, group, dataId, content string) {})
+			key := NacosConfigKey{Namespace: testNacosNamespace, Group: testGroup, DataId: "app.properties"}
+			dc1 := types.NamespacedName{Namespace: testNamespace, Name: "dc1"}
+			dc2 := types.NamespacedName{Namespace: testNamespace, Name: "dc2"}
+			Expect(registry.Listen(dc1, key, c)).To(Succeed())
+			Expect(registry.Listen(dc2, key, c)).To(Succeed())
+
+			server.SetError(fake.OperationListen, fmt.Errorf("client not connected"))
+			Expect(registry.Verify(ctx)).To(BeEmpty())
+			Expect(c.cancels).To(Equal(0))
+			Expect(server.ListenerCount(testNacosNamespace, testGroup, "app.properties")).To(Equal(1))
+			server.SetError(fake.OperationListen, nil)
+
+			// subscriptions whose mapping was removed are still dropped
+			Expect(registry.Cancel(dc1, key)).To(Succeed())
+			registry.mappings.RemoveMapping(key.Namespace, key.Group, key.DataId, dc2)
+			Expect(registry.Verify(ctx)).To(BeEmpty())
+			Expect(c.cancels).To(Equal(1))
+			Expect(server.ListenerCount(testNacosNamespace, testGroup, "app.properties")).To(Equal(0))
+		})
+
+		It("reports Listening condition when listening again failed", func() {
+			server.Publish(testNacosNamespace, testGroup, "app.properties", "a=1")
+			dc := newDC("s2c-listen-failed", nacosiov1.Server2Cluster, "app.properties")
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(dc.Status.Conditions, nacosiov1.ConditionListening)).To(BeTrue())
+
+			server.DropListeners()
+			server.SetError(fake.OperationListen, fmt.Errorf("client not connected"))
+			controller.VerifyListeners(ctx)
+			Expect(controller.Events()).To(Receive())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+			condition := meta.FindStatusCondition(dc.Status.Conditions, nacosiov1.ConditionListening)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(nacosiov1.SyncReasonListenFailed))
+
+			server.SetError(fake.OperationListen, nil)
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(dc.Status.Conditions, nacosiov1.ConditionListening)).To(BeTrue())
+			Expect(server.ListenerCount(testNacosNamespace, testGroup, "app.properties")).To(Equal(1))
+		})
+	})
+
+	Describe("targets", func() {
+		It("publishes to every target and reports failed targets without blocking the others", func() {
+			dc := newDC("c2s-targets", nacosiov1.Cluster2Server, "app.yaml")
+			dc.Spec.Strategy.SyncDeletion = true
+			dc.Spec.Targets = []nacosiov1.SyncTarget{
+				{
+					Name: "staging",
+					NacosServer: nacosiov1.NacosServerConfiguration{
+						ServerAddr: pointer.String("127.0.0.1:8848"),
+						Namespace:  "staging",
+						Group:      "STAGING_GROUP",
+						AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+					},
+				},
+				{
+					Name: "dr",
+					NacosServer: nacosiov1.NacosServerConfiguration{
+						ServerAddr: pointer.String("127.0.0.2:8848"),
+						Namespace:  "dr",
+						AuthRef:    &v1.ObjectReference{Name: "dr-auth", APIVersion: "v1", Kind: "Secret"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s-targets", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1"},
+			})).To(Succeed())
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(MatchError(ContainSubstring("dr")))
+			Expect(getServerContent("app.yaml")).To(Equal("a: 1"))
+			content, _ := server.Get("staging", "STAGING_GROUP", "app.yaml")
+			Expect(content).To(Equal("a: 1"))
+			staging := GetTargetStatus(dc, "staging")
+			Expect(staging).NotTo(BeNil())
+			Expect(staging.Ready).To(BeTrue())
+			Expect(staging.NacosNamespace).To(Equal("staging"))
+			Expect(GetSyncStatusByDataId(staging.SyncStatuses, "app.yaml").Md5).To(Equal(CalcMd5("a: 1")))
+			dr := GetTargetStatus(dc, "dr")
+			Expect(dr).NotTo(BeNil())
+			Expect(dr.Ready).To(BeFalse())
+			Expect(dr.Message).To(ContainSubstring("dr-auth"))
+
+			Expect(k8sClient.Create(ctx, &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "dr-auth", Namespace: testNamespace},
+				Data:       map[string][]byte{"ak": []byte("dr"), "sk": []byte("dr")},
+			})).To(Succeed())
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			content, _ = server.Get("dr", testGroup, "app.yaml")
+			Expect(content).To(Equal("a: 1"))
+			Expect(GetTargetStatus(dc, "dr").Ready).To(BeTrue())
+
+			Expect(controller.Finalize(ctx, dc)).To(Succeed())
+			for _, namespace := range []string{testNacosNamespace, "dr"} {
+				_, exist := server.Get(namespace, testGroup, "app.yaml")
+				Expect(exist).To(BeFalse())
+			}
+			_, exist := server.Get("staging", "STAGING_GROUP", "app.yaml")
+			Expect(exist).To(BeFalse())
+		})
+
+		It("syncs targets with gray and IfAbsent the same way as spec.nacosServer", func() {
+			recorder := record.NewFakeRecorder(100)
+			controller = NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+				ConfigClientFactory: server.ConfigClientFactory(),
+				EventRecorder:       recorder,
+			})
+			dc := newDC("c2s-targets-gray", nacosiov1.Cluster2Server, "app.yaml")
+			dc.Spec.Configs = []nacosiov1.DataIdConfig{{DataId: "init.yaml", SyncPolicy: nacosiov1.IfAbsent}}
+			dc.Spec.Strategy.Gray = &nacosiov1.GrayStrategy{BetaIps: []string{"10.0.0.1"}}
+			dc.Spec.Targets = []nacosiov1.SyncTarget{{
+				Name: "staging",
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String("127.0.0.1:8848"),
+					Namespace:  "staging",
+					AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+				},
+			}}
+			Expect(k8sClient.Create(ctx, dc)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "c2s-targets-gray", Namespace: testNamespace},
+				Data:       map[string]string{"app.yaml": "a: 1", "init.yaml": "b: 1"},
+			})).To(Succeed())
+			server.Publish("staging", testGroup, "init.yaml", "b: 0")
+
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			for _, namespace := range []string{testNacosNamespace, "staging"} {
+				_, exist := server.Get(namespace, testGroup, "app.yaml")
+				Expect(exist).To(BeFalse())
+				beta, ips, _ := server.Beta(namespace, testGroup, "app.yaml")
+				Expect(beta).To(Equal("a: 1"))
+				Expect(ips).To(Equal([]string{"10.0.0.1"}))
+			}
+			staging := GetTargetStatus(dc, "staging")
+			Expect(getGrayStatus(staging.GrayStatuses, "app.yaml").Phase).To(Equal(nacosiov1.GrayPhaseBeta))
+			content, _ := server.Get("staging", testGroup, "init.yaml")
+			Expect(content).To(Equal("b: 0"))
+			status := GetSyncStatusByDataId(staging.SyncStatuses, "init.yaml")
+			Expect(status.Reason).To(Equal(nacosiov1.SyncReasonSkipped))
+			Expect(status.Md5).To(Equal(CalcMd5("b: 1")))
+
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			Expect(events).To(ContainElement(ContainSubstring("init.yaml skipped, due to SyncPolicy IfAbsent and target staging has config already")))
+			Expect(events).To(ContainElement(ContainSubstring("app.yaml published to beta IPs 10.0.0.1 of target staging")))
+
+			dc.Annotations = map[string]string{pkg.GrayPromoteAnnotation: "true"}
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+			content, _ = server.Get("staging", testGroup, "app.yaml")
+			Expect(content).To(Equal("a: 1"))
+			Expect(getGrayStatus(GetTargetStatus(dc, "staging").GrayStatuses, "app.yaml").Phase).To(Equal(nacosiov1.GrayPhasePromoted))
+			Expect(dc.Annotations).NotTo(HaveKey(pkg.GrayPromoteAnnotation))
+		})
+	})
+})
+
+// uncheckedConfigClient hides IsListening of fake client, like clients of nacos sdk, and counts cancellations
+type uncheckedConfigClient struct {
+	config_client.IConfigClient
+	cancels int
+}
+
+func (c *uncheckedConfigClient) CancelListenConfig(param vo.ConfigParam) error {
+	c.cancels++
+	return c.IConfigClient.CancelListenConfig(param)
+}
@@ -0,0 +1,132 @@
+package nacos
+
+import (
+	"context"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/auth"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("nacos server reference", func() {
+	var ctx context.Context
+	var server *fake.ConfigServer
+	var k8sClient client.Client
+	var controller *SyncConfigurationController
+
+	newRefDC := func(namespace string, ref *nacosiov1.NacosServerReference) *nacosiov1.DynamicConfiguration {
+		Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace},
+			Data:       map[string]string{"app.yaml": "namespace: " + namespace},
+		})).To(Succeed())
+		return &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "dc", Namespace: namespace, UID: types.UID(namespace + "-dc")},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				DataIds:        []string{"app.yaml"},
+				Strategy:       nacosiov1.SyncStrategy{SyncPolicy: nacosiov1.Always, SyncDirection: nacosiov1.Cluster2Server},
+				NacosServerRef: ref,
+				NacosServer:    nacosiov1.NacosServerConfiguration{Group: testGroup},
+				ObjectRef:      &v1.ObjectReference{Name: "app", APIVersion: "v1", Kind: "ConfigMap"},
+			},
+		}
+	}
+	getServerContent := func(namespace string) string {
+		content, _ := server.Get(namespace, testGroup, "app.yaml")
+		return content
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = fake.NewConfigServer()
+		server.SetCredentials(&auth.ConfigClientAuthInfo{AccessKey: "ak", SecretKey: "sk"})
+		k8sClient = fakeclient.NewClientBuilder().
+			WithScheme(testScheme).
+			WithStatusSubresource(&nacosiov1.DynamicConfiguration{}).
+			WithObjects(
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"nacos": "shared"}}},
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "nacos-auth", Namespace: "nacos-system"},
+					Data:       map[string][]byte{"ak": []byte("ak"), "sk": []byte("sk")},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "nacos-auth", Namespace: "team-a"},
+					Data:       map[string][]byte{"ak": []byte("ak"), "sk": []byte("sk")},
+				},
+				&nacosiov1.NacosServer{
+					ObjectMeta: metav1.ObjectMeta{Name: "nacos", Namespace: "team-a"},
+					Spec: nacosiov1.NacosServerSpec{
+						ServerAddr: pointer.String("127.0.0.1:8848"),
+						Namespace:  "team-a-ns",
+						AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+					},
+				},
+				&nacosiov1.ClusterNacosServer{
+					ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+					Spec: nacosiov1.ClusterNacosServerSpec{
+						NacosServerSpec: nacosiov1.NacosServerSpec{
+							ServerAddr: pointer.String("127.0.0.1:8848"),
+							Namespace:  "shared-ns",
+							AuthRef:    &v1.ObjectReference{Name: "nacos-auth", Namespace: "nacos-system", APIVersion: "v1", Kind: "Secret"},
+						},
+						AllowedNamespaces: &nacosiov1.AllowedNamespaces{
+							Names:    []string{"team-a"},
+							Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"nacos": "shared"}},
+						},
+					},
+				},
+			).
+			Build()
+		controller = NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+			ConfigClientFactory: server.ConfigClientFactory(),
+		})
+	})
+
+	It("syncs to NacosServer in the same namespace", func() {
+		dc := newRefDC("team-a", &nacosiov1.NacosServerReference{Name: "nacos"})
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		Expect(getServerContent("team-a-ns")).To(Equal("namespace: team-a"))
+		Expect(dc.Status.NacosNamespace).To(Equal("team-a-ns"))
+
+		// NacosServer in other namespaces can't be referenced
+		dc = newRefDC("team-b", &nacosiov1.NacosServerReference{Name: "nacos"})
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+	})
+
+	It("syncs to ClusterNacosServer from namespaces listed or selected", func() {
+		for _, namespace := range []string{"team-a", "team-b"} {
+			dc := newRefDC(namespace, &nacosiov1.NacosServerReference{Kind: nacosiov1.ClusterNacosServerKind, Name: "shared"})
+			Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed(), namespace)
+			Expect(getServerContent("shared-ns")).To(Equal("namespace: "+namespace), namespace)
+		}
+	})
+
+	It("rejects namespaces not allowed by ClusterNacosServer without connecting nacos server", func() {
+		dc := newRefDC("team-c", &nacosiov1.NacosServerReference{Kind: nacosiov1.ClusterNacosServerKind, Name: "shared"})
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(MatchError(ContainSubstring("namespace team-c is not allowed")))
+		Expect(server.Clients()).To(BeEmpty())
+		_, exist := server.Get("shared-ns", testGroup, "app.yaml")
+		Expect(exist).To(BeFalse())
+		condition := meta.FindStatusCondition(dc.Status.Conditions, nacosiov1.ConditionAuthenticated)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal(ConditionReasonInvalidAuthConfig))
+
+		// namespace becomes allowed after it is labeled
+		ns := &v1.Namespace{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "team-c"}, ns)).To(Succeed())
+		ns.Labels = map[string]string{"nacos": "shared"}
+		Expect(k8sClient.Update(ctx, ns)).To(Succeed())
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		Expect(getServerContent("shared-ns")).To(Equal("namespace: team-c"))
+	})
+})
@@ -0,0 +1,25 @@
+package nacos
+
+import (
+	"testing"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var testScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(testScheme))
+	utilruntime.Must(nacosiov1.AddToScheme(testScheme))
+}
+
+func TestNacos(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Nacos Suite")
+}
@@ -22,6 +22,14 @@ func init() {
 			owner:     owner,
 		}, nil
 	})
+	// Using SecretWrapper as default Secret resource wrapper
+	RegisterObjectWrapperIfAbsent(SecretGVK.String(), func(c client.Client, owner client.Object, objRef *v1.ObjectReference) (ObjectReferenceWrapper, error) {
+		return &SecretWrapper{
+			Client:    c,
+			ObjectRef: objRef,
+			owner:     owner,
+		}, nil
+	})
 }
 
 type ObjectReferenceWrapper interface {
@@ -176,19 +184,7 @@ func (cmw *ConfigMapWrapper) Reload() error {
 			// if not found, try to create object reference
 			cm.Namespace = cmw.ObjectRef.Namespace
 			cm.Name = cmw.ObjectRef.Name
-
-			owner := cmw.owner
-			apiVersion, kind := owner.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
-			cm.SetOwnerReferences([]v12.OwnerReference{
-				{
-					APIVersion:         apiVersion,
-					Kind:               kind,
-					Name:               owner.GetName(),
-					UID:                owner.GetUID(),
-					Controller:         pointer.Bool(true),
-					BlockOwnerDeletion: pointer.Bool(true),
-				},
-			})
+			cm.SetOwnerReferences(newControllerOwnerReferences(cmw.owner))
 			if err := cmw.Create(context.TODO(), &cm); err != nil {
 				return err
 			}
@@ -213,3 +209,138 @@ func (cmw *ConfigMapWrapper) ensureOwnerLabel() error {
 	}
 	return nil
 }
+
+type SecretWrapper struct {
+	ObjectRef *v1.ObjectReference
+	secret    *v1.Secret
+	owner     client.Object
+	client.Client
+}
+
+func (sw *SecretWrapper) GetContent(dataId string) (string, bool, error) {
+	if sw.secret == nil {
+		if err := sw.Reload(); err != nil {
+			return "", false, err
+		}
+	}
+	if v, ok := sw.secret.Data[dataId]; ok {
+		return string(v), true, nil
+	}
+	if v, ok := sw.secret.StringData[dataId]; ok {
+		return v, true, nil
+	}
+	return "", false, nil
+}
+
+func (sw *SecretWrapper) StoreContent(dataId string, content string) error {
+	if sw.secret == nil {
+		if err := sw.Reload(); err != nil {
+			return err
+		}
+	}
+	if sw.secret.Data == nil {
+		sw.secret.Data = map[string][]byte{}
+	}
+	sw.secret.Data[dataId] = []byte(content)
+	return nil
+}
+
+func (sw *SecretWrapper) DeleteContent(dataId string) error {
+	if sw.secret == nil {
+		if err := sw.Reload(); err != nil {
+			return err
+		}
+	}
+	delete(sw.secret.Data, dataId)
+	delete(sw.secret.StringData, dataId)
+	return nil
+}
+
+func (sw *SecretWrapper) StoreAllContent(dataMap map[string]string) (bool, error) {
+	changed := false
+	for dataId, newContent := range dataMap {
+		if !changed {
+			if oldContent, exist, err := sw.GetContent(dataId); err != nil {
+				return false, err
+			} else if !exist || CalcMd5(newContent) != CalcMd5(oldContent) {
+				changed = true
+			}
+		}
+		if err := sw.StoreContent(dataId, newContent); err != nil {
+			return false, err
+		}
+	}
+	return changed, nil
+}
+
+func (sw *SecretWrapper) Flush() error {
+	if sw.secret == nil {
+		return nil
+	}
+	return sw.Update(context.TODO(), sw.secret)
+}
+
+func (sw *SecretWrapper) InjectLabels(labels map[string]string) {
+	if sw.secret == nil {
+		if err := sw.Reload(); err != nil {
+			return
+		}
+	}
+	if len(labels) == 0 {
+		return
+	}
+	if sw.secret.Labels == nil {
+		sw.secret.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		sw.secret.Labels[k] = v
+	}
+}
+
+func (sw *SecretWrapper) Reload() error {
+	secret := v1.Secret{}
+	if err := sw.Get(context.TODO(), types.NamespacedName{Namespace: sw.ObjectRef.Namespace, Name: sw.ObjectRef.Name}, &secret); err != nil {
+		if errors.IsNotFound(err) {
+			// if not found, try to create object reference
+			secret.Namespace = sw.ObjectRef.Namespace
+			secret.Name = sw.ObjectRef.Name
+			secret.Type = v1.SecretTypeOpaque
+			secret.SetOwnerReferences(newControllerOwnerReferences(sw.owner))
+			if err := sw.Create(context.TODO(), &secret); err != nil {
+				return err
+			}
+		} else {
+			return err
+		}
+	}
+	sw.secret = &secret
+	return sw.ensureOwnerLabel()
+}
+
+func (sw *SecretWrapper) ensureOwnerLabel() error {
+	if sw.secret == nil {
+		return nil
+	}
+	if sw.secret.Labels == nil {
+		sw.secret.Labels = map[string]string{}
+	}
+	if v, ok := sw.secret.Labels[pkg.ConfigMapLabel]; !ok || v != sw.owner.GetName() {
+		sw.secret.Labels[pkg.ConfigMapLabel] = sw.owner.GetName()
+		return sw.Flush()
+	}
+	return nil
+}
+
+func newControllerOwnerReferences(owner client.Object) []v12.OwnerReference {
+	apiVersion, kind := owner.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
+	return []v12.OwnerReference{
+		{
+			APIVersion:         apiVersion,
+			Kind:               kind,
+			Name:               owner.GetName(),
+			UID:                owner.GetUID(),
+			Controller:         pointer.Bool(true),
+			BlockOwnerDeletion: pointer.Bool(true),
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package nacos
+
+import (
+	"context"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("SecretWrapper", func() {
+	var ctx context.Context
+	var server *fake.ConfigServer
+	var k8sClient client.Client
+	var controller *SyncConfigurationController
+
+	newSecretDC := func(name string, direction nacosiov1.DynamicConfigurationSyncDirection, dataIds ...string) *nacosiov1.DynamicConfiguration {
+		return &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, UID: types.UID(name)},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				DataIds:  dataIds,
+				Strategy: nacosiov1.SyncStrategy{SyncPolicy: nacosiov1.Always, SyncDirection: direction},
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String("127.0.0.1:8848"),
+					Namespace:  testNacosNamespace,
+					Group:      testGroup,
+					AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+				},
+				ObjectRef: &v1.ObjectReference{Name: name, APIVersion: "v1", Kind: "Secret"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = fake.NewConfigServer()
+		k8sClient = fakeclient.NewClientBuilder().
+			WithScheme(testScheme).
+			WithStatusSubresource(&nacosiov1.DynamicConfiguration{}).
+			WithObjects(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "nacos-auth", Namespace: testNamespace},
+				Data:       map[string][]byte{"ak": []byte("ak"), "sk": []byte("sk")},
+			}).
+			Build()
+		controller = NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+			ConfigClientFactory: server.ConfigClientFactory(),
+		})
+	})
+
+	It("publishes decoded data of Secret and falls back to stringData", func() {
+		dc := newSecretDC("c2s-secret", nacosiov1.Cluster2Server, "app.yaml", "db.properties", "both.properties")
+		Expect(k8sClient.Create(ctx, &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "c2s-secret", Namespace: testNamespace},
+			Data: map[string][]byte{
+				"app.yaml":        []byte("password: s3cr3t"),
+				"both.properties": []byte("from=data"),
+			},
+			StringData: map[string]string{
+				"db.properties":   "url=jdbc:mysql://db",
+				"both.properties": "from=stringData",
+			},
+		})).To(Succeed())
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		for dataId, expected := range map[string]string{
+			"app.yaml":        "password: s3cr3t",
+			"db.properties":   "url=jdbc:mysql://db",
+			"both.properties": "from=data",
+		} {
+			content, exist := server.Get(testNacosNamespace, testGroup, dataId)
+			Expect(exist).To(BeTrue(), dataId)
+			Expect(content).To(Equal(expected), dataId)
+		}
+		secret := &v1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "c2s-secret"}, secret)).To(Succeed())
+		Expect(secret.Labels).To(HaveKeyWithValue(pkg.ConfigMapLabel, "c2s-secret"))
+	})
+
+	It("creates Secret owned by DynamicConfiguration and stores content of nacos server", func() {
+		server.Publish(testNacosNamespace, testGroup, "app.properties", "a=1")
+		dc := newSecretDC("s2c-secret", nacosiov1.Server2Cluster, "app.properties")
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		secret := &v1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "s2c-secret"}, secret)).To(Succeed())
+		Expect(secret.Type).To(Equal(v1.SecretTypeOpaque))
+		Expect(secret.Data).To(HaveKeyWithValue("app.properties", []byte("a=1")))
+		Expect(secret.Labels).To(HaveKeyWithValue(pkg.ConfigMapLabel, "s2c-secret"))
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(secret.OwnerReferences[0].UID).To(Equal(dc.UID))
+	})
+
+	It("deletes content from both data and stringData", func() {
+		Expect(k8sClient.Create(ctx, &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "wrapper", Namespace: testNamespace},
+			Data:       map[string][]byte{"a": []byte("1")},
+			StringData: map[string]string{"a": "2", "b": "3"},
+		})).To(Succeed())
+		dc := newSecretDC("wrapper", nacosiov1.Server2Cluster)
+		w := &SecretWrapper{Client: k8sClient, owner: dc,
+			ObjectRef: &v1.ObjectReference{Name: "wrapper", Namespace: testNamespace, APIVersion: "v1", Kind: "Secret"}}
+
+		Expect(w.DeleteContent("a")).To(Succeed())
+		_, exist, err := w.GetContent("a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exist).To(BeFalse())
+		content, exist, err := w.GetContent("b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exist).To(BeTrue())
+		Expect(content).To(Equal("3"))
+
+		changed, err := w.StoreAllContent(map[string]string{"b": "3"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		changed, err = w.StoreAllContent(map[string]string{"b": "4"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+	})
+})
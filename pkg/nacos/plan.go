@@ -0,0 +1,283 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// planSync computes changes of dataIds for spec.strategy.dryRun, and reports them in dc.status.plan and events.
+// Nothing is written to nacos server or object reference, and no dataId is listened. The plan and its time are
+// kept, and no event is recorded, if changes are the same as last plan.
+func (scc *SyncConfigurationController) planSync(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
+	l := log.FromContext(ctx)
+	direction := dc.Spec.Strategy.SyncDirection
+	if direction != nacosiov1.Cluster2Server && direction != nacosiov1.Server2Cluster {
+		return fmt.Errorf("dry run is not supported by sync direction: %s", direction)
+	}
+	configClient, err := scc.getNacosConfigClient(ctx, dc)
+	if err != nil {
+		l.Error(err, "create nacos config client error")
+		return err
+	}
+	objectRef := getObjectReference(dc)
+	clusterData, err := readObjectReference(ctx, scc.Client, &objectRef)
+	if err != nil {
+		l.Error(err, "read object reference error", "obj", objectRef)
+		return err
+	}
+	if direction == nacosiov1.Server2Cluster && dc.Spec.DataIdSelector != nil {
+		selected, err := selectDataIds(configClient, dc.Spec.NacosServer.Group, dc.Spec.DataIdSelector)
+		if err != nil {
+			l.Error(err, "select dataIds from server error")
+			return fmt.Errorf("select dataIds from server error: %w", err)
+		}
+		dc.Status.SelectedDataIds = selected
+	}
+
+	renderer := NewContentRenderer(ctx, scc.Client, dc)
+	changes, errDataIdList := planDataIds(ctx, configClient, renderer, dc, clusterData, "")
+	var errTargets []string
+	if direction == nacosiov1.Cluster2Server {
+		// targets are planned in the same way as spec.nacosServer
+		for _, target := range dc.Spec.Targets {
+			targetClient, _, err := scc.getTargetConfigClient(ctx, dc, target)
+			if err != nil {
+				l.Error(err, "create nacos config client of target error", "target", target.Name)
+				errTargets = append(errTargets, target.Name)
+				changes = append(changes, nacosiov1.PlannedChange{Target: target.Name, Action: nacosiov1.PlanActionSkip, Message: "create nacos config client error: " + err.Error()})
+				continue
+			}
+			targetChanges, errList := planDataIds(ctx, targetClient, renderer, targetView(dc, target), clusterData, target.Name)
+			changes = append(changes, targetChanges...)
+			if len(errList) > 0 {
+				errTargets = append(errTargets, target.Name)
+			}
+		}
+	}
+
+	if dc.Status.Plan == nil || !equality.Semantic.DeepEqual(dc.Status.Plan.Changes, changes) {
+		for _, change := range changes {
+			if change.Action == nacosiov1.PlanActionSkip {
+				continue
+			}
+			if len(change.Target) > 0 {
+				scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonPlanned, "dry run: dataId %s would be %s of target %s, %s", change.DataId, planActionVerb(change.Action, direction), change.Target, change.Diff)
+			} else {
+				scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonPlanned, "dry run: dataId %s would be %s, %s", change.DataId, planActionVerb(change.Action, direction), change.Diff)
+			}
+		}
+		dc.Status.Plan = &nacosiov1.SyncPlan{
+			PlanTime: metav1.Now(),
+			Summary:  planSummary(changes),
+			Changes:  changes,
+		}
+		l.Info("dry run planned", "summary", dc.Status.Plan.Summary)
+	}
+	if len(errDataIdList) > 0 {
+		return fmt.Errorf("error dataIds: %s", strings.Join(errDataIdList, ","))
+	}
+	if len(errTargets) > 0 {
+		return fmt.Errorf("err targets: %s", strings.Join(errTargets, ","))
+	}
+	return nil
+}
+
+// planDataIds computes changes of all dataIds of dc between nacos server of configClient and cluster. Target is the
+// name of spec.targets if dc is the view of a target.
+func planDataIds(ctx context.Context, configClient config_client.IConfigClient, renderer *ContentRenderer, dc *nacosiov1.DynamicConfiguration, clusterData map[string]string, target string) ([]nacosiov1.PlannedChange, []string) {
+	l := log.FromContext(ctx)
+	direction := dc.Spec.Strategy.SyncDirection
+	var changes []nacosiov1.PlannedChange
+	var errDataIdList []string
+	for _, entry := range GetConfigEntries(dc) {
+		dataId := entry.DataId
+		serverContent, err := configClient.GetConfig(vo.ConfigParam{
+			Group:  entry.Group,
+			DataId: dataId,
+		})
+		if err != nil {
+			l.Error(err, "read content from server error", "dataId", dataId, "target", target)
+			errDataIdList = append(errDataIdList, dataId)
+			changes = append(changes, nacosiov1.PlannedChange{DataId: dataId, Target: target, Action: nacosiov1.PlanActionSkip, Message: "read content from server error: " + err.Error()})
+			continue
+		}
+		clusterContent, clusterExist := clusterData[entry.Key]
+		var change nacosiov1.PlannedChange
+		if direction == nacosiov1.Cluster2Server {
+			change, err = planCluster2Server(renderer, entry, clusterContent, clusterExist, serverContent)
+		} else {
+			change, err = planServer2Cluster(renderer, entry, clusterContent, clusterExist, serverContent)
+		}
+		if err != nil {
+			errDataIdList = append(errDataIdList, dataId)
+		}
+		change.Target = target
+		changes = append(changes, change)
+	}
+	return changes, errDataIdList
+}
+
+func planCluster2Server(renderer *ContentRenderer, entry ConfigEntry, clusterContent string, clusterExist bool, serverContent string) (nacosiov1.PlannedChange, error) {
+	change := nacosiov1.PlannedChange{DataId: entry.DataId, Action: nacosiov1.PlanActionSkip}
+	if !clusterExist {
+		if entry.SyncDeletion && len(serverContent) > 0 {
+			change.Action = nacosiov1.PlanActionDelete
+			change.Diff = DiffSummary(serverContent, "")
+		} else {
+			change.Message = "dataId not exist in cluster"
+		}
+		return change, nil
+	}
+	content, err := renderer.Render(entry.DataId, clusterContent)
+	if err == nil {
+		err = ValidateContent(entry.Format, content)
+	}
+	if err != nil {
+		change.Message = err.Error()
+		return change, err
+	}
+	switch {
+	case len(serverContent) == 0:
+		change.Action = nacosiov1.PlanActionCreate
+		change.Diff = DiffSummary("", content)
+	case content == serverContent:
+		change.Message = "same content"
+	case entry.SyncPolicy == nacosiov1.IfAbsent:
+		change.Message = "SyncPolicy IfAbsent and server has config already"
+	default:
+		change.Action = nacosiov1.PlanActionUpdate
+		change.Diff = DiffSummary(serverContent, content)
+	}
+	return change, nil
+}
+
+func planServer2Cluster(renderer *ContentRenderer, entry ConfigEntry, clusterContent string, clusterExist bool, serverContent string) (nacosiov1.PlannedChange, error) {
+	change := nacosiov1.PlannedChange{DataId: entry.DataId, Action: nacosiov1.PlanActionSkip}
+	content, err := renderer.Render(entry.DataId, serverContent)
+	if err == nil {
+		err = ValidateContent(entry.Format, content)
+	}
+	if err != nil {
+		change.Message = err.Error()
+		return change, err
+	}
+	switch {
+	case !clusterExist:
+		change.Action = nacosiov1.PlanActionCreate
+		change.Diff = DiffSummary("", content)
+		if len(content) == 0 {
+			change.Message = "dataId not exist in nacos server, empty content would be stored"
+		}
+	case entry.SyncPolicy == nacosiov1.IfAbsent:
+		change.Message = "SyncPolicy IfAbsent and cluster has config already"
+	case content == clusterContent:
+		change.Message = "same content"
+	default:
+		change.Action = nacosiov1.PlanActionUpdate
+		change.Diff = DiffSummary(clusterContent, content)
+	}
+	return change, nil
+}
+
+// readObjectReference returns content of all keys in object reference without creating or changing it
+func readObjectReference(ctx context.Context, c client.Client, objRef *v1.ObjectReference) (map[string]string, error) {
+	nn := types.NamespacedName{Namespace: objRef.Namespace, Name: objRef.Name}
+	data := map[string]string{}
+	switch objRef.GroupVersionKind() {
+	case ConfigMapGVK:
+		cm := v1.ConfigMap{}
+		if err := c.Get(ctx, nn, &cm); err != nil {
+			if errors.IsNotFound(err) {
+				return data, nil
+			}
+			return nil, err
+		}
+		for k, v := range cm.BinaryData {
+			data[k] = string(v)
+		}
+		for k, v := range cm.Data {
+			data[k] = v
+		}
+	case SecretGVK:
+		secret := v1.Secret{}
+		if err := c.Get(ctx, nn, &secret); err != nil {
+			if errors.IsNotFound(err) {
+				return data, nil
+			}
+			return nil, err
+		}
+		for k, v := range secret.Data {
+			data[k] = string(v)
+		}
+		for k, v := range secret.StringData {
+			data[k] = v
+		}
+	default:
+		return nil, fmt.Errorf("unsupport object reference type: %s", objRef.GroupVersionKind().String())
+	}
+	return data, nil
+}
+
+// DiffSummary counts lines added and removed from oldContent to newContent, order of lines is ignored
+func DiffSummary(oldContent, newContent string) string {
+	counts := map[string]int{}
+	for _, line := range splitLines(oldContent) {
+		counts[line]--
+	}
+	for _, line := range splitLines(newContent) {
+		counts[line]++
+	}
+	added, removed := 0, 0
+	for _, n := range counts {
+		if n > 0 {
+			added += n
+		} else {
+			removed -= n
+		}
+	}
+	return fmt.Sprintf("+%d -%d lines", added, removed)
+}
+
+func splitLines(content string) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+func planSummary(changes []nacosiov1.PlannedChange) string {
+	counts := map[nacosiov1.PlanAction]int{}
+	for _, change := range changes {
+		counts[change.Action]++
+	}
+	return fmt.Sprintf("%d to create, %d to update, %d to delete, %d to skip",
+		counts[nacosiov1.PlanActionCreate], counts[nacosiov1.PlanActionUpdate], counts[nacosiov1.PlanActionDelete], counts[nacosiov1.PlanActionSkip])
+}
+
+func planActionVerb(action nacosiov1.PlanAction, direction nacosiov1.DynamicConfigurationSyncDirection) string {
+	side := "nacos server"
+	if direction == nacosiov1.Server2Cluster {
+		side = "cluster"
+	}
+	switch action {
+	case nacosiov1.PlanActionCreate:
+		return "created in " + side
+	case nacosiov1.PlanActionUpdate:
+		return "updated in " + side
+	case nacosiov1.PlanActionDelete:
+		return "deleted in " + side
+	}
+	return "skipped"
+}
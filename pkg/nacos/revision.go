@@ -0,0 +1,334 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	v1 "k8s.io/api/core/v1"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// RevisionDataIdLabel is the md5 of dataId of DynamicConfigurationRevision, dataId is not always a valid label value
+	RevisionDataIdLabel = "nacos.io/data-id-md5"
+	// DefaultRevisionHistoryLimit is the default number of revisions kept for each dataId
+	DefaultRevisionHistoryLimit = 10
+	// RevisionContentKey is the key of content in Secret of DynamicConfigurationRevision
+	RevisionContentKey = "content"
+)
+
+// GetRevisionHistoryLimit returns number of revisions kept for each dataId of dc
+func GetRevisionHistoryLimit(dc *nacosiov1.DynamicConfiguration) int {
+	if dc.Spec.RevisionHistoryLimit == nil {
+		return DefaultRevisionHistoryLimit
+	}
+	return int(*dc.Spec.RevisionHistoryLimit)
+}
+
+// RecordRevision records content synced to the destination side as the latest revision of dataId.
+// If the content is synced before, its revision is moved to the latest one. Revisions out of limit are deleted.
+func RecordRevision(ctx context.Context, c client.Client, dc *nacosiov1.DynamicConfiguration, dataId, group, content, from string) error {
+	limit := GetRevisionHistoryLimit(dc)
+	if limit <= 0 || len(content) == 0 {
+		return nil
+	}
+	revisions, err := ListRevisions(ctx, c, dc, dataId)
+	if err != nil {
+		return err
+	}
+	contentMd5 := CalcMd5(content)
+	var latest int64
+	var existing *nacosiov1.DynamicConfigurationRevision
+	for i := range revisions {
+		if revisions[i].Revision > latest {
+			latest = revisions[i].Revision
+		}
+		if revisions[i].Md5 == contentMd5 {
+			existing = &revisions[i]
+		}
+	}
+	if existing != nil {
+		if existing.Revision != latest {
+			existing.Revision = latest + 1
+			existing.SyncFrom = from
+			existing.SyncTime = v12.Now()
+			if err := c.Update(ctx, existing); err != nil {
+				return err
+			}
+		}
+	} else {
+		sensitive := IsSensitiveContent(dc, dataId)
+		rev := &nacosiov1.DynamicConfigurationRevision{
+			ObjectMeta: v12.ObjectMeta{
+				Name:      revisionName(dc, dataId, contentMd5),
+				Namespace: dc.Namespace,
+				Labels: map[string]string{
+					pkg.ConfigMapLabel:  dc.Name,
+					RevisionDataIdLabel: CalcMd5(dataId),
+				},
+				OwnerReferences: []v12.OwnerReference{{
+					APIVersion:         nacosiov1.GroupVersion.String(),
+					Kind:               "DynamicConfiguration",
+					Name:               dc.Name,
+					UID:                dc.UID,
+					Controller:         pointer.Bool(true),
+					BlockOwnerDeletion: pointer.Bool(true),
+				}},
+			},
+			DataId:   dataId,
+			Group:    group,
+			Revision: latest + 1,
+			Md5:      contentMd5,
+			SyncFrom: from,
+			SyncTime: v12.Now(),
+		}
+		if sensitive {
+			rev.ContentSecretRef = &v1.LocalObjectReference{Name: rev.Name}
+		} else {
+			rev.Content = content
+		}
+		if err := c.Create(ctx, rev); err != nil {
+			return err
+		}
+		if sensitive {
+			if err := createRevisionSecret(ctx, c, rev, content); err != nil {
+				// revision without content can't be rolled back to
+				_ = c.Delete(ctx, rev)
+				return err
+			}
+		}
+		revisions = append(revisions, *rev)
+	}
+	return pruneRevisions(ctx, c, revisions, limit)
+}
+
+// IsSensitiveContent returns true if content of dataId should be stored in Secrets, i.e. objectRef is a Secret or
+// content is rendered with values of Secrets
+func IsSensitiveContent(dc *nacosiov1.DynamicConfiguration, dataId string) bool {
+	objectRef := getObjectReference(dc)
+	if objectRef.GroupVersionKind() == SecretGVK {
+		return true
+	}
+	if !IsTemplated(dc, dataId) {
+		return false
+	}
+	for _, source := range dc.Spec.Template.ValuesFrom {
+		if source.SecretRef != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// createRevisionSecret stores content of rev in a Secret owned by rev, so that it is deleted with rev
+func createRevisionSecret(ctx context.Context, c client.Client, rev *nacosiov1.DynamicConfigurationRevision, content string) error {
+	secret := &v1.Secret{
+		ObjectMeta: v12.ObjectMeta{
+			Name:      rev.ContentSecretRef.Name,
+			Namespace: rev.Namespace,
+			OwnerReferences: []v12.OwnerReference{{
+				APIVersion:         nacosiov1.GroupVersion.String(),
+				Kind:               "DynamicConfigurationRevision",
+				Name:               rev.Name,
+				UID:                rev.UID,
+				Controller:         pointer.Bool(true),
+				BlockOwnerDeletion: pointer.Bool(true),
+			}},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{RevisionContentKey: []byte(content)},
+	}
+	return c.Create(ctx, secret)
+}
+
+// GetRevisionContent returns content of rev, which is read from its Secret if content is sensitive
+func GetRevisionContent(ctx context.Context, c client.Client, rev *nacosiov1.DynamicConfigurationRevision) (string, error) {
+	if rev.ContentSecretRef == nil {
+		return rev.Content, nil
+	}
+	secret := v1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: rev.Namespace, Name: rev.ContentSecretRef.Name}, &secret); err != nil {
+		return "", fmt.Errorf("read content of revision %d error: %w", rev.Revision, err)
+	}
+	content, ok := secret.Data[RevisionContentKey]
+	if !ok {
+		return "", fmt.Errorf("content of revision %d not found in Secret %s", rev.Revision, secret.Name)
+	}
+	return string(content), nil
+}
+
+// ListRevisions returns revisions of dataId in dc, sorted by revision
+func ListRevisions(ctx context.Context, c client.Client, dc *nacosiov1.DynamicConfiguration, dataId string) ([]nacosiov1.DynamicConfigurationRevision, error) {
+	list := nacosiov1.DynamicConfigurationRevisionList{}
+	if err := c.List(ctx, &list, client.InNamespace(dc.Namespace), client.MatchingLabels{
+		pkg.ConfigMapLabel:  dc.Name,
+		RevisionDataIdLabel: CalcMd5(dataId),
+	}); err != nil {
+		return nil, err
+	}
+	var revisions []nacosiov1.DynamicConfigurationRevision
+	for _, rev := range list.Items {
+		if rev.DataId == dataId {
+			revisions = append(revisions, rev)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// GetRevision returns the revision of dataId in dc
+func GetRevision(ctx context.Context, c client.Client, dc *nacosiov1.DynamicConfiguration, dataId string, revision int64) (*nacosiov1.DynamicConfigurationRevision, error) {
+	revisions, err := ListRevisions(ctx, c, dc, dataId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		if revisions[i].Revision == revision {
+			return &revisions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("revision %d of dataId %s not found", revision, dataId)
+}
+
+// IsRolledBack returns true if dataId is pinned to a revision by spec.rollback
+func IsRolledBack(dc *nacosiov1.DynamicConfiguration, dataId string) bool {
+	return dc.Spec.Rollback != nil && dc.Spec.Rollback.DataId == dataId
+}
+
+// recordRevision records content synced to the destination side, failure is logged and doesn't fail syncing
+func (scc *SyncConfigurationController) recordRevision(ctx context.Context, dc *nacosiov1.DynamicConfiguration, entry ConfigEntry, content, from string) {
+	if err := RecordRevision(ctx, scc.Client, dc, entry.DataId, entry.Group, content, from); err != nil {
+		log.FromContext(ctx).Error(err, "record revision error", "dataId", entry.DataId)
+	}
+}
+
+// syncRollback restores the revision of spec.rollback to the destination side, both sides for bidirectional.
+// Rollback is applied once, until spec.rollback is changed or the last rollback failed.
+func (scc *SyncConfigurationController) syncRollback(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
+	rollback := dc.Spec.Rollback
+	if rollback == nil {
+		return nil
+	}
+	last := dc.Status.LastRollback
+	if last != nil && last.Ready && last.DataId == rollback.DataId && last.Revision == rollback.Revision {
+		return nil
+	}
+	l := log.FromContext(ctx).WithValues("dataId", rollback.DataId, "revision", rollback.Revision)
+	rev, err := scc.rollback(ctx, dc)
+	if err != nil {
+		l.Error(err, "rollback error")
+		dc.Status.LastRollback = &nacosiov1.RollbackStatus{
+			DataId:       rollback.DataId,
+			Revision:     rollback.Revision,
+			RollbackTime: v12.Now(),
+			Message:      err.Error(),
+		}
+		MarkSyncStatusNotReady(dc, rollback.DataId, nacosiov1.SyncReasonRollbackFailed, "rollback error: "+err.Error())
+		recordWarning(scc.recorder, dc, ReasonRollbackFailed, rollback.DataId, err)
+		return fmt.Errorf("rollback dataId %s to revision %d error: %w", rollback.DataId, rollback.Revision, err)
+	}
+	l.Info("dataId rolled back")
+	dc.Status.LastRollback = &nacosiov1.RollbackStatus{
+		DataId:       rollback.DataId,
+		Revision:     rollback.Revision,
+		Md5:          rev.Md5,
+		RollbackTime: v12.Now(),
+		Ready:        true,
+	}
+	scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonRolledBack, "dataId %s rolled back to revision %d", rollback.DataId, rollback.Revision)
+	if dc.Spec.Strategy.SyncDirection != nacosiov1.Cluster2Server {
+		return RolloutIfNeeded(ctx, scc.Client, dc, true, scc.recorder)
+	}
+	return nil
+}
+
+func (scc *SyncConfigurationController) rollback(ctx context.Context, dc *nacosiov1.DynamicConfiguration) (*nacosiov1.DynamicConfigurationRevision, error) {
+	rollback := dc.Spec.Rollback
+	var entry *ConfigEntry
+	for _, e := range GetConfigEntries(dc) {
+		if e.DataId == rollback.DataId {
+			entry = &e
+			break
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("dataId %s not found in DynamicConfiguration", rollback.DataId)
+	}
+	rev, err := GetRevision(ctx, scc.Client, dc, rollback.DataId, rollback.Revision)
+	if err != nil {
+		return nil, err
+	}
+	content, err := GetRevisionContent(ctx, scc.Client, rev)
+	if err != nil {
+		return nil, err
+	}
+	direction := dc.Spec.Strategy.SyncDirection
+	if direction == nacosiov1.Cluster2Server || direction == nacosiov1.Bidirectional {
+		configClient, err := scc.getNacosConfigClient(ctx, dc)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := configClient.PublishConfig(vo.ConfigParam{
+			DataId:  entry.DataId,
+			Group:   entry.Group,
+			Content: content,
+			Type:    string(entry.Type),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if direction == nacosiov1.Server2Cluster || direction == nacosiov1.Bidirectional {
+		objectRef := getObjectReference(dc)
+		objWrapper, err := NewObjectReferenceWrapper(scc.Client, dc, &objectRef)
+		if err != nil {
+			return nil, err
+		}
+		if err := objWrapper.StoreContent(entry.Key, content); err != nil {
+			return nil, err
+		}
+		if err := objWrapper.Flush(); err != nil {
+			return nil, err
+		}
+	}
+	message := fmt.Sprintf("rolled back to revision %d", rev.Revision)
+	if direction == nacosiov1.Bidirectional {
+		UpdateBidirectionalSyncStatus(dc, entry.DataId, rev.Md5, rev.Md5, "rollback", v12.Now(), true, false, nacosiov1.SyncReasonRolledBack, message)
+	} else {
+		UpdateSyncStatus(dc, entry.DataId, rev.Md5, "rollback", v12.Now(), true, nacosiov1.SyncReasonRolledBack, message)
+	}
+	return rev, nil
+}
+
+func pruneRevisions(ctx context.Context, c client.Client, revisions []nacosiov1.DynamicConfigurationRevision, limit int) error {
+	if len(revisions) <= limit {
+		return nil
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	for i := 0; i < len(revisions)-limit; i++ {
+		if err := c.Delete(ctx, &revisions[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func revisionName(dc *nacosiov1.DynamicConfiguration, dataId, contentMd5 string) string {
+	prefix := dc.Name
+	// name of object is at most 253 characters
+	if len(prefix) > 200 {
+		prefix = prefix[:200]
+	}
+	return fmt.Sprintf("%s-%s-%s", prefix, CalcMd5(dataId)[:8], contentMd5[:10])
+}
@@ -0,0 +1,264 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ConfigHashAnnotationPrefix is the prefix of pod template annotation patched to rollout targets,
+// followed by name of DynamicConfiguration
+const ConfigHashAnnotationPrefix = "config-hash.nacos.io/"
+
+// ConfigHash returns hash of content synced to objectRef, calculated from md5 of all dataIds in sync statuses
+func ConfigHash(dc *nacosiov1.DynamicConfiguration) string {
+	var lines []string
+	for _, status := range dc.Status.SyncStatuses {
+		lines = append(lines, status.DataId+"="+status.Md5)
+	}
+	sort.Strings(lines)
+	return CalcMd5(strings.Join(lines, "\n"))
+}
+
+const (
+	// rolloutRetryBaseDelay is the delay before retrying a failed rollout at first, doubled for each failure
+	rolloutRetryBaseDelay = 10 * time.Second
+	// rolloutRetryMaxDelay is the max delay between retries of a failed rollout
+	rolloutRetryMaxDelay = 5 * time.Minute
+)
+
+// RolloutIfNeeded rolls out dc.spec.rolloutTargets if content of objectRef changed, or failed rollout is due to retry
+func RolloutIfNeeded(ctx context.Context, c client.Client, dc *nacosiov1.DynamicConfiguration, contentChanged bool, recorder record.EventRecorder) error {
+	if len(dc.Spec.RolloutTargets) == 0 {
+		dc.Status.Rollouts = nil
+		return nil
+	}
+	needRollout := contentChanged
+	var failed []string
+	for _, status := range dc.Status.Rollouts {
+		if status.Ready {
+			continue
+		}
+		failed = append(failed, status.Kind+"/"+status.Name)
+		if nextRolloutRetry(status) <= 0 {
+			needRollout = true
+		}
+	}
+	if needRollout {
+		return rolloutWorkloads(ctx, c, dc, recorder)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("rollout error workloads: %s, retry in %s", strings.Join(failed, ","), NextRolloutRetry(dc).Round(time.Second))
+	}
+	return nil
+}
+
+// NextRolloutRetry returns duration until the earliest failed rollout of dc should be retried, zero means no failure
+func NextRolloutRetry(dc *nacosiov1.DynamicConfiguration) time.Duration {
+	var next time.Duration
+	for _, status := range dc.Status.Rollouts {
+		if status.Ready {
+			continue
+		}
+		d := nextRolloutRetry(status)
+		if d < time.Second {
+			d = time.Second
+		}
+		if next == 0 || d < next {
+			next = d
+		}
+	}
+	return next
+}
+
+// nextRolloutRetry returns duration until failed rollout should be retried. LastRolloutTime is kept while the failure
+// is unchanged, so retries are due at the sum of delays of all failures after it.
+func nextRolloutRetry(status nacosiov1.RolloutStatus) time.Duration {
+	var backoff time.Duration
+	delay := rolloutRetryBaseDelay
+	for i := int32(0); i < status.Failures; i++ {
+		backoff += delay
+		if delay *= 2; delay > rolloutRetryMaxDelay {
+			delay = rolloutRetryMaxDelay
+		}
+	}
+	return backoff - time.Since(status.LastRolloutTime.Time)
+}
+
+// rolloutWorkloads patches pod template of dc.spec.rolloutTargets with config hash annotation, so that workloads
+// roll out with new content of objectRef. Workloads triggered are recorded in dc.status.rollouts.
+func rolloutWorkloads(ctx context.Context, c client.Client, dc *nacosiov1.DynamicConfiguration, recorder record.EventRecorder) error {
+	l := log.FromContext(ctx)
+	hash := ConfigHash(dc)
+	annotationKey := configHashAnnotationKey(dc)
+	last := map[string]nacosiov1.RolloutStatus{}
+	for _, status := range dc.Status.Rollouts {
+		last[status.Kind+"/"+status.Name] = status
+	}
+	// failed returns status of a failed rollout, time of last status is kept if it failed by the same error, and
+	// event is recorded only if the failure is new
+	failed := func(kind, name string, err error) nacosiov1.RolloutStatus {
+		status := nacosiov1.RolloutStatus{
+			Kind:            kind,
+			Name:            name,
+			LastRolloutTime: metav1.Now(),
+			Message:         err.Error(),
+			Failures:        1,
+		}
+		if lastStatus, ok := last[kind+"/"+name]; ok && !lastStatus.Ready && lastStatus.Message == status.Message {
+			status.LastRolloutTime = lastStatus.LastRolloutTime
+			status.Failures = lastStatus.Failures + 1
+			return status
+		}
+		recorder.Eventf(dc, v1.EventTypeWarning, ReasonRolloutFailed, "rollout %s %s failed: %s", kind, name, err.Error())
+		return status
+	}
+	var statuses []nacosiov1.RolloutStatus
+	var errWorkloads []string
+	triggered := map[string]bool{}
+	for _, target := range dc.Spec.RolloutTargets {
+		workloads, err := listRolloutWorkloads(ctx, c, dc.Namespace, target)
+		if err != nil {
+			name := target.Name
+			if target.Selector != nil {
+				name = metav1.FormatLabelSelector(target.Selector)
+			}
+			l.Error(err, "list rollout targets error", "kind", target.Kind, "name", name)
+			errWorkloads = append(errWorkloads, target.Kind+"/"+name)
+			statuses = append(statuses, failed(target.Kind, name, err))
+			continue
+		}
+		for _, w := range workloads {
+			id := target.Kind + "/" + w.GetName()
+			if triggered[id] {
+				continue
+			}
+			triggered[id] = true
+			patched, err := patchConfigHash(ctx, c, w, annotationKey, hash)
+			if err != nil {
+				l.Error(err, "patch config hash of rollout target error", "kind", target.Kind, "name", w.GetName())
+				errWorkloads = append(errWorkloads, id)
+				status := failed(target.Kind, w.GetName(), err)
+				status.ConfigHash = hash
+				statuses = append(statuses, status)
+				continue
+			}
+			status := nacosiov1.RolloutStatus{
+				Kind:            target.Kind,
+				Name:            w.GetName(),
+				ConfigHash:      hash,
+				LastRolloutTime: metav1.Now(),
+				Ready:           true,
+			}
+			if patched {
+				l.Info("rollout triggered", "kind", target.Kind, "name", w.GetName(), "configHash", hash)
+				recorder.Eventf(dc, v1.EventTypeNormal, ReasonRolloutTriggered, "rollout %s %s triggered by config change", target.Kind, w.GetName())
+			} else if lastStatus, ok := last[id]; ok && lastStatus.Ready && lastStatus.ConfigHash == hash {
+				status.LastRolloutTime = lastStatus.LastRolloutTime
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	dc.Status.Rollouts = statuses
+	if len(errWorkloads) > 0 {
+		return fmt.Errorf("rollout error workloads: %s", strings.Join(errWorkloads, ","))
+	}
+	return nil
+}
+
+func configHashAnnotationKey(dc *nacosiov1.DynamicConfiguration) string {
+	name := dc.Name
+	// name part of annotation key is at most 63 characters
+	if len(name) > 63 {
+		name = CalcMd5(name)
+	}
+	return ConfigHashAnnotationPrefix + name
+}
+
+func listRolloutWorkloads(ctx context.Context, c client.Client, namespace string, target nacosiov1.RolloutTarget) ([]client.Object, error) {
+	var obj client.Object
+	var list client.ObjectList
+	switch target.Kind {
+	case nacosiov1.DeploymentKind:
+		obj, list = &appsv1.Deployment{}, &appsv1.DeploymentList{}
+	case nacosiov1.StatefulSetKind:
+		obj, list = &appsv1.StatefulSet{}, &appsv1.StatefulSetList{}
+	case nacosiov1.DaemonSetKind:
+		obj, list = &appsv1.DaemonSet{}, &appsv1.DaemonSetList{}
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", target.Kind)
+	}
+	if len(target.Name) > 0 {
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: target.Name}, obj); err != nil {
+			return nil, err
+		}
+		return []client.Object{obj}, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(target.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	var workloads []client.Object
+	switch l := list.(type) {
+	case *appsv1.DeploymentList:
+		for i := range l.Items {
+			workloads = append(workloads, &l.Items[i])
+		}
+	case *appsv1.StatefulSetList:
+		for i := range l.Items {
+			workloads = append(workloads, &l.Items[i])
+		}
+	case *appsv1.DaemonSetList:
+		for i := range l.Items {
+			workloads = append(workloads, &l.Items[i])
+		}
+	}
+	return workloads, nil
+}
+
+// patchConfigHash returns true if pod template of obj is patched, false if it has the hash already
+func patchConfigHash(ctx context.Context, c client.Client, obj client.Object, key, hash string) (bool, error) {
+	origin := obj.DeepCopyObject().(client.Object)
+	template := podTemplateOf(obj)
+	if template == nil {
+		return false, fmt.Errorf("unsupported workload %T", obj)
+	}
+	if template.Annotations[key] == hash {
+		return false, nil
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[key] = hash
+	if err := c.Patch(ctx, obj, client.MergeFrom(origin)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func podTemplateOf(obj client.Object) *v1.PodTemplateSpec {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template
+	default:
+		return nil
+	}
+}
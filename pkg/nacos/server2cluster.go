@@ -4,27 +4,39 @@ import (
 	"context"
 	"fmt"
 	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/metrics"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sync"
 	"time"
 )
 
+// enqueueTimeout is the max duration a callback waits to trigger reconciling
+const enqueueTimeout = 10 * time.Second
+
 type Server2ClusterCallback interface {
 	Callback(namespace, group, dataId, content string)
 	CallbackWithContext(ctx context.Context, namespace, group, dataId, content string)
 }
 
-func NewDefaultServer2ClusterCallback(c client.Client, mappings *DataId2DCMappings, locks *LockManager) Server2ClusterCallback {
+func NewDefaultServer2ClusterCallback(c client.Client, mappings *DataId2DCMappings, locks *LockManager, events chan<- event.GenericEvent, recorder record.EventRecorder) Server2ClusterCallback {
+	if recorder == nil {
+		recorder = NewNopEventRecorder()
+	}
 	return &DefaultServer2ClusterCallback{
 		Client:   c,
 		mappings: mappings,
 		locks:    locks,
+		events:   events,
+		recorder: recorder,
 	}
 }
 
@@ -32,6 +44,8 @@ type DefaultServer2ClusterCallback struct {
 	client.Client
 	mappings *DataId2DCMappings
 	locks    *LockManager
+	events   chan<- event.GenericEvent
+	recorder record.EventRecorder
 }
 
 func (cb *DefaultServer2ClusterCallback) Callback(namespace, group, dataId, content string) {
@@ -44,7 +58,9 @@ func (cb *DefaultServer2ClusterCallback) CallbackWithContext(ctx context.Context
 	l.Info("server2cluster callback")
 	dcNNList := cb.mappings.GetDCList(namespace, group, dataId)
 	for _, nn := range dcNNList {
-		if err := retry.RetryOnConflict(wait.Backoff{
+		start := time.Now()
+		needReconcile := false
+		err := retry.RetryOnConflict(wait.Backoff{
 			Duration: 1 * time.Second,
 			Factor:   2,
 			Steps:    3,
@@ -55,15 +71,44 @@ func (cb *DefaultServer2ClusterCallback) CallbackWithContext(ctx context.Context
 			lock := cb.locks.GetLock(lockName)
 			lock.Lock()
 			defer lock.Unlock()
-			return cb.server2ClusterCallbackOneDC(ctx, namespace, group, dataId, content, nn)
-		}); err != nil {
+			var err error
+			needReconcile, err = cb.server2ClusterCallbackOneDC(ctx, namespace, group, dataId, content, nn)
+			return err
+		})
+		metrics.ObserveServer2ClusterCallback(start, err)
+		if err != nil {
 			l.Error(err, "update config failed", "dc", nn)
 		}
+		if needReconcile {
+			// triggered after the lock is released, so reconciling of dc is not blocked if events are not drained
+			cb.enqueue(ctx, nn)
+		}
 	}
 	l.Info("server2cluster callback processed", "dcList", dcNNList)
 }
 
-func (cb *DefaultServer2ClusterCallback) server2ClusterCallbackOneDC(ctx context.Context, namespace, group, dataId, content string, nn types.NamespacedName) error {
+// enqueue triggers reconciling of dc. It gives up after enqueueTimeout, e.g. the controller is not started or its
+// queue is busy, then the change is synced by next reconciling of dc.
+func (cb *DefaultServer2ClusterCallback) enqueue(ctx context.Context, nn types.NamespacedName) {
+	if cb.events == nil {
+		return
+	}
+	l := log.FromContext(ctx).WithValues("dc", nn)
+	timer := time.NewTimer(enqueueTimeout)
+	defer timer.Stop()
+	select {
+	case cb.events <- event.GenericEvent{Object: &nacosiov1.DynamicConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: nn.Namespace, Name: nn.Name},
+	}}:
+		l.Info("reconciling triggered by server change")
+	case <-ctx.Done():
+	case <-timer.C:
+		l.Error(fmt.Errorf("timeout after %s", enqueueTimeout), "trigger reconciling error, server change is synced by next reconciling")
+	}
+}
+
+// server2ClusterCallbackOneDC applies content to dc, it returns true if dc should be reconciled to apply content instead
+func (cb *DefaultServer2ClusterCallback) server2ClusterCallbackOneDC(ctx context.Context, namespace, group, dataId, content string, nn types.NamespacedName) (bool, error) {
 	l := log.FromContext(ctx)
 	l = l.WithValues("dc", nn)
 	dc := nacosiov1.DynamicConfiguration{}
@@ -71,30 +116,42 @@ func (cb *DefaultServer2ClusterCallback) server2ClusterCallbackOneDC(ctx context
 		if errors.IsNotFound(err) {
 			cb.mappings.RemoveMapping(namespace, group, dataId, nn)
 			l.Info("mapping removed due to dc not found")
-			return nil
+			return false, nil
 		}
 		l.Error(err, "get DynamicConfiguration error")
-		return err
+		return false, err
 	}
-	if !StringSliceContains(dc.Spec.DataIds, dataId) {
+	entry := FindConfigEntry(&dc, group, dataId)
+	if entry == nil {
 		cb.mappings.RemoveMapping(namespace, group, dataId, nn)
-		l.Info("mapping removed due to dataId not found in spec.DataIds")
-		return nil
+		l.Info("mapping removed due to dataId and group not found in DynamicConfiguration")
+		return false, nil
 	}
-	if dc.Spec.NacosServer.Namespace != namespace {
+	if GetNacosNamespace(&dc) != namespace {
 		cb.mappings.RemoveMapping(namespace, group, dataId, nn)
-		l.Info("mapping removed due to namespace changed", "namespace from server", namespace, "namespace in dc", dc.Spec.NacosServer.Namespace)
-		return nil
+		l.Info("mapping removed due to namespace changed", "namespace from server", namespace, "namespace in dc", GetNacosNamespace(&dc))
+		return false, nil
 	}
-	if dc.Spec.NacosServer.Group != group {
-		cb.mappings.RemoveMapping(namespace, group, dataId, nn)
-		l.Info("mapping removed due to group changed", "group from server", group, "group in dc", dc.Spec.NacosServer.Group)
-		return nil
+	if dc.Spec.Suspend {
+		l.Info("ignored due to suspended, it is synced again after resumed", "dataId", dataId)
+		return false, nil
+	}
+	if dc.Spec.Strategy.DryRun {
+		l.Info("ignored due to dry run", "dataId", dataId)
+		return false, nil
+	}
+	if IsRolledBack(&dc, dataId) {
+		l.Info("ignored due to rollback", "dataId", dataId)
+		return false, nil
+	}
+	if dc.Spec.Strategy.SyncDirection == nacosiov1.Bidirectional {
+		// bidirectional dataIds need to compare both sides with last sync status, leave it to reconciling
+		return true, nil
 	}
 	if dc.Status.ObjectRef == nil {
 		err := fmt.Errorf("ObjectReference empty in status")
 		l.Error(err, "ObjectReference empty in status")
-		return err
+		return false, err
 	}
 
 	objRef := dc.Status.ObjectRef.DeepCopy()
@@ -102,35 +159,93 @@ func (cb *DefaultServer2ClusterCallback) server2ClusterCallbackOneDC(ctx context
 	objWrapper, err := NewObjectReferenceWrapper(cb.Client, &dc, objRef)
 	if err != nil {
 		l.Error(err, "create object wrapper error", "objRef", objRef)
-		return err
+		return false, err
+	}
+	content, err = NewContentRenderer(ctx, cb.Client, &dc).Render(dataId, content)
+	if err != nil {
+		l.Error(err, "render content error")
+		recordWarning(cb.recorder, &dc, ReasonRenderFailed, dataId, err)
+		UpdateSyncStatus(&dc, dataId, "", "server", metav1.Now(), false, nacosiov1.SyncReasonRenderFailed, "render content error: "+err.Error())
+		return false, cb.Status().Update(ctx, &dc)
+	}
+	if err := ValidateContent(entry.Format, content); err != nil {
+		l.Error(err, "invalid content")
+		recordWarning(cb.recorder, &dc, ReasonInvalidContent, dataId, err)
+		MarkSyncStatusNotReady(&dc, dataId, nacosiov1.SyncReasonInvalidContent, err.Error())
+		return false, cb.Status().Update(ctx, &dc)
 	}
-	oldContent, _, err := objWrapper.GetContent(dataId)
+	oldContent, _, err := objWrapper.GetContent(entry.Key)
 	if err != nil {
 		l.Error(err, "read content error")
-		return err
+		return false, err
 	}
 	newMd5 := CalcMd5(content)
 	if newMd5 == CalcMd5(oldContent) {
 		l.Info("ignored due to same content", "md5", newMd5)
-		UpdateSyncStatusIfAbsent(&dc, dataId, newMd5, "server", metav1.Now(), true, "skipped due to same md5")
-		return nil
+		UpdateSyncStatusIfAbsent(&dc, dataId, newMd5, "server", metav1.Now(), true, nacosiov1.SyncReasonSynced, "skipped due to same md5")
+		return false, nil
 	}
-	if err := objWrapper.StoreContent(dataId, content); err != nil {
+	if err := objWrapper.StoreContent(entry.Key, content); err != nil {
 		l.Error(err, "update content error", "obj", objRef)
+		recordWarning(cb.recorder, &dc, ReasonServerChangeFailed, dataId, err)
+		return false, err
+	}
+	if err := objWrapper.Flush(); err != nil {
+		l.Error(err, "flush object reference error", "obj", objRef)
+		recordWarning(cb.recorder, &dc, ReasonServerChangeFailed, dataId, err)
+		return false, err
+	}
+	cb.recorder.Eventf(&dc, v1.EventTypeNormal, ReasonServerChangeApplied, "dataId %s changed in nacos server, applied to %s %s", dataId, objRef.Kind, objRef.Name)
+	now := metav1.Now()
+	UpdateSyncStatus(&dc, dataId, newMd5, "server", now, true, nacosiov1.SyncReasonSynced, "")
+	if err := RecordRevision(ctx, cb.Client, &dc, dataId, group, content, "server"); err != nil {
+		l.Error(err, "record revision error")
+	}
+	if err := RolloutIfNeeded(ctx, cb.Client, &dc, true, cb.recorder); err != nil {
+		l.Error(err, "rollout workloads error")
+	}
+	rollouts := dc.Status.Rollouts
+	return false, cb.updateStatus(ctx, &dc, func(dc *nacosiov1.DynamicConfiguration) {
+		UpdateSyncStatus(dc, dataId, newMd5, "server", now, true, nacosiov1.SyncReasonSynced, "")
+		dc.Status.Rollouts = rollouts
+	})
+}
+
+// updateStatus updates status of dc, and applies mutate to the latest dc on conflict. Content is stored to object
+// reference already, so only status is retried, otherwise content is the same when retrying and status is lost.
+func (cb *DefaultServer2ClusterCallback) updateStatus(ctx context.Context, dc *nacosiov1.DynamicConfiguration, mutate func(dc *nacosiov1.DynamicConfiguration)) error {
+	err := cb.Status().Update(ctx, dc)
+	if !errors.IsConflict(err) {
 		return err
 	}
-	UpdateSyncStatus(&dc, dataId, newMd5, "server", metav1.Now(), true, "")
-	return cb.Status().Update(ctx, &dc)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := cb.Get(ctx, client.ObjectKeyFromObject(dc), dc); err != nil {
+			return err
+		}
+		mutate(dc)
+		return cb.Status().Update(ctx, dc)
+	})
+}
+
+// NacosConfigKey identifies a configuration in nacos server
+type NacosConfigKey struct {
+	Namespace string
+	Group     string
+	DataId    string
+}
+
+func (k NacosConfigKey) String() string {
+	return GetNacosConfigurationUniKey(k.Namespace, k.Group, k.DataId)
 }
 
 type DataId2DCMappings struct {
-	m    map[string][]types.NamespacedName
+	m    map[NacosConfigKey][]types.NamespacedName
 	lock sync.RWMutex
 }
 
 func NewDataId2DCMappings() *DataId2DCMappings {
 	return &DataId2DCMappings{
-		m:    map[string][]types.NamespacedName{},
+		m:    map[NacosConfigKey][]types.NamespacedName{},
 		lock: sync.RWMutex{},
 	}
 }
@@ -138,7 +253,8 @@ func NewDataId2DCMappings() *DataId2DCMappings {
 func (d *DataId2DCMappings) AddMapping(namespaceId, group, dataId string, nn types.NamespacedName) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
-	key := GetNacosConfigurationUniKey(namespaceId, group, dataId)
+	key := NacosConfigKey{Namespace: namespaceId, Group: group, DataId: dataId}
+	defer d.updateActiveListenersMetric()
 	arr, ok := d.m[key]
 	if !ok {
 		d.m[key] = []types.NamespacedName{nn}
@@ -158,19 +274,40 @@ func (d *DataId2DCMappings) AddMapping(namespaceId, group, dataId string, nn typ
 	d.m[key] = arr
 }
 
+func (d *DataId2DCMappings) updateActiveListenersMetric() {
+	metrics.ActiveListeners.Set(float64(len(d.m)))
+}
+
 func (d *DataId2DCMappings) GetDCList(namespaceId, group, dataId string) []types.NamespacedName {
 	d.lock.RLock()
-	d.lock.RUnlock()
+	defer d.lock.RUnlock()
+
+	key := NacosConfigKey{Namespace: namespaceId, Group: group, DataId: dataId}
+	return append([]types.NamespacedName{}, d.m[key]...)
+}
+
+// GetConfigKeys return all configurations which the DynamicConfiguration is mapped to
+func (d *DataId2DCMappings) GetConfigKeys(nn types.NamespacedName) []NacosConfigKey {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
 
-	key := GetNacosConfigurationUniKey(namespaceId, group, dataId)
-	return d.m[key]
+	var keys []NacosConfigKey
+	for key, arr := range d.m {
+		for _, v := range arr {
+			if v.String() == nn.String() {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	return keys
 }
 
 func (d *DataId2DCMappings) HasMapping(namespaceId, group, dataId string, nn types.NamespacedName) bool {
 	d.lock.RLock()
 	defer d.lock.RUnlock()
 
-	key := GetNacosConfigurationUniKey(namespaceId, group, dataId)
+	key := NacosConfigKey{Namespace: namespaceId, Group: group, DataId: dataId}
 	arr, ok := d.m[key]
 	if !ok {
 		return false
@@ -191,7 +328,8 @@ func (d *DataId2DCMappings) RemoveMapping(namespaceId, group, dataId string, nn
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
-	key := GetNacosConfigurationUniKey(namespaceId, group, dataId)
+	key := NacosConfigKey{Namespace: namespaceId, Group: group, DataId: dataId}
+	defer d.updateActiveListenersMetric()
 	arr := d.m[key]
 	var newArr []types.NamespacedName
 	for _, v := range arr {
@@ -200,6 +338,10 @@ func (d *DataId2DCMappings) RemoveMapping(namespaceId, group, dataId string, nn
 		}
 		newArr = append(newArr, v)
 	}
+	if len(newArr) == 0 {
+		delete(d.m, key)
+		return
+	}
 	d.m[key] = newArr
 }
 
@@ -0,0 +1,156 @@
+package nacos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GetTargetStatus returns status of target, nil if target is never synced
+func GetTargetStatus(dc *nacosiov1.DynamicConfiguration, name string) *nacosiov1.TargetStatus {
+	for i := range dc.Status.TargetStatuses {
+		if dc.Status.TargetStatuses[i].Name == name {
+			return &dc.Status.TargetStatuses[i]
+		}
+	}
+	return nil
+}
+
+// targetView returns a copy of dc whose nacos server is the target, so that group of dataIds and client params
+// are resolved the same way as spec.nacosServer
+func targetView(dc *nacosiov1.DynamicConfiguration, target nacosiov1.SyncTarget) *nacosiov1.DynamicConfiguration {
+	view := dc.DeepCopy()
+	server := *target.NacosServer.DeepCopy()
+	if len(server.Group) == 0 {
+		server.Group = dc.Spec.NacosServer.Group
+	}
+	view.Spec.NacosServer = server
+	view.Spec.NacosServerRef = target.NacosServerRef.DeepCopy()
+	view.Spec.Targets = nil
+	return view
+}
+
+// syncTargets publishes dataIds to each target of spec.targets. Every target is synced even if others failed,
+// result of each target is recorded in dc.status.targetStatuses.
+func (scc *SyncConfigurationController) syncTargets(ctx context.Context, dc *nacosiov1.DynamicConfiguration) error {
+	nn := types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}
+	var names []string
+	for _, target := range dc.Spec.Targets {
+		names = append(names, target.Name)
+	}
+	for _, c := range scc.clients.RetainTargets(nn, names, true) {
+		scc.closeClientIfUnused(c)
+	}
+	if len(dc.Spec.Targets) == 0 {
+		dc.Status.TargetStatuses = nil
+		return nil
+	}
+
+	l := log.FromContext(ctx)
+	objectRef := getObjectReference(dc)
+	objWrapper, err := NewObjectReferenceWrapper(scc.Client, dc, &objectRef)
+	if err != nil {
+		l.Error(err, "create object wrapper error", "obj", objectRef)
+		return err
+	}
+	var statuses []nacosiov1.TargetStatus
+	var errTargets []string
+	for _, target := range dc.Spec.Targets {
+		status := nacosiov1.TargetStatus{Name: target.Name}
+		if last := GetTargetStatus(dc, target.Name); last != nil {
+			status = *last.DeepCopy()
+		}
+		logWithTarget := l.WithValues("target", target.Name)
+		err := scc.syncTarget(log.IntoContext(ctx, logWithTarget), dc, target, objWrapper, &status)
+		status.Ready = err == nil
+		status.Message = errorMessage(err)
+		for _, s := range status.SyncStatuses {
+			if !s.Ready && status.Ready {
+				status.Ready = false
+				status.Message = fmt.Sprintf("dataId %s not ready: %s", s.DataId, s.Reason)
+			}
+		}
+		if err != nil {
+			logWithTarget.Error(err, "sync target error")
+			errTargets = append(errTargets, target.Name)
+		}
+		statuses = append(statuses, status)
+	}
+	dc.Status.TargetStatuses = statuses
+	if len(errTargets) > 0 {
+		return fmt.Errorf("err targets: %s", strings.Join(errTargets, ","))
+	}
+	return nil
+}
+
+// syncTarget publishes dataIds to the target the same way as spec.nacosServer, results are recorded in status
+func (scc *SyncConfigurationController) syncTarget(ctx context.Context, dc *nacosiov1.DynamicConfiguration, target nacosiov1.SyncTarget, objWrapper ObjectReferenceWrapper, status *nacosiov1.TargetStatus) error {
+	configClient, namespace, err := scc.getTargetConfigClient(ctx, dc, target)
+	if err != nil {
+		return err
+	}
+	status.NacosNamespace = namespace
+	return scc.syncToDestination(ctx, dc, syncDestination{
+		target:       target.Name,
+		configClient: configClient,
+		view:         targetView(dc, target),
+		syncStatuses: &status.SyncStatuses,
+		grayStatuses: &status.GrayStatuses,
+	}, objWrapper)
+}
+
+// finalizeTargets deletes dataIds with syncDeletion from all targets, failure of a target doesn't block the others
+func (scc *SyncConfigurationController) finalizeTargets(ctx context.Context, dc *nacosiov1.DynamicConfiguration) []string {
+	l := log.FromContext(ctx)
+	var errTargets []string
+	for _, target := range dc.Spec.Targets {
+		configClient, _, err := scc.getTargetConfigClient(ctx, dc, target)
+		if err != nil {
+			l.Error(err, "create nacos config client of target error", "target", target.Name)
+			errTargets = append(errTargets, target.Name)
+			continue
+		}
+		failed := false
+		for _, entry := range GetConfigEntries(targetView(dc, target)) {
+			if !entry.SyncDeletion {
+				continue
+			}
+			if _, err := configClient.DeleteConfig(vo.ConfigParam{Group: entry.Group, DataId: entry.DataId}); err != nil {
+				l.Error(err, "delete dataId in target error", "target", target.Name, "dataId", entry.DataId)
+				recordWarning(scc.recorder, dc, ReasonDeleteFailed, entry.DataId, fmt.Errorf("target %s: %w", target.Name, err))
+				failed = true
+				continue
+			}
+			scc.recorder.Eventf(dc, v1.EventTypeNormal, ReasonDeleted, "dataId %s deleted in target %s by finalizer", entry.DataId, target.Name)
+		}
+		if failed {
+			errTargets = append(errTargets, target.Name)
+		}
+	}
+	return errTargets
+}
+
+// getTargetConfigClient returns config client of target and the namespace of nacos server it resolved
+func (scc *SyncConfigurationController) getTargetConfigClient(ctx context.Context, dc *nacosiov1.DynamicConfiguration, target nacosiov1.SyncTarget) (config_client.IConfigClient, string, error) {
+	clientParams, err := scc.authProvider.GetNacosClientParams(targetView(dc, target))
+	if err != nil {
+		scc.recorder.Eventf(dc, v1.EventTypeWarning, ReasonAuthFailed, "resolve nacos server and credentials of target %s error: %s", target.Name, err.Error())
+		return nil, "", fmt.Errorf("resolve nacos server and credentials error: %w", err)
+	}
+	configClient, err := scc.authManager.GetNacosConfigClientByParams(clientParams)
+	if err != nil {
+		return nil, "", fmt.Errorf("create nacos config client error: %w", err)
+	}
+	nn := types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}
+	if oldClient := scc.clients.TrackTarget(nn, target.Name, configClient, clientParams.SecretRefs()); oldClient != nil {
+		scc.closeClientIfUnused(oldClient)
+	}
+	return newInstrumentedConfigClient(configClient, string(dc.Spec.Strategy.SyncDirection)), clientParams.Namespace, nil
+}
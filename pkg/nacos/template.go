@@ -0,0 +1,121 @@
+package nacos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"regexp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"text/template"
+)
+
+var placeholderRegexp = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+// ContentRenderer renders content of dataIds with variables of dc.spec.template
+type ContentRenderer struct {
+	tpl     *nacosiov1.ContentTemplate
+	values  map[string]string
+	loadErr error
+}
+
+// NewContentRenderer loads variables of dc.spec.template. Error of loading variables is returned when rendering,
+// so that it is reported by each dataId.
+func NewContentRenderer(ctx context.Context, c client.Client, dc *nacosiov1.DynamicConfiguration) *ContentRenderer {
+	tpl := dc.Spec.Template
+	if tpl == nil || dc.Spec.Strategy.SyncDirection == nacosiov1.Bidirectional {
+		return &ContentRenderer{}
+	}
+	values := map[string]string{}
+	for _, source := range tpl.ValuesFrom {
+		switch {
+		case source.ConfigMapRef != nil:
+			cm := v1.ConfigMap{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: dc.Namespace, Name: source.ConfigMapRef.Name}, &cm); err != nil {
+				return &ContentRenderer{tpl: tpl, loadErr: fmt.Errorf("read values from ConfigMap %s error: %w", source.ConfigMapRef.Name, err)}
+			}
+			for k, v := range cm.Data {
+				values[k] = v
+			}
+		case source.SecretRef != nil:
+			secret := v1.Secret{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: dc.Namespace, Name: source.SecretRef.Name}, &secret); err != nil {
+				return &ContentRenderer{tpl: tpl, loadErr: fmt.Errorf("read values from Secret %s error: %w", source.SecretRef.Name, err)}
+			}
+			for k, v := range secret.Data {
+				values[k] = string(v)
+			}
+		}
+	}
+	for k, v := range tpl.Variables {
+		values[k] = v
+	}
+	return &ContentRenderer{tpl: tpl, values: values}
+}
+
+// Render returns rendered content of dataId, content is returned as it is if dataId is not rendered by template
+func (r *ContentRenderer) Render(dataId, content string) (string, error) {
+	if r == nil || r.tpl == nil {
+		return content, nil
+	}
+	if !templateAppliesTo(r.tpl, dataId) {
+		return content, nil
+	}
+	if r.loadErr != nil {
+		return "", r.loadErr
+	}
+	if r.tpl.Type == nacosiov1.GoTemplate {
+		return renderGoTemplate(dataId, content, r.values)
+	}
+	return renderPlaceholder(content, r.values)
+}
+
+// IsTemplated returns true if content of dataId is rendered by dc.spec.template
+func IsTemplated(dc *nacosiov1.DynamicConfiguration, dataId string) bool {
+	if dc.Spec.Template == nil || dc.Spec.Strategy.SyncDirection == nacosiov1.Bidirectional {
+		return false
+	}
+	return templateAppliesTo(dc.Spec.Template, dataId)
+}
+
+func templateAppliesTo(tpl *nacosiov1.ContentTemplate, dataId string) bool {
+	return len(tpl.DataIds) == 0 || StringSliceContains(tpl.DataIds, dataId)
+}
+
+func renderGoTemplate(name, content string, values map[string]string) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", err
+	}
+	buf := bytes.Buffer{}
+	if err := t.Execute(&buf, values); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderPlaceholder replaces ${var} with its value, and $${var} with ${var}
+func renderPlaceholder(content string, values map[string]string) (string, error) {
+	var undefined []string
+	rendered := placeholderRegexp.ReplaceAllStringFunc(content, func(s string) string {
+		if strings.HasPrefix(s, "$$") {
+			return s[1:]
+		}
+		name := strings.TrimSpace(s[2 : len(s)-1])
+		v, ok := values[name]
+		if !ok {
+			if !StringSliceContains(undefined, name) {
+				undefined = append(undefined, name)
+			}
+			return s
+		}
+		return v
+	})
+	if len(undefined) > 0 {
+		return "", fmt.Errorf("undefined variables: %s", strings.Join(undefined, ","))
+	}
+	return rendered, nil
+}
@@ -0,0 +1,146 @@
+package nacos
+
+import (
+	"context"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg/nacos/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ContentRenderer", func() {
+	var ctx context.Context
+	var k8sClient client.Client
+
+	newTemplatedDC := func(tpl *nacosiov1.ContentTemplate) *nacosiov1.DynamicConfiguration {
+		return &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "tpl", Namespace: testNamespace},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				DataIds:  []string{"app.yaml"},
+				Strategy: nacosiov1.SyncStrategy{SyncPolicy: nacosiov1.Always, SyncDirection: nacosiov1.Cluster2Server},
+				NacosServer: nacosiov1.NacosServerConfiguration{
+					ServerAddr: pointer.String("127.0.0.1:8848"),
+					Namespace:  testNacosNamespace,
+					Group:      testGroup,
+					AuthRef:    &v1.ObjectReference{Name: "nacos-auth", APIVersion: "v1", Kind: "Secret"},
+				},
+				ObjectRef: &v1.ObjectReference{Name: "tpl", APIVersion: "v1", Kind: "ConfigMap"},
+				Template:  tpl,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		k8sClient = fakeclient.NewClientBuilder().
+			WithScheme(testScheme).
+			WithObjects(
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "nacos-auth", Namespace: testNamespace},
+					Data:       map[string][]byte{"ak": []byte("ak"), "sk": []byte("sk")},
+				},
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "values", Namespace: testNamespace},
+					Data:       map[string]string{"host": "cm-host", "port": "80"},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "secret-values", Namespace: testNamespace},
+					Data:       map[string][]byte{"password": []byte("secret"), "port": []byte("443")},
+				},
+			).
+			Build()
+	})
+
+	It("substitutes placeholders and keeps escaped ones", func() {
+		r := NewContentRenderer(ctx, k8sClient, newTemplatedDC(&nacosiov1.ContentTemplate{
+			Variables: map[string]string{"host": "example.com", "port": "8080"},
+		}))
+		content, err := r.Render("app.yaml", "url: http://${host}:${ port }\nraw: $${host}")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal("url: http://example.com:8080\nraw: ${host}"))
+
+		_, err = r.Render("app.yaml", "${user} ${host} ${user} ${password}")
+		Expect(err).To(MatchError("undefined variables: user,password"))
+	})
+
+	It("renders Go template with missingkey=error", func() {
+		r := NewContentRenderer(ctx, k8sClient, newTemplatedDC(&nacosiov1.ContentTemplate{
+			Type:      nacosiov1.GoTemplate,
+			Variables: map[string]string{"host": "example.com"},
+		}))
+		content, err := r.Render("app.yaml", `host: {{ .host | printf "%q" }}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(`host: "example.com"`))
+
+		_, err = r.Render("app.yaml", "port: {{ .port }}")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`map has no entry for key "port"`))
+
+		_, err = r.Render("app.yaml", "host: {{ .host ")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("loads values from ConfigMap, Secret and variables in order", func() {
+		r := NewContentRenderer(ctx, k8sClient, newTemplatedDC(&nacosiov1.ContentTemplate{
+			ValuesFrom: []nacosiov1.TemplateValuesSource{
+				{ConfigMapRef: &v1.LocalObjectReference{Name: "values"}},
+				{SecretRef: &v1.LocalObjectReference{Name: "secret-values"}},
+			},
+			Variables: map[string]string{"host": "example.com"},
+		}))
+		content, err := r.Render("app.yaml", "${host}:${port} ${password}")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal("example.com:443 secret"))
+
+		r = NewContentRenderer(ctx, k8sClient, newTemplatedDC(&nacosiov1.ContentTemplate{
+			ValuesFrom: []nacosiov1.TemplateValuesSource{{ConfigMapRef: &v1.LocalObjectReference{Name: "missing"}}},
+		}))
+		_, err = r.Render("app.yaml", "${host}")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("read values from ConfigMap missing error"))
+	})
+
+	It("renders only dataIds of template", func() {
+		dc := newTemplatedDC(&nacosiov1.ContentTemplate{DataIds: []string{"app.yaml"}})
+		Expect(IsTemplated(dc, "app.yaml")).To(BeTrue())
+		Expect(IsTemplated(dc, "other.yaml")).To(BeFalse())
+		content, err := NewContentRenderer(ctx, k8sClient, dc).Render("other.yaml", "${host}")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal("${host}"))
+
+		dc.Spec.Strategy.SyncDirection = nacosiov1.Bidirectional
+		Expect(IsTemplated(dc, "app.yaml")).To(BeFalse())
+	})
+
+	It("reports render errors in sync status without publishing", func() {
+		server := fake.NewConfigServer()
+		controller := NewSyncConfigurationController(k8sClient, SyncConfigOptions{
+			ConfigClientFactory: server.ConfigClientFactory(),
+		})
+		Expect(k8sClient.Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "tpl", Namespace: testNamespace},
+			Data:       map[string]string{"app.yaml": "host: ${host}"},
+		})).To(Succeed())
+		dc := newTemplatedDC(&nacosiov1.ContentTemplate{})
+
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).NotTo(Succeed())
+		_, exist := server.Get(testNacosNamespace, testGroup, "app.yaml")
+		Expect(exist).To(BeFalse())
+		status := GetSyncStatusByDataId(dc.Status.SyncStatuses, "app.yaml")
+		Expect(status).NotTo(BeNil())
+		Expect(status.Ready).To(BeFalse())
+		Expect(status.Reason).To(Equal(nacosiov1.SyncReasonRenderFailed))
+		Expect(status.Message).To(ContainSubstring("undefined variables: host"))
+
+		dc.Spec.Template.Variables = map[string]string{"host": "example.com"}
+		Expect(controller.SyncDynamicConfiguration(ctx, dc)).To(Succeed())
+		content, _ := server.Get(testNacosNamespace, testGroup, "app.yaml")
+		Expect(content).To(Equal("host: example.com"))
+	})
+})
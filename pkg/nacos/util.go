@@ -4,17 +4,28 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var (
 	ConfigMapGVK = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
+	SecretGVK    = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"}
 )
 
 func GetNacosConfigurationUniKey(namespace, group, dataId string) string {
 	return fmt.Sprintf("%s/%s/%s", namespace, group, dataId)
 }
 
+// GetNacosNamespace return namespace of nacos server which dc synced with at last time,
+// fallback to dc.spec.nacosServer.namespace if dc has never been synced
+func GetNacosNamespace(dc *nacosiov1.DynamicConfiguration) string {
+	if len(dc.Status.NacosNamespace) > 0 {
+		return dc.Status.NacosNamespace
+	}
+	return dc.Spec.NacosServer.Namespace
+}
+
 func CalcMd5(s string) string {
 	if len(s) == 0 {
 		return ""
@@ -31,3 +42,16 @@ func StringSliceContains(arr []string, item string) bool {
 	}
 	return false
 }
+
+// StringSliceEqual returns true if both slices have the same items in the same order
+func StringSliceEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg"
+	"github.com/nacos-group/nacos-controller/pkg/nacos"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var objectreferencelog = logf.Log.WithName("objectreference-resource")
+
+const serviceAccountUsernamePrefix = "system:serviceaccount:"
+
+// objectSelector of nacos.io/owned-by-dc is set by config/webhook/objectselector_patch.yaml, since it can't be generated
+//+kubebuilder:webhook:path=/validate--v1-configmap,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=configmaps,verbs=create;update,versions=v1,name=vconfigmap.nacos.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate--v1-secret,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=secrets,verbs=create;update,versions=v1,name=vsecret.nacos.io,admissionReviewVersions=v1
+
+// ObjectReferenceValidator validates content of ConfigMaps and Secrets which are managed by a DynamicConfiguration,
+// so that content in invalid format is rejected before it is synced to nacos server.
+// Only cluster2server and bidirectional DynamicConfigurations are checked, and dataIds rendered by template are skipped.
+// Only keys added or changed are checked on update, so that an invalid key doesn't block other writes, and requests
+// of the controller itself are allowed, e.g. adding owner label or storing content read from nacos server.
+type ObjectReferenceValidator struct {
+	Client client.Reader
+	// ControllerUsername is the username of the controller, e.g. system:serviceaccount:<namespace>:<name>
+	ControllerUsername string
+}
+
+var _ admission.CustomValidator = &ObjectReferenceValidator{}
+
+func SetupObjectReferenceWebhookWithManager(mgr ctrl.Manager) error {
+	validator := &ObjectReferenceValidator{Client: mgr.GetClient(), ControllerUsername: serviceAccountUsername(mgr.GetConfig())}
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1.ConfigMap{}).
+		WithValidator(validator).
+		Complete(); err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1.Secret{}).
+		WithValidator(validator).
+		Complete()
+}
+
+func (v *ObjectReferenceValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, nil, obj)
+}
+
+func (v *ObjectReferenceValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, oldObj, newObj)
+}
+
+func (v *ObjectReferenceValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks keys of obj which are added or changed since oldObj, oldObj is nil on creation
+func (v *ObjectReferenceValidator) validate(ctx context.Context, oldObj, obj runtime.Object) error {
+	var gvk = nacosiov1.ConfigMapGVK
+	if _, ok := obj.(*v1.Secret); ok {
+		gvk = nacosiov1.SecretGVK
+	}
+	meta, data, err := objectData(obj)
+	if err != nil {
+		return err
+	}
+	oldData := map[string]string{}
+	if oldObj != nil {
+		if _, oldData, err = objectData(oldObj); err != nil {
+			return err
+		}
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil && len(v.ControllerUsername) > 0 &&
+		req.UserInfo.Username == v.ControllerUsername {
+		return nil
+	}
+	dcName, ok := meta.GetLabels()[pkg.ConfigMapLabel]
+	if !ok || len(dcName) == 0 {
+		return nil
+	}
+	dc := nacosiov1.DynamicConfiguration{}
+	if err := v.Client.Get(ctx, types.NamespacedName{Namespace: meta.GetNamespace(), Name: dcName}, &dc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		objectreferencelog.Error(err, "get DynamicConfiguration error", "name", dcName, "namespace", meta.GetNamespace())
+		return nil
+	}
+	if dc.Spec.Strategy.SyncDirection == nacosiov1.Server2Cluster || dc.Spec.ObjectRef == nil ||
+		dc.Spec.ObjectRef.Name != meta.GetName() || dc.Spec.ObjectRef.Kind != gvk.Kind {
+		return nil
+	}
+	var errs field.ErrorList
+	for _, entry := range nacos.GetConfigEntries(&dc) {
+		content, exist := data[entry.Key]
+		if !exist || nacos.IsTemplated(&dc, entry.DataId) {
+			continue
+		}
+		if oldContent, ok := oldData[entry.Key]; ok && oldContent == content {
+			continue
+		}
+		if err := nacos.ValidateContent(entry.Format, content); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("data").Key(entry.Key), entry.DataId, err.Error()))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.NewInvalid(gvk.GroupKind(), meta.GetName(), errs)
+}
+
+// objectData returns content of a ConfigMap or Secret, stringData of Secret overrides data
+func objectData(obj runtime.Object) (client.Object, map[string]string, error) {
+	data := map[string]string{}
+	switch o := obj.(type) {
+	case *v1.ConfigMap:
+		for k, v := range o.Data {
+			data[k] = v
+		}
+		return o, data, nil
+	case *v1.Secret:
+		for k, v := range o.Data {
+			data[k] = string(v)
+		}
+		for k, v := range o.StringData {
+			data[k] = v
+		}
+		return o, data, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported object %T", obj)
+	}
+}
+
+// serviceAccountUsername returns the username of the service account which the controller runs as, it is read from
+// the subject of the bearer token in cluster. Empty is returned if the controller doesn't run in cluster.
+func serviceAccountUsername(config *rest.Config) string {
+	if config == nil {
+		return ""
+	}
+	token := config.BearerToken
+	if len(config.BearerTokenFile) > 0 {
+		b, err := os.ReadFile(config.BearerTokenFile)
+		if err != nil {
+			objectreferencelog.Error(err, "read token of service account error")
+			return ""
+		}
+		token = string(b)
+	}
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	claims := struct {
+		Subject string `json:"sub"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || !strings.HasPrefix(claims.Subject, serviceAccountUsernamePrefix) {
+		return ""
+	}
+	return claims.Subject
+}
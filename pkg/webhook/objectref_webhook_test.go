@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+
+	nacosiov1 "github.com/nacos-group/nacos-controller/api/v1"
+	"github.com/nacos-group/nacos-controller/pkg"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("ObjectReferenceValidator", func() {
+	const controllerUsername = "system:serviceaccount:nacos-controller:nacos-controller"
+	var validator *ObjectReferenceValidator
+	newConfigMap := func(data map[string]string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "dc", Namespace: "default", Labels: map[string]string{pkg.ConfigMapLabel: "dc"}},
+			Data:       data,
+		}
+	}
+	requestBy := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: username}},
+		})
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(nacosiov1.AddToScheme(scheme)).To(Succeed())
+		dc := &nacosiov1.DynamicConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "dc", Namespace: "default"},
+			Spec: nacosiov1.DynamicConfigurationSpec{
+				DataIds:   []string{"app.json", "db.json"},
+				Strategy:  nacosiov1.SyncStrategy{SyncDirection: nacosiov1.Cluster2Server},
+				ObjectRef: &v1.ObjectReference{Name: "dc", APIVersion: "v1", Kind: "ConfigMap"},
+			},
+		}
+		validator = &ObjectReferenceValidator{
+			Client:             fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build(),
+			ControllerUsername: controllerUsername,
+		}
+	})
+
+	It("validates keys added or changed", func() {
+		ctx := requestBy("user")
+		_, err := validator.ValidateCreate(ctx, newConfigMap(map[string]string{"app.json": "{"}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("app.json"))
+
+		oldObj := newConfigMap(map[string]string{"app.json": "{}"})
+		_, err = validator.ValidateUpdate(ctx, oldObj, newConfigMap(map[string]string{"app.json": "{", "db.json": "{}"}))
+		Expect(err).To(HaveOccurred())
+		_, err = validator.ValidateUpdate(ctx, oldObj, newConfigMap(map[string]string{"app.json": "{}", "db.json": "{"}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("db.json"))
+	})
+
+	It("allows update of other keys when an invalid key is unchanged", func() {
+		oldObj := newConfigMap(map[string]string{"app.json": "{"})
+		newObj := newConfigMap(map[string]string{"app.json": "{", "db.json": "{}"})
+		Expect(validator.ValidateUpdate(requestBy("user"), oldObj, newObj)).Error().NotTo(HaveOccurred())
+		newObj.Labels["foo"] = "bar"
+		Expect(validator.ValidateUpdate(requestBy("user"), oldObj, newObj)).Error().NotTo(HaveOccurred())
+	})
+
+	It("allows requests of the controller", func() {
+		ctx := requestBy(controllerUsername)
+		oldObj := newConfigMap(map[string]string{"app.json": "{}"})
+		newObj := newConfigMap(map[string]string{"app.json": "{"})
+		Expect(validator.ValidateUpdate(ctx, oldObj, newObj)).Error().NotTo(HaveOccurred())
+		Expect(validator.ValidateCreate(ctx, newObj)).Error().NotTo(HaveOccurred())
+		Expect(validator.ValidateUpdate(requestBy("user"), oldObj, newObj)).Error().To(HaveOccurred())
+	})
+
+	It("reads username of service account from token", func() {
+		payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"` + controllerUsername + `"}`))
+		tokenFile := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte("header."+payload+".signature\n"), 0600)).To(Succeed())
+		Expect(serviceAccountUsername(&rest.Config{BearerTokenFile: tokenFile})).To(Equal(controllerUsername))
+
+		payload = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+		Expect(serviceAccountUsername(&rest.Config{BearerToken: "header." + payload + ".signature"})).To(BeEmpty())
+		Expect(serviceAccountUsername(&rest.Config{})).To(BeEmpty())
+	})
+})